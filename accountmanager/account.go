@@ -43,6 +43,30 @@ type accountAuthor struct {
 	indexWeight           map[uint64]uint64
 }
 
+// LockedBalance is an amount of an asset released to the account on a vesting
+// schedule of block numbers: nothing before Cliff, then linearly from Start
+// until End.
+type LockedBalance struct {
+	AssetID uint64   `json:"assetID"`
+	Amount  *big.Int `json:"amount"`
+	Claimed *big.Int `json:"claimed"`
+	Start   uint64   `json:"start"`
+	Cliff   uint64   `json:"cliff"`
+	End     uint64   `json:"end"`
+}
+
+// Vested returns the amount released at block number.
+func (l *LockedBalance) Vested(number uint64) *big.Int {
+	switch {
+	case number < l.Cliff:
+		return big.NewInt(0)
+	case number >= l.End:
+		return new(big.Int).Set(l.Amount)
+	}
+	vested := new(big.Int).Mul(l.Amount, new(big.Int).SetUint64(number-l.Start))
+	return vested.Div(vested, new(big.Int).SetUint64(l.End-l.Start))
+}
+
 func newAssetBalance(assetID uint64, amount *big.Int) *AssetBalance {
 	ab := AssetBalance{
 		AssetID: assetID,
@@ -70,6 +94,7 @@ type Account struct {
 	Destroy               bool             //account destroy
 	Description           string
 	//ChargeRatio           uint64
+	LockedBalances []*LockedBalance `rlp:"tail"` //not claimed yet, held by the account manager
 }
 
 // NewAccount create a new account object.
@@ -106,7 +131,7 @@ func (a *Account) HaveCode() bool {
 
 // IsEmpty check account empty
 func (a *Account) IsEmpty() bool {
-	return a.GetCodeSize() == 0 && len(a.Balances) == 0 && a.Nonce == 0
+	return a.GetCodeSize() == 0 && len(a.Balances) == 0 && len(a.LockedBalances) == 0 && a.Nonce == 0
 }
 
 // GetName return account object name
@@ -375,6 +400,42 @@ func (a *Account) EnoughAccountBalance(assetID uint64, value *big.Int) error {
 	return nil
 }
 
+//AddLockedBalance add a vesting schedule
+func (a *Account) AddLockedBalance(lock *LockedBalance) {
+	a.LockedBalances = append(a.LockedBalances, lock)
+}
+
+//GetLockedBalanceByID get the amount of an asset locked and not claimed yet
+func (a *Account) GetLockedBalanceByID(assetID uint64) *big.Int {
+	locked := big.NewInt(0)
+	for _, lock := range a.LockedBalances {
+		if lock.AssetID == assetID {
+			locked.Add(locked, new(big.Int).Sub(lock.Amount, lock.Claimed))
+		}
+	}
+	return locked
+}
+
+//ClaimLockedBalance mark the amount of an asset vested at block number as
+//claimed, dropping the schedules fully claimed, and return it
+func (a *Account) ClaimLockedBalance(assetID uint64, number uint64) *big.Int {
+	claimed := big.NewInt(0)
+	locks := make([]*LockedBalance, 0, len(a.LockedBalances))
+	for _, lock := range a.LockedBalances {
+		if lock.AssetID == assetID {
+			vested := lock.Vested(number)
+			claimed.Add(claimed, new(big.Int).Sub(vested, lock.Claimed))
+			lock.Claimed = vested
+			if lock.Claimed.Cmp(lock.Amount) >= 0 {
+				continue
+			}
+		}
+		locks = append(locks, lock)
+	}
+	a.LockedBalances = locks
+	return claimed
+}
+
 // IsSuicided suicide
 func (a *Account) IsSuicided() bool {
 	return a.Suicide
@@ -60,6 +60,13 @@ type CreateAccountAction struct {
 	Description string        `json:"description,omitempty"`
 }
 
+type LockedTransferAction struct {
+	To    common.Name `json:"to,omitempty"`
+	Start uint64      `json:"start,omitempty"`
+	Cliff uint64      `json:"cliff,omitempty"`
+	End   uint64      `json:"end,omitempty"`
+}
+
 type UpdataAccountAction struct {
 	Founder common.Name `json:"founder,omitempty"`
 }
@@ -108,6 +115,15 @@ type UpdateAssetContract struct {
 	Contract common.Name `json:"contract"`
 }
 
+type FreezeAccountAsset struct {
+	AssetID uint64      `json:"assetId,omitempty"`
+	Account common.Name `json:"account"`
+}
+
+type PauseAsset struct {
+	AssetID uint64 `json:"assetId,omitempty"`
+}
+
 //AccountManager represents account management model.
 type AccountManager struct {
 	sdb *state.StateDB
@@ -503,6 +519,17 @@ func (am *AccountManager) GetAccountIDByName(accountName common.Name) (uint64, e
 	return accountID, nil
 }
 
+//AccountNameIDKey get the state key holding the id of account, acctManager is
+//the account manager name of the chain
+func AccountNameIDKey(acctManager common.Name, accountName common.Name) []byte {
+	return state.AccountDataKey(acctManager.String(), accountNameIDPrefix+accountName.String())
+}
+
+//AccountInfoKey get the state key holding the account object of accountID
+func AccountInfoKey(acctManager common.Name, accountID uint64) []byte {
+	return state.AccountDataKey(acctManager.String(), acctInfoPrefix+strconv.FormatUint(accountID, 10))
+}
+
 //GetAccountById get account by account id
 func (am *AccountManager) GetAccountById(id uint64) (*Account, error) {
 	if id == 0 {
@@ -654,7 +681,7 @@ func (am *AccountManager) RecoverTx(signer types.Signer, tx *types.Transaction)
 				count += weight
 			}
 			threshold := acctAuthor.threshold
-			if name.String() == signSender.String() && (action.Type() == types.UpdateAccountAuthor || signSender != action.Sender()) {
+			if name.String() == signSender.String() && (action.Type() == types.UpdateAccountAuthor || action.Type() == types.SetAccountRecovery || signSender != action.Sender()) {
 				threshold = acctAuthor.updateAuthorThreshold
 			}
 			if count < threshold {
@@ -721,6 +748,19 @@ func (am *AccountManager) IsValidSign(accountName common.Name, pub common.PubKey
 	if err != nil {
 		return err
 	}
+	return isValidSign(acct, pub)
+}
+
+// IsValidSignByTime check pub signs for the account at the snapshot of time
+func (am *AccountManager) IsValidSignByTime(accountName common.Name, pub common.PubKey, time uint64) error {
+	acct, err := am.GetAccountByTime(accountName, time)
+	if err != nil {
+		return err
+	}
+	return isValidSign(acct, pub)
+}
+
+func isValidSign(acct *Account, pub common.PubKey) error {
 	if acct == nil {
 		return ErrAccountNotExist
 	}
@@ -822,6 +862,16 @@ func (am *AccountManager) GetAssetInfoByID(assetID uint64) (*asset.AssetObject,
 	return am.ast.GetAssetObjectByID(assetID)
 }
 
+//GetAssetControl get the transfer restrictions of an asset
+func (am *AccountManager) GetAssetControl(assetID uint64) (*asset.AssetControl, error) {
+	return am.ast.GetAssetControl(assetID)
+}
+
+//IsAccountFrozen check the holdings of an asset by account are frozen
+func (am *AccountManager) IsAccountFrozen(assetID uint64, accountName common.Name) (bool, error) {
+	return am.ast.IsAccountFrozen(assetID, accountName)
+}
+
 // GetAllAssetByAssetID get accout asset and subAsset information
 func (am *AccountManager) GetAllAssetByAssetID(acct *Account, assetID uint64) (map[uint64]*big.Int, error) {
 	var ba = make(map[uint64]*big.Int)
@@ -904,6 +954,8 @@ func (am *AccountManager) GetBalanceByTime(accountName common.Name, assetID uint
 		return acct.GetBalanceByID(assetID)
 	} else if typeID == 1 {
 		return am.GetAllBalanceByAssetID(acct, assetID)
+	} else if typeID == 2 {
+		return acct.GetLockedBalanceByID(assetID), nil
 	} else {
 		return big.NewInt(0), fmt.Errorf("type ID %d invalid", typeID)
 	}
@@ -922,6 +974,8 @@ func (am *AccountManager) GetAccountBalanceByID(accountName common.Name, assetID
 		return acct.GetBalanceByID(assetID)
 	} else if typeID == 1 {
 		return am.GetAllBalanceByAssetID(acct, assetID)
+	} else if typeID == 2 {
+		return acct.GetLockedBalanceByID(assetID), nil
 	} else {
 		return big.NewInt(0), fmt.Errorf("type ID %d invalid", typeID)
 	}
@@ -1057,6 +1111,50 @@ func (am *AccountManager) AddAccountBalanceByName(accountName common.Name, asset
 	return am.SetAccount(acct)
 }
 
+//LockBalance lock value of an asset, already held by the account manager, for
+//accountName until it vests
+func (am *AccountManager) LockBalance(accountName common.Name, assetID uint64, value *big.Int, lock *LockedTransferAction) error {
+	if value.Sign() <= 0 {
+		return ErrAmountValueInvalid
+	}
+	if lock.Start > lock.Cliff || lock.Cliff > lock.End {
+		return ErrLockScheduleInvalid
+	}
+	acct, err := am.GetAccountByName(accountName)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return ErrAccountNotExist
+	}
+	acct.AddLockedBalance(&LockedBalance{
+		AssetID: assetID,
+		Amount:  new(big.Int).Set(value),
+		Claimed: big.NewInt(0),
+		Start:   lock.Start,
+		Cliff:   lock.Cliff,
+		End:     lock.End,
+	})
+	return am.SetAccount(acct)
+}
+
+//ClaimLockedBalance claim the amount of an asset vested at block number, the
+//caller pays it out of the account manager
+func (am *AccountManager) ClaimLockedBalance(accountName common.Name, assetID uint64, number uint64) (*big.Int, error) {
+	acct, err := am.GetAccountByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, ErrAccountNotExist
+	}
+	amount := acct.ClaimLockedBalance(assetID, number)
+	if amount.Sign() == 0 {
+		return nil, ErrNoVestedBalance
+	}
+	return amount, am.SetAccount(acct)
+}
+
 //
 func (am *AccountManager) EnoughAccountBalance(accountName common.Name, assetID uint64, value *big.Int) error {
 	acct, err := am.GetAccountByName(accountName)
@@ -1172,6 +1270,9 @@ func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount commo
 	if !am.ast.HasAccess(assetID, fromAccountExtra...) {
 		return fmt.Errorf("no permissions of asset %v", assetID)
 	}
+	if err := am.ast.CheckTransferable(assetID, fromAccount, toAccount); err != nil {
+		return err
+	}
 	// if !am.ast.HasAccess(assetID, fromAccount, toAccount) {
 	// 	return fmt.Errorf("no permissions of asset %v", assetID)
 	// }
@@ -1228,6 +1329,15 @@ func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount commo
 	return am.SetAccount(toAcct)
 }
 
+//checkAssetControl check fromName may freeze or pause the asset, the system
+//asset can never be
+func (am *AccountManager) checkAssetControl(fromName common.Name, assetID uint64, config *params.ChainConfig) error {
+	if assetID == config.SysTokenID {
+		return ErrSysAssetControl
+	}
+	return am.ast.CheckOwner(fromName, assetID)
+}
+
 func (am *AccountManager) CheckAssetContract(contract common.Name, owner common.Name, from ...common.Name) bool {
 	from = append(from, owner)
 	for _, name := range from {
@@ -1463,6 +1573,59 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		if err := am.UpdateAccountAuthor(action.Sender(), &acctAuth); err != nil {
 			return nil, err
 		}
+	case types.LockedTransfer:
+		var lock LockedTransferAction
+		err := rlp.DecodeBytes(action.Data(), &lock)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := am.LockBalance(lock.To, action.AssetID(), action.Value(), &lock); err != nil {
+			return nil, err
+		}
+	case types.ClaimLocked:
+		amount, err := am.ClaimLockedBalance(action.Sender(), action.AssetID(), number)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AccountName), action.Sender(), action.AssetID(), amount, fromAccountExtra...); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AccountName), action.Sender(), 0, action.AssetID(), 0, amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.SetAccountRecovery:
+		var recovery SetRecoveryAction
+		err := rlp.DecodeBytes(action.Data(), &recovery)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.SetRecovery(action.Sender(), &recovery); err != nil {
+			return nil, err
+		}
+	case types.ApproveAccountRecovery:
+		var approve ApproveRecoveryAction
+		err := rlp.DecodeBytes(action.Data(), &approve)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ApproveRecovery(action.Sender(), &approve, number); err != nil {
+			return nil, err
+		}
+	case types.ExecuteAccountRecovery:
+		var execute ExecuteRecoveryAction
+		err := rlp.DecodeBytes(action.Data(), &execute)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ExecuteRecovery(action.Sender(), &execute, number); err != nil {
+			return nil, err
+		}
+	case types.CancelAccountRecovery:
+		if err := am.CancelRecovery(action.Sender()); err != nil {
+			return nil, err
+		}
 	case types.IssueAsset:
 		var issueAsset IssueAsset
 		err := rlp.DecodeBytes(action.Data(), &issueAsset)
@@ -1525,6 +1688,9 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		internalAction = &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
 		internalActions = append(internalActions, internalAction)
 	case types.DestroyAsset:
+		if err := am.ast.CheckTransferable(action.AssetID(), action.Sender()); err != nil {
+			return nil, err
+		}
 		if err := am.SubAccountBalanceByID(common.Name(accountManagerContext.ChainConfig.AssetName), action.AssetID(), action.Value()); err != nil {
 			return nil, err
 		}
@@ -1606,6 +1772,60 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 			return nil, err
 		}
 
+	case types.FreezeAccountAsset:
+		fallthrough
+	case types.UnfreezeAccountAsset:
+		var freeze FreezeAccountAsset
+		err := rlp.DecodeBytes(action.Data(), &freeze)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.checkAssetControl(action.Sender(), freeze.AssetID, accountManagerContext.ChainConfig); err != nil {
+			return nil, err
+		}
+		if exist, err := am.AccountIsExist(freeze.Account); err != nil {
+			return nil, err
+		} else if !exist {
+			return nil, ErrAccountNotExist
+		}
+		if err := am.ast.SetAccountFrozen(freeze.AssetID, freeze.Account, action.Type() == types.FreezeAccountAsset); err != nil {
+			return nil, err
+		}
+	case types.PauseAsset:
+		fallthrough
+	case types.UnpauseAsset:
+		var pause PauseAsset
+		err := rlp.DecodeBytes(action.Data(), &pause)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.checkAssetControl(action.Sender(), pause.AssetID, accountManagerContext.ChainConfig); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetAssetPaused(pause.AssetID, action.Type() == types.PauseAsset); err != nil {
+			return nil, err
+		}
+	case types.ApproveAsset:
+		var approve ApproveAssetAction
+		err := rlp.DecodeBytes(action.Data(), &approve)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.ApproveAsset(action.Sender(), &approve); err != nil {
+			return nil, err
+		}
+	case types.TransferFromAsset:
+		var transfer TransferFromAction
+		err := rlp.DecodeBytes(action.Data(), &transfer)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.TransferFrom(action.Sender(), &transfer, fromAccountExtra...); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, transfer.From, transfer.To, 0, transfer.AssetID, 0, transfer.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
 	case types.Transfer:
 	default:
 		return nil, ErrUnKnownTxType
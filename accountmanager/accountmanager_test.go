@@ -2312,3 +2312,403 @@ func TestAccountManager_AccountHaveCode(t *testing.T) {
 		t.Errorf("TestAccountManager_AccountHaveCode. account not have code error = %v", err)
 	}
 }
+
+func TestAccountManager_LockedTransfer(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	escrow := common.Name(params.DefaultChainconfig.AccountName)
+	funder, owner := common.Name("a123456789lock"), common.Name("a123456789vest")
+	for _, name := range []common.Name{escrow, funder, owner} {
+		pubkey, _ := GeneragePubKey()
+		if err := am.CreateAccount(common.Name("unichain"), name, "", 0, 0, pubkey, ""); err != nil {
+			t.Fatalf("create account %v: %v", name, err)
+		}
+		if err := am.AddAccountBalanceByID(name, 0, big.NewInt(0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := am.AddAccountBalanceByID(funder, 0, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	forkID := params.ForkID4
+	process := func(actionType types.ActionType, from common.Name, value int64, payload []byte, number uint64) error {
+		action := types.NewAction(actionType, from, escrow, 0, 0, 0, big.NewInt(value), payload, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: number, CurForkID: forkID})
+		return err
+	}
+	invalid, _ := rlp.EncodeToBytes(&LockedTransferAction{To: owner, Start: 20, Cliff: 10, End: 30})
+	if err := process(types.LockedTransfer, funder, 100, invalid, 1); err == nil {
+		t.Fatal("locked transfer accepted before ForkID5")
+	}
+	forkID = params.ForkID5
+	if err := process(types.LockedTransfer, funder, 100, invalid, 1); err != ErrLockScheduleInvalid {
+		t.Fatalf("invalid schedule: have %v, want %v", err, ErrLockScheduleInvalid)
+	}
+	payload, _ := rlp.EncodeToBytes(&LockedTransferAction{To: owner, Start: 10, Cliff: 20, End: 110})
+	if err := process(types.LockedTransfer, funder, 100, payload, 1); err != nil {
+		t.Fatalf("locked transfer: %v", err)
+	}
+
+	check := func(balance, locked int64) {
+		if have, _ := am.GetAccountBalanceByID(owner, 0, 0); have.Int64() != balance {
+			t.Fatalf("balance mismatch: have %v, want %v", have, balance)
+		}
+		if have, _ := am.GetAccountBalanceByID(owner, 0, 2); have.Int64() != locked {
+			t.Fatalf("locked balance mismatch: have %v, want %v", have, locked)
+		}
+		if ok, _ := am.CanTransfer(owner, 0, big.NewInt(balance+1)); ok {
+			t.Fatalf("locked balance transferable")
+		}
+	}
+	check(0, 100)
+
+	// nothing vested before the cliff
+	if err := process(types.ClaimLocked, owner, 0, nil, 19); err != ErrNoVestedBalance {
+		t.Fatalf("claim before cliff: have %v, want %v", err, ErrNoVestedBalance)
+	}
+	if err := process(types.ClaimLocked, owner, 0, nil, 60); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	check(50, 50)
+	if err := process(types.ClaimLocked, owner, 0, nil, 200); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	check(100, 0)
+	if acct, _ := am.GetAccountByName(owner); len(acct.LockedBalances) != 0 {
+		t.Fatalf("claimed schedule not dropped: %v", acct.LockedBalances)
+	}
+}
+
+func TestAccountManager_Recovery(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys := common.Name(params.DefaultChainconfig.AccountName)
+	owner := common.Name("a123456789ownr")
+	guardians := []common.Name{common.Name("a123456789grda"), common.Name("a123456789grdb"), common.Name("a123456789grdc")}
+	for _, name := range append([]common.Name{owner}, guardians...) {
+		pubkey, _ := GeneragePubKey()
+		if err := am.CreateAccount(common.Name("unichain"), name, "", 0, 0, pubkey, ""); err != nil {
+			t.Fatalf("create account %v: %v", name, err)
+		}
+	}
+
+	forkID := params.ForkID4
+	process := func(actionType types.ActionType, from common.Name, payload interface{}, number uint64) error {
+		var data []byte
+		if payload != nil {
+			data, _ = rlp.EncodeToBytes(payload)
+		}
+		action := types.NewAction(actionType, from, sys, 0, 0, 0, big.NewInt(0), data, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: number, CurForkID: forkID})
+		return err
+	}
+	newReset := func() *RecoveryReset {
+		pubkey, _ := GeneragePubKey()
+		return &RecoveryReset{Authors: []*common.Author{common.NewAuthor(pubkey, 1)}, Threshold: 1, UpdateAuthorThreshold: 1}
+	}
+	reset, other := newReset(), newReset()
+
+	if err := process(types.SetAccountRecovery, owner, &SetRecoveryAction{Guardians: guardians, Threshold: 2, Delay: 10}, 1); err == nil {
+		t.Fatal("set recovery accepted before ForkID5")
+	}
+	forkID = params.ForkID5
+	if err := process(types.SetAccountRecovery, owner, &SetRecoveryAction{Guardians: guardians, Threshold: 4}, 1); err != ErrRecoveryThreshold {
+		t.Fatalf("invalid threshold: have %v, want %v", err, ErrRecoveryThreshold)
+	}
+	if err := process(types.SetAccountRecovery, owner, &SetRecoveryAction{Guardians: guardians, Threshold: 2, Delay: 10}, 1); err != nil {
+		t.Fatalf("set recovery: %v", err)
+	}
+	if err := process(types.ApproveAccountRecovery, owner, &ApproveRecoveryAction{Account: owner, Reset: reset}, 2); err != ErrGuardianInvalid {
+		t.Fatalf("approve by owner: have %v, want %v", err, ErrGuardianInvalid)
+	}
+	if err := process(types.ApproveAccountRecovery, guardians[0], &ApproveRecoveryAction{Account: owner, Reset: reset}, 5); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if err := process(types.ExecuteAccountRecovery, guardians[0], &ExecuteRecoveryAction{Account: owner}, 100); err != ErrRecoveryNotReady {
+		t.Fatalf("execute below threshold: have %v, want %v", err, ErrRecoveryNotReady)
+	}
+	// a guardian proposing another reset keeps the approvals of the others
+	if err := process(types.ApproveAccountRecovery, guardians[2], &ApproveRecoveryAction{Account: owner, Reset: other}, 6); err != nil {
+		t.Fatalf("approve other: %v", err)
+	}
+	if recovery, _ := am.GetAccountRecovery(owner); len(recovery.Pending) != 2 || len(recovery.Pending[0].Approvals) != 1 {
+		t.Fatalf("approvals of reset lost: %v", recovery.Pending)
+	}
+	if err := process(types.ApproveAccountRecovery, guardians[1], &ApproveRecoveryAction{Account: owner, Reset: reset}, 6); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if err := process(types.ApproveAccountRecovery, guardians[2], &ApproveRecoveryAction{Account: owner, Reset: other}, 7); err != ErrRecoveryPending {
+		t.Fatalf("replace approved reset: have %v, want %v", err, ErrRecoveryPending)
+	}
+	if err := process(types.ExecuteAccountRecovery, guardians[0], &ExecuteRecoveryAction{Account: owner}, 15); err != ErrRecoveryNotReady {
+		t.Fatalf("execute within delay: have %v, want %v", err, ErrRecoveryNotReady)
+	}
+
+	// the owner cancels, guardians need to approve again
+	if err := process(types.CancelAccountRecovery, owner, nil, 15); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if recovery, _ := am.GetAccountRecovery(owner); recovery == nil || len(recovery.Pending) != 0 {
+		t.Fatalf("recovery not cancelled: %v", recovery)
+	}
+	for _, guardian := range guardians[1:] {
+		if err := process(types.ApproveAccountRecovery, guardian, &ApproveRecoveryAction{Account: owner, Reset: reset}, 20); err != nil {
+			t.Fatalf("approve: %v", err)
+		}
+	}
+	version, _ := am.GetAuthorVersion(owner)
+	if err := process(types.ExecuteAccountRecovery, guardians[2], &ExecuteRecoveryAction{Account: owner}, 30); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	acct, _ := am.GetAccountByName(owner)
+	if len(acct.Authors) != 1 || acct.Authors[0].String() != reset.Authors[0].String() {
+		t.Fatalf("authors not reset: %v", acct.Authors)
+	}
+	if acct.GetAuthorVersion() == version {
+		t.Fatalf("author version not updated")
+	}
+	if recovery, _ := am.GetAccountRecovery(owner); recovery == nil || len(recovery.Pending) != 0 {
+		t.Fatalf("executed recovery still pending: %v", recovery)
+	}
+}
+
+func TestAccountManager_AssetControl(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, holder, other := common.Name("a123456789ctrl"), common.Name("a123456789hold"), common.Name("a123456789othr")
+	for _, name := range []common.Name{owner, holder, other} {
+		pubkey, _ := GeneragePubKey()
+		if err := am.CreateAccount(common.Name("unichain"), name, "", 0, 0, pubkey, ""); err != nil {
+			t.Fatalf("create account %v: %v", name, err)
+		}
+	}
+	// the first asset takes the system asset id
+	sys := IssueAsset{AssetName: "sysc0123456789", Symbol: "sysc", Amount: big.NewInt(1000), Owner: owner, Founder: owner, UpperLimit: big.NewInt(1000)}
+	if _, err := am.IssueAsset(owner, sys, blockNumber, 0); err != nil {
+		t.Fatal(err)
+	}
+	issue := IssueAsset{AssetName: "ctrl0123456789", Symbol: "ctrl", Amount: big.NewInt(1000), Owner: owner, Founder: owner, UpperLimit: big.NewInt(1000)}
+	if _, err := am.IssueAsset(owner, issue, blockNumber, 0); err != nil {
+		t.Fatal(err)
+	}
+	assetObj, err := am.GetAssetInfoByName(issue.AssetName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assetID := assetObj.GetAssetID()
+	if err := am.AddAccountBalanceByID(owner, assetID, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	forkID := params.ForkID4
+	process := func(actionType types.ActionType, from common.Name, payload interface{}) error {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, from, common.Name(params.DefaultChainconfig.AssetName), 0, 0, 0, big.NewInt(0), data, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: blockNumber, CurForkID: forkID})
+		return err
+	}
+	if err := process(types.FreezeAccountAsset, owner, &FreezeAccountAsset{AssetID: assetID, Account: holder}); err == nil {
+		t.Fatal("freeze accepted before ForkID5")
+	}
+	if err := process(types.PauseAsset, owner, &PauseAsset{AssetID: assetID}); err == nil {
+		t.Fatal("pause accepted before ForkID5")
+	}
+	forkID = params.ForkID5
+	if err := process(types.PauseAsset, other, &PauseAsset{AssetID: assetID}); err != asset.ErrOwnerMismatch {
+		t.Fatalf("pause by non owner: have %v, want %v", err, asset.ErrOwnerMismatch)
+	}
+	if err := process(types.PauseAsset, owner, &PauseAsset{AssetID: params.DefaultChainconfig.SysTokenID}); err != ErrSysAssetControl {
+		t.Fatalf("pause system asset: have %v, want %v", err, ErrSysAssetControl)
+	}
+
+	if err := process(types.FreezeAccountAsset, owner, &FreezeAccountAsset{AssetID: assetID, Account: holder}); err != nil {
+		t.Fatalf("freeze: %v", err)
+	}
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(10)); err != asset.ErrAccountFrozen {
+		t.Fatalf("transfer to frozen account: have %v, want %v", err, asset.ErrAccountFrozen)
+	}
+	if frozen, err := am.IsAccountFrozen(assetID, holder); err != nil || !frozen {
+		t.Fatalf("holder not frozen: %v %v", frozen, err)
+	}
+	if frozen, _ := am.IsAccountFrozen(assetID, other); frozen {
+		t.Fatalf("freeze leaked to other account")
+	}
+	destroy := types.NewAction(types.DestroyAsset, holder, common.Name(params.DefaultChainconfig.AssetName), 0, assetID, 0, big.NewInt(0), nil, nil)
+	if _, err := am.Process(&types.AccountManagerContext{Action: destroy, ChainConfig: params.DefaultChainconfig, Number: blockNumber}); err != asset.ErrAccountFrozen {
+		t.Fatalf("destroy by frozen account: have %v, want %v", err, asset.ErrAccountFrozen)
+	}
+	if err := am.TransferAsset(owner, other, assetID, big.NewInt(10)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if err := process(types.UnfreezeAccountAsset, owner, &FreezeAccountAsset{AssetID: assetID, Account: holder}); err != nil {
+		t.Fatalf("unfreeze: %v", err)
+	}
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(10)); err != nil {
+		t.Fatalf("transfer after unfreeze: %v", err)
+	}
+
+	if err := process(types.PauseAsset, owner, &PauseAsset{AssetID: assetID}); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if err := am.TransferAsset(other, holder, assetID, big.NewInt(1)); err != asset.ErrAssetPaused {
+		t.Fatalf("transfer of paused asset: have %v, want %v", err, asset.ErrAssetPaused)
+	}
+	if err := process(types.PauseAsset, owner, &PauseAsset{AssetID: assetID}); err != asset.ErrControlNotChanged {
+		t.Fatalf("pause twice: have %v, want %v", err, asset.ErrControlNotChanged)
+	}
+	if err := process(types.UnpauseAsset, owner, &PauseAsset{AssetID: assetID}); err != nil {
+		t.Fatalf("unpause: %v", err)
+	}
+	if control, _ := am.GetAssetControl(assetID); control.Paused {
+		t.Fatalf("control not cleared: %v", control)
+	}
+	if frozen, _ := am.IsAccountFrozen(assetID, holder); frozen {
+		t.Fatalf("holder still frozen")
+	}
+}
+
+func TestAccountManager_Allowance(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, spender, to := common.Name("a123456789ownr"), common.Name("a123456789spnd"), common.Name("a123456789rcpt")
+	for _, name := range []common.Name{owner, spender, to} {
+		pubkey, _ := GeneragePubKey()
+		if err := am.CreateAccount(common.Name("unichain"), name, "", 0, 0, pubkey, ""); err != nil {
+			t.Fatalf("create account %v: %v", name, err)
+		}
+	}
+	issue := IssueAsset{AssetName: "allw0123456789", Symbol: "allw", Amount: big.NewInt(1000), Owner: owner, Founder: owner, UpperLimit: big.NewInt(1000)}
+	if _, err := am.IssueAsset(owner, issue, blockNumber, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(owner, 0, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	forkID := params.ForkID4
+	process := func(actionType types.ActionType, from common.Name, payload interface{}) error {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, from, common.Name(params.DefaultChainconfig.AssetName), 0, 0, 0, big.NewInt(0), data, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, ChainConfig: params.DefaultChainconfig, Number: blockNumber, CurForkID: forkID})
+		return err
+	}
+	if err := process(types.ApproveAsset, owner, &ApproveAssetAction{Spender: spender, AssetID: 0, Amount: big.NewInt(100)}); err == nil {
+		t.Fatal("approve before fork succeeded")
+	}
+
+	forkID = params.ForkID5
+	transfer := &TransferFromAction{From: owner, To: to, AssetID: 0, Amount: big.NewInt(60)}
+	if err := process(types.TransferFromAsset, spender, transfer); err != ErrAllowanceExceeded {
+		t.Fatalf("transfer without allowance: have %v, want %v", err, ErrAllowanceExceeded)
+	}
+	if err := process(types.ApproveAsset, owner, &ApproveAssetAction{Spender: owner, AssetID: 0, Amount: big.NewInt(100)}); err != ErrSpenderInvalid {
+		t.Fatalf("approve self: have %v, want %v", err, ErrSpenderInvalid)
+	}
+	if err := process(types.ApproveAsset, owner, &ApproveAssetAction{Spender: spender, AssetID: 0, Amount: big.NewInt(100)}); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if err := process(types.TransferFromAsset, spender, transfer); err != nil {
+		t.Fatalf("transfer from: %v", err)
+	}
+	if err := process(types.TransferFromAsset, spender, transfer); err != ErrAllowanceExceeded {
+		t.Fatalf("transfer over allowance: have %v, want %v", err, ErrAllowanceExceeded)
+	}
+	if allowance, _ := am.GetAllowance(owner, spender, 0); allowance.Int64() != 40 {
+		t.Fatalf("allowance mismatch: have %v, want 40", allowance)
+	}
+	if balance, _ := am.GetAccountBalanceByID(to, 0, 0); balance.Int64() != 60 {
+		t.Fatalf("balance mismatch: have %v, want 60", balance)
+	}
+
+	if err := process(types.ApproveAsset, owner, &ApproveAssetAction{Spender: spender, AssetID: 0, Amount: big.NewInt(0)}); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if allowance, _ := am.GetAllowance(owner, spender, 0); allowance.Sign() != 0 {
+		t.Fatalf("allowance not revoked: %v", allowance)
+	}
+}
+
+func TestAccountManager_ProofKeys(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	cachedb := state.NewDatabase(db)
+	statedb, _ := state.New(common.Hash{}, cachedb)
+	am, err := NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := common.Name("a123456789prof")
+	pubkey, _ := GeneragePubKey()
+	if err := am.CreateAccount(common.Name("unichain"), name, "", 0, 0, pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	batch := db.NewBatch()
+	root, err := statedb.Commit(batch, common.Hash{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cachedb.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	batch.Write()
+
+	statedb, _ = state.New(root, cachedb)
+	acctManager := common.Name(acctManagerName)
+	proof, err := statedb.GetProof(AccountNameIDKey(acctManager, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := state.VerifyProof(root, AccountNameIDKey(acctManager, name), proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accountID uint64
+	if err := rlp.DecodeBytes(value, &accountID); err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err = statedb.GetProof(AccountInfoKey(acctManager, accountID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err = state.VerifyProof(root, AccountInfoKey(acctManager, accountID), proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var acct Account
+	if err := rlp.DecodeBytes(value, &acct); err != nil {
+		t.Fatal(err)
+	}
+	if acct.GetName() != name || acct.GetAccountID() != accountID {
+		t.Fatalf("proven account mismatch: have %v %v, want %v %v", acct.GetName(), acct.GetAccountID(), name, accountID)
+	}
+}
+
+func TestCreate2ContractName(t *testing.T) {
+	deployer := common.Name("create2deployer")
+	code := []byte{0x60, 0x00}
+	name := Create2ContractName(deployer, common.HexToHash("0x01"), code)
+	if name != Create2ContractName(deployer, common.HexToHash("0x01"), code) {
+		t.Fatal("contract name not deterministic")
+	}
+	if !deployer.IsChildren(name) || len(name.String()) != len(deployer.String())+1+contractNameLength {
+		t.Fatalf("invalid contract name %v", name)
+	}
+	if !name.IsValid(acctRegExp, accountNameLength) || !name.IsValid(acctRegExpFork1, accountNameLength) {
+		t.Fatalf("contract name %v not a valid account name", name)
+	}
+	if name == Create2ContractName(deployer, common.HexToHash("0x02"), code) {
+		t.Fatal("contract name not changed by salt")
+	}
+	if name == Create2ContractName(deployer, common.HexToHash("0x01"), []byte{0x60, 0x01}) {
+		t.Fatal("contract name not changed by code")
+	}
+}
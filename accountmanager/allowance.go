@@ -0,0 +1,130 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+const acctAllowancePrefix = "acctAllowance"
+
+// ApproveAssetAction set the amount of asset spender may transfer out of the
+// sender, replacing the previous allowance. A zero amount revokes it.
+type ApproveAssetAction struct {
+	Spender common.Name `json:"spender"`
+	AssetID uint64      `json:"assetId,omitempty"`
+	Amount  *big.Int    `json:"amount"`
+}
+
+// TransferFromAction transfer asset of From to To, spending the allowance
+// From approved for the sender.
+type TransferFromAction struct {
+	From    common.Name `json:"from"`
+	To      common.Name `json:"to"`
+	AssetID uint64      `json:"assetId,omitempty"`
+	Amount  *big.Int    `json:"amount"`
+}
+
+func (am *AccountManager) allowanceKey(owner, spender common.Name, assetID uint64) (string, error) {
+	ownerID, err := am.GetAccountIDByName(owner)
+	if err != nil {
+		return "", err
+	}
+	spenderID, err := am.GetAccountIDByName(spender)
+	if err != nil {
+		return "", err
+	}
+	if ownerID == 0 || spenderID == 0 {
+		return "", ErrAccountNotExist
+	}
+	return fmt.Sprintf("%s%d_%d_%d", acctAllowancePrefix, ownerID, spenderID, assetID), nil
+}
+
+// GetAllowance get the amount of asset spender may still transfer out of owner
+func (am *AccountManager) GetAllowance(owner, spender common.Name, assetID uint64) (*big.Int, error) {
+	key, err := am.allowanceKey(owner, spender, assetID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := am.sdb.Get(acctManagerName, key)
+	if err != nil {
+		return nil, err
+	}
+	amount := big.NewInt(0)
+	if len(b) == 0 {
+		return amount, nil
+	}
+	if err := rlp.DecodeBytes(b, amount); err != nil {
+		return nil, err
+	}
+	return amount, nil
+}
+
+func (am *AccountManager) setAllowance(owner, spender common.Name, assetID uint64, amount *big.Int) error {
+	key, err := am.allowanceKey(owner, spender, assetID)
+	if err != nil {
+		return err
+	}
+	if amount.Sign() == 0 {
+		am.sdb.Delete(acctManagerName, key)
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(amount)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, key, b)
+	return nil
+}
+
+// ApproveAsset set the allowance of spender over the asset of owner
+func (am *AccountManager) ApproveAsset(owner common.Name, action *ApproveAssetAction) error {
+	if action.Spender == owner {
+		return ErrSpenderInvalid
+	}
+	if action.Amount == nil || action.Amount.Sign() < 0 {
+		return ErrAmountValueInvalid
+	}
+	if _, err := am.ast.GetAssetObjectByID(action.AssetID); err != nil {
+		return err
+	}
+	return am.setAllowance(owner, action.Spender, action.AssetID, action.Amount)
+}
+
+// TransferFrom transfer asset of action.From to action.To by spender, deducting
+// the amount from its allowance.
+func (am *AccountManager) TransferFrom(spender common.Name, action *TransferFromAction, fromAccountExtra ...common.Name) error {
+	if action.Amount == nil || action.Amount.Sign() <= 0 {
+		return ErrAmountValueInvalid
+	}
+	allowance, err := am.GetAllowance(action.From, spender, action.AssetID)
+	if err != nil {
+		return err
+	}
+	if allowance.Cmp(action.Amount) < 0 {
+		return ErrAllowanceExceeded
+	}
+	if err := am.setAllowance(action.From, spender, action.AssetID, allowance.Sub(allowance, action.Amount)); err != nil {
+		return err
+	}
+	fromAccountExtra = append(fromAccountExtra, spender)
+	return am.TransferAsset(action.From, action.To, action.AssetID, action.Amount, fromAccountExtra...)
+}
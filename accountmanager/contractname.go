@@ -0,0 +1,46 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+)
+
+// contractNameLength is the length of the sub name of the accounts created
+// by Create2Contract, it fits the sub name length of all account levels.
+const contractNameLength = 8
+
+var contractNameSpace = new(big.Int).Exp(big.NewInt(36), big.NewInt(contractNameLength), nil)
+
+// Create2ContractAction deploy the contract code to the sub account of the
+// sender derived from the salt and the code hash.
+type Create2ContractAction struct {
+	Salt common.Hash `json:"salt"`
+	Code []byte      `json:"code"`
+}
+
+// Create2ContractName returns the name of the sub account of deployer the code
+// is deployed to with salt, it is known before the contract is deployed.
+func Create2ContractName(deployer common.Name, salt common.Hash, code []byte) common.Name {
+	hash := crypto.Keccak256([]byte{0xff}, []byte(deployer.String()), salt.Bytes(), crypto.Keccak256(code))
+	sub := new(big.Int).Mod(new(big.Int).SetBytes(hash), contractNameSpace).Text(36)
+	return common.Name(deployer.String() + "." + strings.Repeat("0", contractNameLength-len(sub)) + sub)
+}
@@ -52,4 +52,15 @@ var (
 	ErrNegativeAmount         = errors.New("negative amount")
 	ErrAmountMustBeZero       = errors.New("amount must be zero")
 	ErrAssetOwnerInvalid      = errors.New("asset owner Invalid ")
+	ErrLockScheduleInvalid    = errors.New("lock schedule invalid")
+	ErrNoVestedBalance        = errors.New("no vested balance to claim")
+	ErrGuardianInvalid        = errors.New("guardian invalid")
+	ErrRecoveryThreshold      = errors.New("recovery threshold invalid")
+	ErrRecoveryResetInvalid   = errors.New("recovery authors or thresholds invalid")
+	ErrRecoveryNotExist       = errors.New("recovery not exist")
+	ErrRecoveryPending        = errors.New("approved recovery pending")
+	ErrRecoveryNotReady       = errors.New("recovery not approved or delay not over")
+	ErrSysAssetControl        = errors.New("system asset can not be frozen or paused")
+	ErrSpenderInvalid         = errors.New("spender invalid")
+	ErrAllowanceExceeded      = errors.New("insufficient allowance")
 )
@@ -0,0 +1,302 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+const acctRecoveryPrefix = "acctRecovery"
+
+// RecoveryReset replaces the authors and thresholds of an account.
+type RecoveryReset struct {
+	Authors               []*common.Author `json:"authors"`
+	Threshold             uint64           `json:"threshold"`
+	UpdateAuthorThreshold uint64           `json:"updateAuthorThreshold"`
+}
+
+// PendingRecovery is a reset proposed by guardians of an account, with the
+// guardians approving it.
+type PendingRecovery struct {
+	Reset     *RecoveryReset `json:"reset"`
+	Approvals []common.Name  `json:"approvals"`
+	Number    uint64         `json:"number"` // block number it can be executed at, 0 until approved by threshold guardians
+}
+
+// AccountRecovery is the guardian configuration of an account.
+type AccountRecovery struct {
+	Guardians []common.Name      `json:"guardians"`
+	Threshold uint64             `json:"threshold"`
+	Delay     uint64             `json:"delay"`   // blocks between approval and execution
+	Pending   []*PendingRecovery `json:"pending"` // resets approved by at least one guardian, every guardian approves one
+}
+
+// SetRecoveryAction set, or remove when Guardians is empty, the guardians of
+// the sender.
+type SetRecoveryAction struct {
+	Guardians []common.Name `json:"guardians,omitempty"`
+	Threshold uint64        `json:"threshold,omitempty"`
+	Delay     uint64        `json:"delay,omitempty"`
+}
+
+// ApproveRecoveryAction approve, as a guardian, a reset of account.
+type ApproveRecoveryAction struct {
+	Account common.Name    `json:"account,omitempty"`
+	Reset   *RecoveryReset `json:"reset,omitempty"`
+}
+
+// ExecuteRecoveryAction execute, as a guardian, the approved reset of account.
+type ExecuteRecoveryAction struct {
+	Account common.Name `json:"account,omitempty"`
+}
+
+func (r *AccountRecovery) isGuardian(name common.Name) bool {
+	for _, guardian := range r.Guardians {
+		if guardian == name {
+			return true
+		}
+	}
+	return false
+}
+
+// approvedReset returns the reset approved by threshold guardians, nil if none.
+func (r *AccountRecovery) approvedReset() *PendingRecovery {
+	for _, pending := range r.Pending {
+		if pending.Number != 0 {
+			return pending
+		}
+	}
+	return nil
+}
+
+func (p *PendingRecovery) approved(name common.Name) bool {
+	for _, approval := range p.Approvals {
+		if approval == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *PendingRecovery) revoke(name common.Name) {
+	for i, approval := range p.Approvals {
+		if approval == name {
+			p.Approvals = append(p.Approvals[:i], p.Approvals[i+1:]...)
+			return
+		}
+	}
+}
+
+// GetAccountRecovery get the guardian configuration of account, nil if not set
+func (am *AccountManager) GetAccountRecovery(accountName common.Name) (*AccountRecovery, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	b, err := am.sdb.Get(acctManagerName, acctRecoveryPrefix+strconv.FormatUint(accountID, 10))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var recovery AccountRecovery
+	if err := rlp.DecodeBytes(b, &recovery); err != nil {
+		return nil, err
+	}
+	return &recovery, nil
+}
+
+func (am *AccountManager) setAccountRecovery(accountName common.Name, recovery *AccountRecovery) error {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return err
+	}
+	if accountID == 0 {
+		return ErrAccountNotExist
+	}
+	key := acctRecoveryPrefix + strconv.FormatUint(accountID, 10)
+	if recovery == nil {
+		am.sdb.Delete(acctManagerName, key)
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(recovery)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, key, b)
+	return nil
+}
+
+// SetRecovery set the guardians of account, dropping any pending recovery
+func (am *AccountManager) SetRecovery(accountName common.Name, action *SetRecoveryAction) error {
+	if len(action.Guardians) == 0 {
+		return am.setAccountRecovery(accountName, nil)
+	}
+	if uint64(len(action.Guardians)) > params.MaxAuthorNum {
+		return fmt.Errorf("account guardian length can not exceed %d", params.MaxAuthorNum)
+	}
+	if action.Threshold == 0 || action.Threshold > uint64(len(action.Guardians)) {
+		return ErrRecoveryThreshold
+	}
+	seen := make(map[common.Name]bool)
+	for _, guardian := range action.Guardians {
+		if guardian == accountName || seen[guardian] {
+			return ErrGuardianInvalid
+		}
+		seen[guardian] = true
+		if exist, err := am.AccountIsExist(guardian); err != nil {
+			return err
+		} else if !exist {
+			return ErrAccountNotExist
+		}
+	}
+	return am.setAccountRecovery(accountName, &AccountRecovery{
+		Guardians: action.Guardians,
+		Threshold: action.Threshold,
+		Delay:     action.Delay,
+	})
+}
+
+// ApproveRecovery approve the reset of account by guardian, starting the delay
+// once approved by threshold guardians. Every guardian approves one reset, so
+// approving another one moves its approval, without touching the approvals of
+// the other guardians. No reset can be approved once one has the threshold.
+func (am *AccountManager) ApproveRecovery(guardian common.Name, action *ApproveRecoveryAction, number uint64) error {
+	recovery, err := am.GetAccountRecovery(action.Account)
+	if err != nil {
+		return err
+	}
+	if recovery == nil {
+		return ErrRecoveryNotExist
+	}
+	if !recovery.isGuardian(guardian) {
+		return ErrGuardianInvalid
+	}
+	if err := checkRecoveryReset(action.Reset); err != nil {
+		return err
+	}
+
+	hash := types.RlpHash(action.Reset)
+	if approved := recovery.approvedReset(); approved != nil && types.RlpHash(approved.Reset) != hash {
+		return ErrRecoveryPending
+	}
+
+	var (
+		target  *PendingRecovery
+		pending = make([]*PendingRecovery, 0, len(recovery.Pending)+1)
+	)
+	for _, p := range recovery.Pending {
+		if types.RlpHash(p.Reset) == hash {
+			target = p
+		} else {
+			p.revoke(guardian)
+		}
+		if len(p.Approvals) > 0 || p == target {
+			pending = append(pending, p)
+		}
+	}
+	if target == nil {
+		target = &PendingRecovery{Reset: action.Reset}
+		pending = append(pending, target)
+	}
+	if !target.approved(guardian) {
+		target.Approvals = append(target.Approvals, guardian)
+	}
+	if target.Number == 0 && uint64(len(target.Approvals)) >= recovery.Threshold {
+		target.Number = number + recovery.Delay
+	}
+	recovery.Pending = pending
+	return am.setAccountRecovery(action.Account, recovery)
+}
+
+// ExecuteRecovery reset the authors of account once the delay of its approved
+// recovery is over
+func (am *AccountManager) ExecuteRecovery(guardian common.Name, action *ExecuteRecoveryAction, number uint64) error {
+	recovery, err := am.GetAccountRecovery(action.Account)
+	if err != nil {
+		return err
+	}
+	if recovery == nil {
+		return ErrRecoveryNotExist
+	}
+	if !recovery.isGuardian(guardian) {
+		return ErrGuardianInvalid
+	}
+	pending := recovery.approvedReset()
+	if pending == nil || number < pending.Number {
+		return ErrRecoveryNotReady
+	}
+
+	acct, err := am.GetAccountByName(action.Account)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return ErrAccountNotExist
+	}
+	acct.Authors = pending.Reset.Authors
+	acct.SetThreshold(pending.Reset.Threshold)
+	acct.SetUpdateAuthorThreshold(pending.Reset.UpdateAuthorThreshold)
+	acct.SetAuthorVersion()
+	if err := am.SetAccount(acct); err != nil {
+		return err
+	}
+	recovery.Pending = nil
+	return am.setAccountRecovery(action.Account, recovery)
+}
+
+// CancelRecovery drop the pending recoveries of account
+func (am *AccountManager) CancelRecovery(accountName common.Name) error {
+	recovery, err := am.GetAccountRecovery(accountName)
+	if err != nil {
+		return err
+	}
+	if recovery == nil || len(recovery.Pending) == 0 {
+		return ErrRecoveryNotExist
+	}
+	recovery.Pending = nil
+	return am.setAccountRecovery(accountName, recovery)
+}
+
+// checkRecoveryReset makes sure the account stays usable after the reset.
+func checkRecoveryReset(reset *RecoveryReset) error {
+	if reset == nil || len(reset.Authors) == 0 {
+		return ErrRecoveryResetInvalid
+	}
+	if uint64(len(reset.Authors)) > params.MaxAuthorNum {
+		return fmt.Errorf("account author length can not exceed %d", params.MaxAuthorNum)
+	}
+	var weight uint64
+	for _, author := range reset.Authors {
+		weight += author.GetWeight()
+	}
+	if reset.Threshold == 0 || reset.UpdateAuthorThreshold == 0 ||
+		weight < reset.Threshold || weight < reset.UpdateAuthorThreshold {
+		return ErrRecoveryResetInvalid
+	}
+	return nil
+}
@@ -39,12 +39,20 @@ var (
 	assetCountPrefix  = "assetCount"
 	assetNameIDPrefix = "assetNameId"
 	assetObjectPrefix = "assetDefinitionObject"
+	assetPausedPrefix = "assetPaused"
+	assetFrozenPrefix = "assetFrozen"
 )
 
 type Asset struct {
 	sdb *state.StateDB
 }
 
+// AssetControl is the transfer restrictions set by the owner of an asset.
+// Frozen accounts are stored one key per account, see IsAccountFrozen.
+type AssetControl struct {
+	Paused bool `json:"paused"`
+}
+
 func SetAssetNameConfig(config *Config) bool {
 	if config.AssetNameLevel < 1 || config.AssetNameLength < config.MainAssetNameMinLength || config.MainAssetNameMinLength >= config.MainAssetNameMaxLength {
 		panic("asset name level config error")
@@ -342,6 +350,9 @@ func (a *Asset) DestroyAsset(accountName common.Name, assetID uint64, amount *bi
 	if asset == nil {
 		return ErrAssetNotExist
 	}
+	if err := a.CheckTransferable(assetID); err != nil {
+		return err
+	}
 
 	//everyone can destory asset
 	// if asset.GetAssetOwner() != accountName {
@@ -628,3 +639,86 @@ func (a *Asset) CheckOwner(fromName common.Name, assetID uint64) error {
 	}
 	return nil
 }
+
+func assetPausedKey(assetID uint64) string {
+	return assetPausedPrefix + strconv.FormatUint(assetID, 10)
+}
+
+func assetFrozenKey(assetID uint64, accountName common.Name) string {
+	return assetFrozenPrefix + strconv.FormatUint(assetID, 10) + "_" + accountName.String()
+}
+
+// setFlag store a set flag as a single byte and delete the key when cleared
+func (a *Asset) setFlag(key string, set bool) error {
+	b, err := a.sdb.Get(assetManagerName, key)
+	if err != nil {
+		return err
+	}
+	if (len(b) != 0) == set {
+		return ErrControlNotChanged
+	}
+	if set {
+		a.sdb.Put(assetManagerName, key, []byte{1})
+	} else {
+		a.sdb.Delete(assetManagerName, key)
+	}
+	return nil
+}
+
+func (a *Asset) getFlag(key string) (bool, error) {
+	b, err := a.sdb.Get(assetManagerName, key)
+	if err != nil {
+		return false, err
+	}
+	return len(b) != 0, nil
+}
+
+//GetAssetControl get the transfer restrictions of an asset
+func (a *Asset) GetAssetControl(assetID uint64) (*AssetControl, error) {
+	paused, err := a.IsAssetPaused(assetID)
+	if err != nil {
+		return nil, err
+	}
+	return &AssetControl{Paused: paused}, nil
+}
+
+//IsAssetPaused check all transfers of an asset are paused
+func (a *Asset) IsAssetPaused(assetID uint64) (bool, error) {
+	return a.getFlag(assetPausedKey(assetID))
+}
+
+//IsAccountFrozen check the holdings of an asset by account are frozen
+func (a *Asset) IsAccountFrozen(assetID uint64, accountName common.Name) (bool, error) {
+	return a.getFlag(assetFrozenKey(assetID, accountName))
+}
+
+//SetAssetPaused pause or unpause all transfers of an asset
+func (a *Asset) SetAssetPaused(assetID uint64, paused bool) error {
+	return a.setFlag(assetPausedKey(assetID), paused)
+}
+
+//SetAccountFrozen freeze or unfreeze the holdings of an asset by account
+func (a *Asset) SetAccountFrozen(assetID uint64, accountName common.Name, frozen bool) error {
+	return a.setFlag(assetFrozenKey(assetID, accountName), frozen)
+}
+
+//CheckTransferable check the asset is not paused and none of names is frozen
+func (a *Asset) CheckTransferable(assetID uint64, names ...common.Name) error {
+	paused, err := a.IsAssetPaused(assetID)
+	if err != nil {
+		return err
+	}
+	if paused {
+		return ErrAssetPaused
+	}
+	for _, name := range names {
+		frozen, err := a.IsAccountFrozen(assetID, name)
+		if err != nil {
+			return err
+		}
+		if frozen {
+			return ErrAccountFrozen
+		}
+	}
+	return nil
+}
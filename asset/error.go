@@ -35,4 +35,7 @@ var (
 	ErrDetailTooLong        = errors.New("detail info exceed maximum")
 	ErrNegativeAmount       = errors.New("negative amount")
 	ErrAmountOverMax256     = errors.New("amount over max uint256")
+	ErrAssetPaused          = errors.New("asset is paused")
+	ErrAccountFrozen        = errors.New("account is frozen for asset")
+	ErrControlNotChanged    = errors.New("asset control not changed")
 )
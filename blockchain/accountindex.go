@@ -0,0 +1,194 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/fdb"
+)
+
+// accountTxIndexer keeps the account transaction index in step with the
+// canonical chain. Entry counters are cached, so that several blocks can be
+// indexed or unindexed within a single batch.
+type accountTxIndexer struct {
+	db     rawdb.DatabaseReader
+	head   uint64 // number of the last indexed block
+	valid  bool   // whether the index covers every block up to head
+	fresh  bool   // whether the index is rebuilt from scratch, ignoring stored counters
+	counts map[common.Name]uint64
+	dirty  map[common.Name]struct{}
+}
+
+func newAccountTxIndexer(db rawdb.DatabaseReader) *accountTxIndexer {
+	head, valid := rawdb.ReadAccountTxIndexHead(db)
+	return &accountTxIndexer{
+		db:     db,
+		head:   head,
+		valid:  valid,
+		counts: make(map[common.Name]uint64),
+		dirty:  make(map[common.Name]struct{}),
+	}
+}
+
+// accountTxNames returns the accounts an action is indexed under.
+func accountTxNames(action *types.Action) []common.Name {
+	if action.Sender() == action.Recipient() {
+		return []common.Name{action.Sender()}
+	}
+	return []common.Name{action.Sender(), action.Recipient()}
+}
+
+func (idx *accountTxIndexer) count(name common.Name) uint64 {
+	if count, ok := idx.counts[name]; ok {
+		return count
+	}
+	if idx.fresh {
+		return 0
+	}
+	return rawdb.ReadAccountTxCount(idx.db, name)
+}
+
+func (idx *accountTxIndexer) setCount(name common.Name, count uint64) {
+	idx.counts[name] = count
+	idx.dirty[name] = struct{}{}
+}
+
+// index appends the actions of the block to the index if the block directly
+// follows the indexed head, otherwise the index is left untouched.
+func (idx *accountTxIndexer) index(batch fdb.Batch, block *types.Block) {
+	if idx == nil || !idx.valid || idx.head+1 != block.NumberU64() {
+		return
+	}
+	idx.apply(batch, block)
+}
+
+// unindex removes the actions of the block from the index if the block is the
+// indexed head, otherwise the index is left untouched.
+func (idx *accountTxIndexer) unindex(batch fdb.Batch, block *types.Block) {
+	if idx == nil || !idx.valid || idx.head != block.NumberU64() || block.NumberU64() == 0 {
+		return
+	}
+	txs := block.Transactions()
+	for i := len(txs) - 1; i >= 0; i-- {
+		actions := txs[i].GetActions()
+		for j := len(actions) - 1; j >= 0; j-- {
+			names := accountTxNames(actions[j])
+			for k := len(names) - 1; k >= 0; k-- {
+				count := idx.count(names[k]) - 1
+				rawdb.DeleteAccountTxIndexEntry(batch, names[k], count)
+				idx.setCount(names[k], count)
+			}
+		}
+	}
+	idx.head = block.NumberU64() - 1
+}
+
+func (idx *accountTxIndexer) apply(batch fdb.Batch, block *types.Block) {
+	for i, tx := range block.Transactions() {
+		for j, action := range tx.GetActions() {
+			entry := &rawdb.AccountTxIndexEntry{
+				TxHash:      tx.Hash(),
+				BlockNumber: block.NumberU64(),
+				TxIndex:     uint64(i),
+				ActionIndex: uint64(j),
+			}
+			for _, name := range accountTxNames(action) {
+				count := idx.count(name)
+				rawdb.WriteAccountTxIndexEntry(batch, name, count, entry)
+				idx.setCount(name, count+1)
+			}
+		}
+	}
+	idx.head = block.NumberU64()
+}
+
+// commit writes the modified counters and the index head into the batch.
+func (idx *accountTxIndexer) commit(batch fdb.Batch) {
+	if idx == nil || !idx.valid {
+		return
+	}
+	for name := range idx.dirty {
+		rawdb.WriteAccountTxCount(batch, name, idx.counts[name])
+	}
+	idx.dirty = make(map[common.Name]struct{})
+	if !idx.fresh {
+		rawdb.WriteAccountTxIndexHead(batch, idx.head)
+	}
+}
+
+// checkAccountTxIndex makes sure the account transaction index is usable, it
+// is built right away for a new chain and reported if it lags behind.
+func (bc *BlockChain) checkAccountTxIndex() error {
+	current := bc.CurrentBlock().NumberU64()
+	head, ok := rawdb.ReadAccountTxIndexHead(bc.db)
+	if !ok && current == 0 {
+		return bc.ReindexAccountTxs()
+	}
+	if !ok || head != current {
+		log.Warn("Account tx index is not in step with the chain, run 'uni chain reindex' to rebuild it", "indexed", head, "current", current)
+	}
+	return nil
+}
+
+// ReindexAccountTxs rebuilds the account transaction index from the genesis
+// block up to the current head block.
+func (bc *BlockChain) ReindexAccountTxs() error {
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	rawdb.DeleteAccountTxIndexHead(bc.db)
+
+	var (
+		idx             = newAccountTxIndexer(bc.db)
+		batch           = bc.db.NewBatch()
+		last            = bc.CurrentBlock().NumberU64()
+		start, reported = time.Now(), time.Now()
+	)
+	idx.valid, idx.fresh = true, true
+
+	for nr := uint64(0); nr <= last; nr++ {
+		block := bc.GetBlockByNumber(nr)
+		if block == nil {
+			return fmt.Errorf("reindex failed on #%d: not found", nr)
+		}
+		idx.apply(batch, block)
+		if batch.ValueSize() >= fdb.IdealBatchSize {
+			idx.commit(batch)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(reported) >= statsReportLimit {
+			log.Info("Reindexing account txs", "number", nr, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	idx.commit(batch)
+	rawdb.WriteAccountTxIndexHead(batch, last)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Reindexed account txs", "blocks", last+1, "accounts", len(idx.counts), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
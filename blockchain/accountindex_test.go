@@ -0,0 +1,86 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/rawdb"
+)
+
+func checkAccountTxIndex(t *testing.T, chain *BlockChain, name common.Name) {
+	head, ok := rawdb.ReadAccountTxIndexHead(chain.db)
+	if !ok || head != chain.CurrentBlock().NumberU64() {
+		t.Fatalf("index head mismatch: have %v/%v, want %v", head, ok, chain.CurrentBlock().NumberU64())
+	}
+
+	var seq uint64
+	for nr := uint64(0); nr <= head; nr++ {
+		block := chain.GetBlockByNumber(nr)
+		for i, tx := range block.Transactions() {
+			for j, action := range tx.GetActions() {
+				if action.Sender() != name && action.Recipient() != name {
+					continue
+				}
+				entry := rawdb.ReadAccountTxIndexEntry(chain.db, name, seq)
+				if entry == nil {
+					t.Fatalf("entry %v not found", seq)
+				}
+				if entry.TxHash != tx.Hash() || entry.BlockNumber != nr || entry.TxIndex != uint64(i) || entry.ActionIndex != uint64(j) {
+					t.Fatalf("entry %v mismatch: have %x/%d/%d/%d, want %x/%d/%d/%d", seq, entry.TxHash, entry.BlockNumber,
+						entry.TxIndex, entry.ActionIndex, tx.Hash(), nr, i, j)
+				}
+				seq++
+			}
+		}
+	}
+	if count := rawdb.ReadAccountTxCount(chain.db, name); count != seq {
+		t.Fatalf("count mismatch: have %v, want %v", count, seq)
+	}
+}
+
+func TestAccountTxIndexReorg(t *testing.T) {
+	genesis := DefaultGenesis()
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	chain.vmConfig.AccountIndexFlag = true
+	if err := chain.checkAccountTxIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	chain, _ = makeNewChain(t, genesis, chain, 10, canonicalSeed)
+	name := common.StrToName(genesis.Config.SysName)
+	checkAccountTxIndex(t, chain, name)
+
+	// generate fork blocks
+	forkChain := newCanonical(t, genesis)
+	defer forkChain.Stop()
+
+	_, forkBlocks := makeNewChain(t, genesis, forkChain, 11, forkSeed)
+	if _, err := chain.InsertChain(forkBlocks); err != nil {
+		t.Fatal(err)
+	}
+	checkBlocksInsert(t, chain, forkBlocks)
+	checkAccountTxIndex(t, chain, name)
+
+	if err := chain.ReindexAccountTxs(); err != nil {
+		t.Fatal(err)
+	}
+	checkAccountTxIndex(t, chain, name)
+}
@@ -116,6 +116,11 @@ func NewBlockChain(db fdb.Database, statePruning bool, vmConfig vm.Config, chain
 		badhashesMap[common.HexToHash(hash)] = true
 	}
 
+	// The internal action index is built from the stored internal actions.
+	if vmConfig.InternalIndexFlag {
+		vmConfig.ContractLogFlag = true
+	}
+
 	bc := &BlockChain{
 		chainConfig:      chainConfig,
 		statePruning:     statePruning,
@@ -178,6 +183,17 @@ func NewBlockChain(db fdb.Database, statePruning bool, vmConfig vm.Config, chain
 		log.Info("Start chain with a specified block number", "start", bc.CurrentBlock().NumberU64(), "irreversible", bc.IrreversibleNumber())
 	}
 
+	if vmConfig.AccountIndexFlag {
+		if err := bc.checkAccountTxIndex(); err != nil {
+			return nil, err
+		}
+	}
+	if vmConfig.InternalIndexFlag {
+		if err := bc.checkInternalActionIndex(); err != nil {
+			return nil, err
+		}
+	}
+
 	bc.station = newStation(bc, 0)
 	go bc.update()
 	return bc, nil
@@ -662,14 +678,27 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	}
 
 	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+	var detailTxs []*types.DetailTx
 	if bc.vmConfig.ContractLogFlag {
-		detailTxs := make([]*types.DetailTx, len(receipts))
+		detailTxs = make([]*types.DetailTx, len(receipts))
 		for i := 0; i < len(receipts); i++ {
 			detailTxs[i] = receipts[i].GetInternalTxsLog()
 		}
 		rawdb.WriteDetailTxs(batch, block.Hash(), block.NumberU64(), detailTxs)
 	}
 
+	var (
+		txIndexer       *accountTxIndexer
+		internalIndexer *internalActionIndexer
+	)
+	if bc.vmConfig.AccountIndexFlag {
+		txIndexer = newAccountTxIndexer(bc.db)
+	}
+	if bc.vmConfig.InternalIndexFlag {
+		internalIndexer = newInternalActionIndexer(bc.db)
+		internalIndexer.known[block.Hash()] = detailTxs
+	}
+
 	currentBlock := bc.CurrentBlock()
 	localTd := bc.GetTd(currentBlock.Hash(), currentBlock.NumberU64())
 	reorg := externTd.Cmp(localTd) > 0 || strings.Compare(block.Coinbase().String(), bc.chainConfig.SysName) == 0
@@ -681,19 +710,23 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	if reorg {
 		// Reorganise the chain if the parent is not the head block
 		if block.ParentHash() != currentBlock.Hash() {
-			if err = bc.reorgChain(currentBlock, block, batch); err != nil {
+			if err = bc.reorgChain(currentBlock, block, batch, txIndexer, internalIndexer); err != nil {
 				return false, err
 			}
 		}
 
 		// Write the positional metadata for transaction/receipt lookups and preimages
 		rawdb.WriteTxLookupEntries(batch, block)
+		txIndexer.index(batch, block)
+		internalIndexer.index(batch, block)
 		rawdb.WritePreimages(batch, block.NumberU64(), state.Preimages())
 		isCanon = true
 	}
 
 	if isCanon {
 		bc.insert(batch, block)
+		txIndexer.commit(batch)
+		internalIndexer.commit(batch)
 	}
 
 	if err := batch.Write(); err != nil {
@@ -898,7 +931,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []*types.Log, error)
 	return 0, coalescedLogs, nil
 }
 
-func (bc *BlockChain) reorgChain(oldBlock, newBlock *types.Block, batch fdb.Batch) error {
+func (bc *BlockChain) reorgChain(oldBlock, newBlock *types.Block, batch fdb.Batch, txIndexer *accountTxIndexer, internalIndexer *internalActionIndexer) error {
 	var (
 		newChain    types.Blocks
 		oldChain    types.Blocks
@@ -962,10 +995,17 @@ func (bc *BlockChain) reorgChain(oldBlock, newBlock *types.Block, batch fdb.Batc
 		}
 	}
 
+	for _, block := range oldChain {
+		txIndexer.unindex(batch, block)
+		internalIndexer.unindex(batch, block)
+	}
+
 	var addedTxs []*types.Transaction
 	for i := len(newChain) - 1; i >= 0; i-- {
 		bc.insert(batch, newChain[i])
 		rawdb.WriteTxLookupEntries(batch, newChain[i])
+		txIndexer.index(batch, newChain[i])
+		internalIndexer.index(batch, newChain[i])
 		addedTxs = append(addedTxs, newChain[i].Txs...)
 	}
 
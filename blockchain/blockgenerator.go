@@ -37,6 +37,7 @@ type blockGenerator struct {
 	am      *accountmanager.AccountManager
 
 	gasPool  *common.GasPool
+	prepared bool
 	txs      []*types.Transaction
 	receipts []*types.Receipt
 
@@ -75,6 +76,7 @@ func (bg *blockGenerator) AddTxWithChain(tx *types.Transaction) {
 	if bg.gasPool == nil {
 		bg.SetCoinbase(bg.genesisBlock.Coinbase())
 	}
+	bg.prepare()
 
 	bg.stateDB.Prepare(tx.Hash(), common.Hash{}, len(bg.txs))
 
@@ -87,6 +89,18 @@ func (bg *blockGenerator) AddTxWithChain(tx *types.Transaction) {
 	bg.receipts = append(bg.receipts, receipt)
 }
 
+// prepare runs the consensus engine on the header once, ahead of the
+// transactions as in block processing.
+func (bg *blockGenerator) prepare() {
+	if bg.prepared || bg.engine == nil {
+		return
+	}
+	if err := bg.engine.Prepare(bg, bg.header, bg.txs, nil, bg.stateDB); err != nil {
+		panic(fmt.Sprintf("engine prepare error: %v", err))
+	}
+	bg.prepared = true
+}
+
 // CurrentHeader return current header
 func (bg *blockGenerator) CurrentHeader() *types.Header {
 	return bg.parent.Head
@@ -91,6 +91,7 @@ type Downloader struct {
 	maxNumber   uint64
 	knownBlocks mapset.Set
 	subs        []router.Subscription
+	fastSync    int32 // 1 while a fresh node should fast sync
 }
 
 // NewDownloader create a new downloader
@@ -348,9 +349,16 @@ func getBlocks(from router.Station, to router.Station, req []common.Hash, errch
 	return bodies, nil
 }
 
-func (dl *Downloader) findAncestor(from router.Station, to router.Station, headNumber uint64, preAncestor uint64, errCh chan struct{}) (uint64, common.Hash, *Error) {
+// ancestorChain is the local chain searched for the common ancestor with a peer.
+type ancestorChain interface {
+	Genesis() *types.Block
+	HasBlock(hash common.Hash, number uint64) bool
+	IrreversibleNumber() uint64
+}
+
+func findAncestor(chain ancestorChain, from router.Station, to router.Station, headNumber uint64, preAncestor uint64, errCh chan struct{}) (uint64, common.Hash, *Error) {
 	if headNumber < 1 {
-		return 0, dl.blockchain.Genesis().Hash(), nil
+		return 0, chain.Genesis().Hash(), nil
 	}
 	find := func(headNum, length uint64) (uint64, common.Hash, *Error) {
 		hashes, err := getBlockHashes(from, to, &getBlockHashByNumber{headNumber, length, 0, true}, errCh)
@@ -359,7 +367,7 @@ func (dl *Downloader) findAncestor(from router.Station, to router.Station, headN
 		}
 
 		for i, hash := range hashes {
-			if dl.blockchain.HasBlock(hash, headNum-uint64(i)) {
+			if chain.HasBlock(hash, headNum-uint64(i)) {
 				log.Debug("downloader findAncestor", "hash", hash.Hex(), "number", headNum-uint64(i))
 				return headNum - uint64(i), hash, nil
 			}
@@ -367,7 +375,7 @@ func (dl *Downloader) findAncestor(from router.Station, to router.Station, headN
 		return 0, emptyHash, &Error{errors.New("not find"), notFind}
 	}
 
-	irreversibleNumber := dl.blockchain.IrreversibleNumber()
+	irreversibleNumber := chain.IrreversibleNumber()
 	log.Debug("downloader findAncestor", "headNumber", headNumber, "preAncestor", preAncestor, "irreversibleNumber", irreversibleNumber)
 	if preAncestor < irreversibleNumber {
 		preAncestor = irreversibleNumber
@@ -465,7 +473,20 @@ func (dl *Downloader) multiplexDownload() bool {
 	router.StationRegister(stationSearch)
 	defer router.StationUnregister(stationSearch)
 
-	ancestor, ancestorHash, err := dl.findAncestor(stationSearch, status.station, headNumber, status.ancestor, status.errCh)
+	if atomic.LoadInt32(&dl.fastSync) == 1 && head.NumberU64() == 0 && statusNumber > fastSyncMinGap {
+		if err := dl.syncFast(stationSearch, status, statusNumber); err != nil {
+			log.Warn("Fast sync failed", "node", adaptor.GetFnode(status.station), "err", err)
+			router.AddErr(status.station, 1)
+			if err.eid == insertError || err.eid == sizeNotEqual {
+				log.Warn("Disconnect because fast sync error:", "node:", adaptor.GetFnode(status.station))
+				router.SendTo(nil, nil, router.OneMinuteLimited, status.station) // disconnect and put into blacklist
+			}
+			return false
+		}
+		return true
+	}
+
+	ancestor, ancestorHash, err := findAncestor(dl.blockchain, stationSearch, status.station, headNumber, status.ancestor, status.errCh)
 	if err != nil {
 		log.Warn("ancestor err", "err", err, "errID:", err.eid)
 		router.AddErr(status.station, 1)
@@ -490,6 +511,40 @@ func (dl *Downloader) multiplexDownload() bool {
 		downloadAmount = 1024
 	}
 	downloadEnd := downloadStart + downloadAmount
+	numbers, hashes, ok := downloadPoints(stationSearch, status, downloadStart, downloadStartHash, downloadEnd)
+	if !ok {
+		return false
+	}
+
+	n, err := dl.assignDownloadTask(hashes, numbers, dl.blockchain.InsertChain)
+	status.ancestor = n
+	if err != nil {
+		log.Warn("Insert error:", "number:", n, "error", err)
+		failedNum := numbers[len(numbers)-1] - n
+		router.AddErr(status.station, failedNum)
+		if failedNum > 32 {
+			log.Warn("Disconnect because Insert error:", "node:", adaptor.GetFnode(status.station), "failedNum", failedNum)
+			router.SendTo(nil, nil, router.OneMinuteLimited, status.station) // disconnect and put into blacklist
+		}
+	}
+
+	head = dl.blockchain.CurrentBlock()
+	if statusTD.Cmp(dl.blockchain.GetTd(head.Hash(), head.NumberU64())) <= 0 {
+		dl.broadcastStatus(&NewBlockHashesData{
+			Hash:      head.Hash(),
+			Number:    head.NumberU64(),
+			TD:        dl.blockchain.GetTd(head.Hash(), head.NumberU64()),
+			Completed: true,
+		})
+		return false
+	}
+	return true
+}
+
+// downloadPoints gets the hashes of every downloadBulk blocks from start to
+// end, which split the download into tasks.
+func downloadPoints(stationSearch router.Station, status *stationStatus, downloadStart uint64, downloadStartHash common.Hash, downloadEnd uint64) ([]uint64, []common.Hash, bool) {
+	downloadAmount := downloadEnd - downloadStart
 	downloadBulk := uint64(64)
 	numbers := make([]uint64, 0, (downloadAmount+downloadBulk-1)/downloadBulk+1)
 	hashes := make([]common.Hash, 0, (downloadAmount+downloadBulk-1)/downloadBulk+1)
@@ -508,7 +563,7 @@ func (dl *Downloader) multiplexDownload() bool {
 			Reverse: false}, status.errCh)
 		if err != nil || len(hash) != len(numbers[1:]) {
 			log.Debug("getBlockHashes 1 err", "err", err, "len(hash)", len(hash), "len(numbers)", len(numbers[1:]))
-			return false
+			return nil, nil, false
 		}
 		hashes = append(hashes, hash...)
 	}
@@ -522,34 +577,11 @@ func (dl *Downloader) multiplexDownload() bool {
 			Reverse: false}, status.errCh)
 		if err != nil || len(hash) != 1 {
 			log.Debug("getBlockHashes 2 err", "len(hash)", len(hash), "err", err)
-			return false
+			return nil, nil, false
 		}
 		hashes = append(hashes, hash...)
 	}
-
-	n, err := dl.assignDownloadTask(hashes, numbers)
-	status.ancestor = n
-	if err != nil {
-		log.Warn("Insert error:", "number:", n, "error", err)
-		failedNum := numbers[len(numbers)-1] - n
-		router.AddErr(status.station, failedNum)
-		if failedNum > 32 {
-			log.Warn("Disconnect because Insert error:", "node:", adaptor.GetFnode(status.station), "failedNum", failedNum)
-			router.SendTo(nil, nil, router.OneMinuteLimited, status.station) // disconnect and put into blacklist
-		}
-	}
-
-	head = dl.blockchain.CurrentBlock()
-	if statusTD.Cmp(dl.blockchain.GetTd(head.Hash(), head.NumberU64())) <= 0 {
-		dl.broadcastStatus(&NewBlockHashesData{
-			Hash:      head.Hash(),
-			Number:    head.NumberU64(),
-			TD:        dl.blockchain.GetTd(head.Hash(), head.NumberU64()),
-			Completed: true,
-		})
-		return false
-	}
-	return true
+	return numbers, hashes, true
 }
 
 func (dl *Downloader) loopStart() {
@@ -580,7 +612,7 @@ func (dl *Downloader) loop() {
 }
 
 // Return the height of the last successfully inserted block and error
-func (dl *Downloader) assignDownloadTask(hashes []common.Hash, numbers []uint64) (uint64, *Error) {
+func (dl *Downloader) assignDownloadTask(hashes []common.Hash, numbers []uint64, insert func(types.Blocks) (int, error)) (uint64, *Error) {
 	log.Debug("assingDownloadTask:", "hashesLen", len(hashes), "numbersLen", len(numbers), "numbers", numbers)
 	workers := &simpleHeap{cmp: dl.remotes.cmp}
 	dl.remotesMutex.RLock()
@@ -650,7 +682,7 @@ func (dl *Downloader) assignDownloadTask(hashes []common.Hash, numbers []uint64)
 		if blocks == nil {
 			return start, nil
 		}
-		if index, err := dl.blockchain.InsertChain(blocks); err != nil {
+		if index, err := insert(blocks); err != nil {
 			return blocks[index].NumberU64() - 1, &Error{err, other}
 		}
 	}
@@ -25,10 +25,13 @@ import (
 
 type simuAdaptor struct{}
 
+// SendOut delivers the event as received from a peer. It is delivered on a
+// new goroutine as over the network, sending it within SendOut takes the
+// router locks again and deadlocks with a subscription waiting for them.
 func (simuAdaptor) SendOut(e *router.Event) error {
 	e.To = nil
 	//e.From = router.NewLocalStation(e.From.Name(), nil)
-	router.SendEvent(e)
+	go router.SendEvent(e)
 	return nil
 }
 
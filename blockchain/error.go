@@ -31,6 +31,12 @@ var (
 	ErrBlacklistedHash = errors.New("blacklisted hash")
 
 	errGenesisNoConfig = errors.New("genesis has no chain configuration")
+
+	// ErrReorgIrreversible is returned if headers would replace an irreversible header.
+	ErrReorgIrreversible = errors.New("reorganization below the irreversible block")
+
+	errNoLightEngine = errors.New("light chain engine not set")
+	errNoLightPeer   = errors.New("no peer to retrieve from")
 )
 
 // GenesisMismatchError is raised when trying to overwrite an existing
@@ -0,0 +1,424 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+	router "github.com/unichainplatform/unichain/event"
+	"github.com/unichainplatform/unichain/processor"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/snapshot"
+	"github.com/unichainplatform/unichain/state"
+	trie "github.com/unichainplatform/unichain/state/mtp"
+	"github.com/unichainplatform/unichain/types"
+)
+
+const (
+	fastSyncMinGap   = 1024 // blocks a fresh node must be behind its best peer to fast sync
+	fastSyncBulk     = 1024 // blocks written per download round, and headers proving the pivot irreversible
+	maxNodeDataFetch = 384  // state trie nodes requested from a peer at once
+)
+
+// SetFastSync enable/disable fast sync. A fresh node far behind its peers then
+// downloads the state of a recent block instead of executing every block, and
+// continues with full sync from there.
+func (bc *BlockChain) SetFastSync(enable bool) {
+	var flag int32
+	if enable {
+		flag = 1
+	}
+	atomic.StoreInt32(&bc.station.downloader.fastSync, flag)
+}
+
+// fastChain is the chain a fast sync verifies the downloaded blocks against.
+// Their states are not available locally, so the state trie nodes read by the
+// consensus rules are retrieved from the syncing peer, proven by the roots of
+// the verified headers.
+type fastChain struct {
+	*BlockChain
+	stateCache state.Database
+	validator  processor.Validator
+}
+
+func newFastChain(bc *BlockChain, fetch func([]common.Hash) ([][]byte, *Error)) *fastChain {
+	retrieve := func(hash common.Hash) ([]byte, error) {
+		nodes, err := fetch([]common.Hash{hash})
+		if err != nil {
+			return nil, err
+		}
+		if len(nodes) == 0 || crypto.Keccak256Hash(nodes[0]) != hash {
+			return nil, &Error{fmt.Errorf("state node %x not delivered", hash), notFind}
+		}
+		return nodes[0], nil
+	}
+	fc := &fastChain{
+		BlockChain: bc,
+		// the retrieved nodes are kept apart, the state sync takes the
+		// subtrie of a node in the database as complete
+		stateCache: state.NewDatabase(&odrDatabase{Database: bc.db, nodes: rawdb.NewMemoryDatabase(), retrieve: retrieve}),
+	}
+	fc.validator = bc.Validator().WithChain(fc)
+	return fc
+}
+
+// HasBlockAndState checks if a block is present, its state is retrieved on
+// demand.
+func (fc *fastChain) HasBlockAndState(hash common.Hash, number uint64) bool {
+	return fc.HasBlock(hash, number)
+}
+
+// StateAt returns a state whose missing trie nodes are retrieved from the peer.
+func (fc *fastChain) StateAt(root common.Hash) (*state.StateDB, error) {
+	return state.New(root, fc.stateCache)
+}
+
+// CheckForkID checks the validity of forkID
+func (fc *fastChain) CheckForkID(header *types.Header) error {
+	parentHeader := fc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	state, err := fc.StateAt(parentHeader.Root)
+	if err != nil {
+		return err
+	}
+	return fc.fcontroller.checkForkID(header, state)
+}
+
+// insertBlocks verifies the headers, including the producer signatures, and
+// the bodies of blocks above the head block and writes them as canonical
+// without executing them, their state and receipts are not available. The
+// account tx index is kept in step, the internal action index skips the
+// blocks as their internal actions are not known.
+func (fc *fastChain) insertBlocks(chain types.Blocks) (int, error) {
+	if len(chain) == 0 {
+		return 0, nil
+	}
+	if err := fc.sanityCheck(chain); err != nil {
+		return 0, err
+	}
+
+	fc.wg.Add(1)
+	defer fc.wg.Done()
+
+	fc.chainmu.Lock()
+	defer fc.chainmu.Unlock()
+
+	var (
+		txIndexer       *accountTxIndexer
+		internalIndexer *internalActionIndexer
+	)
+	if fc.vmConfig.AccountIndexFlag {
+		txIndexer = newAccountTxIndexer(fc.db)
+	}
+	if fc.vmConfig.InternalIndexFlag {
+		internalIndexer = newInternalActionIndexer(fc.db)
+	}
+	for i, block := range chain {
+		err := fc.validator.ValidateHeader(block.Header(), true)
+		if err == processor.ErrKnownBlock {
+			continue
+		}
+		if err == nil {
+			err = fc.validator.ValidateBody(block)
+		}
+		if err != nil {
+			return i, err
+		}
+		ptd := fc.GetTd(block.ParentHash(), block.NumberU64()-1)
+		if ptd == nil {
+			return i, processor.ErrUnknownAncestor
+		}
+		if err := fc.WriteTd(block.Hash(), block.NumberU64(), new(big.Int).Add(block.Difficulty(), ptd)); err != nil {
+			return i, err
+		}
+		batch := fc.db.NewBatch()
+		rawdb.WriteBlock(batch, block)
+		rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
+		rawdb.WriteTxLookupEntries(batch, block)
+		txIndexer.index(batch, block)
+		txIndexer.commit(batch)
+		internalIndexer.index(batch, block)
+		internalIndexer.commit(batch)
+		if err := batch.Write(); err != nil {
+			return i, err
+		}
+	}
+	log.Info("Imported new fast sync segment", "blocks", len(chain), "number", chain[len(chain)-1].Number(), "hash", chain[len(chain)-1].Hash())
+	return len(chain), nil
+}
+
+// irreversibleNumber returns the number the verified headers down from head
+// prove irreversible, the pivot a fast sync makes its irreversible head block.
+// Like dpos.CalcBFTIrreversible it is the highest number at least two thirds of
+// the producers of these headers proposed as irreversible, blocks of the system
+// are irreversible by themselves.
+func (fc *fastChain) irreversibleNumber(head *types.Header) uint64 {
+	var (
+		irreversible uint64
+		proposed     = make(map[string]uint64)
+	)
+	for header := head; header != nil && header.Number.Uint64() > 0; header = fc.GetHeader(header.ParentHash, header.Number.Uint64()-1) {
+		if head.Number.Uint64()-header.Number.Uint64() >= fastSyncBulk {
+			break
+		}
+		if header.Coinbase.String() == fc.chainConfig.SysName {
+			irreversible = header.Number.Uint64()
+			break
+		}
+		if _, ok := proposed[header.Coinbase.String()]; !ok {
+			proposed[header.Coinbase.String()] = header.ProposedIrreversible
+		}
+	}
+	if len(proposed) == 0 {
+		return irreversible
+	}
+	numbers := make([]uint64, 0, len(proposed))
+	for _, number := range proposed {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	if number := numbers[(len(numbers)-1)/3]; number > irreversible && number <= head.Number.Uint64() {
+		irreversible = number
+	}
+	return irreversible
+}
+
+// commitFastSync makes block, whose state was downloaded, the irreversible head
+// block.
+func (bc *BlockChain) commitFastSync(block *types.Block) error {
+	if _, err := state.New(block.Root(), bc.stateCache); err != nil {
+		return err
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	var (
+		txIndexer       *accountTxIndexer
+		internalIndexer *internalActionIndexer
+	)
+	if bc.vmConfig.AccountIndexFlag {
+		txIndexer = newAccountTxIndexer(bc.db)
+	}
+	if bc.vmConfig.InternalIndexFlag {
+		internalIndexer = newInternalActionIndexer(bc.db)
+	}
+	batch := bc.db.NewBatch()
+	// Blocks above the pivot are downloaded again with their state
+	var above []*types.Block
+	for i := block.NumberU64() + 1; rawdb.ReadCanonicalHash(bc.db, i) != (common.Hash{}); i++ {
+		above = append(above, bc.GetBlockByNumber(i))
+		rawdb.DeleteCanonicalHash(batch, i)
+	}
+	for i := len(above) - 1; i >= 0; i-- {
+		txIndexer.unindex(batch, above[i])
+		internalIndexer.unindex(batch, above[i])
+	}
+	txIndexer.commit(batch)
+	internalIndexer.commit(batch)
+	bc.insert(batch, block)
+	rawdb.WriteHeadHeaderHash(batch, block.Hash())
+	rawdb.WriteIrreversibleNumber(batch, block.NumberU64())
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	bc.currentBlock.Store(block)
+	bc.irreversibleNumber.Store(block.NumberU64())
+	if internalIndexer != nil {
+		log.Warn("Internal actions of fast synced blocks are not indexed", "number", block.Number())
+	}
+	log.Info("Fast sync completed", "number", block.Number(), "hash", block.Hash(), "root", block.Root())
+	return nil
+}
+
+func getNodeData(from router.Station, to router.Station, req []common.Hash, errch chan struct{}) ([][]byte, *Error) {
+	se := &router.Event{
+		From:     from,
+		To:       to,
+		Typecode: router.P2PGetNodeDataMsg,
+		Data:     req,
+	}
+	timeout := time.Second + time.Duration(len(req))*(10*time.Millisecond)
+	e, err := syncReq(se, router.P2PNodeDataMsg, [][]byte{}, timeout, errch)
+	if err != nil {
+		return nil, err
+	}
+	return e.Data.([][]byte), nil
+}
+
+// syncFast writes the verified blocks up to head from the peer of status
+// without executing them, downloads the state trie of the block they prove
+// irreversible and makes it the head block.
+func (dl *Downloader) syncFast(stationSearch router.Station, status *stationStatus, head uint64) *Error {
+	log.Info("Fast sync started", "head", head, "node", status.station.Name())
+	fetch := func(req []common.Hash) ([][]byte, *Error) {
+		return getNodeData(stationSearch, status.station, req, status.errCh)
+	}
+	fc := newFastChain(dl.blockchain, fetch)
+
+	start, startHash := uint64(0), dl.blockchain.Genesis().Hash()
+	for start < head {
+		end := start + fastSyncBulk
+		if end > head {
+			end = head
+		}
+		numbers, hashes, ok := downloadPoints(stationSearch, status, start, startHash, end)
+		if !ok {
+			return &Error{fmt.Errorf("get block hashes from %d to %d failed", start, end), other}
+		}
+		n, err := dl.assignDownloadTask(hashes, numbers, fc.insertBlocks)
+		if err != nil {
+			return err
+		}
+		if n != end {
+			return &Error{fmt.Errorf("download blocks stopped at %d", n), other}
+		}
+		start, startHash = end, hashes[len(hashes)-1]
+	}
+
+	pivot := fc.irreversibleNumber(fc.GetHeader(startHash, head))
+	if pivot == 0 {
+		return &Error{fmt.Errorf("no irreversible block below %d", head), other}
+	}
+	block := fc.GetBlockByNumber(pivot)
+	if block == nil {
+		return &Error{fmt.Errorf("missing pivot block %d", pivot), other}
+	}
+	log.Info("Fast sync pivot", "number", pivot, "hash", block.Hash())
+	if err := dl.syncState(block.Root(), fetch); err != nil {
+		return err
+	}
+	if err := dl.syncSnapshots(block, fetch); err != nil {
+		return err
+	}
+	if err := dl.blockchain.commitFastSync(block); err != nil {
+		return &Error{err, other}
+	}
+	atomic.StoreInt32(&dl.fastSync, 0)
+	return nil
+}
+
+// syncSnapshots downloads the states of the snapshots recorded in the state of
+// block, back to the snapshot of the start of the epoch before the one of
+// block, and writes their snapshot records. DPoS reads the stakes of an epoch
+// from these states, full sync writes the records when executing the blocks.
+// The root of a snapshot is the root of the header of its block, so it is
+// proven by the verified headers.
+func (dl *Downloader) syncSnapshots(block *types.Block, fetch func([]common.Hash) ([][]byte, *Error)) *Error {
+	bc := dl.blockchain
+	statedb, err := state.New(block.Root(), bc.stateCache)
+	if err != nil {
+		return &Error{err, other}
+	}
+	snapshotManager := snapshot.NewSnapshotManager(statedb)
+	timestamp, err := snapshotManager.GetLastSnapshotTime()
+	if err != nil {
+		return &Error{err, other}
+	}
+	earliest := bc.chainConfig.ReferenceTime
+	epochInterval := bc.chainConfig.DposCfg.EpochInterval * uint64(time.Millisecond)
+	if epochs := (block.Time().Uint64() - earliest) / epochInterval; epochs > 0 {
+		earliest += (epochs - 1) * epochInterval
+	}
+	for {
+		info, err := snapshotManager.GetSnapshotBlock(timestamp)
+		if err != nil {
+			return &Error{err, other}
+		}
+		header := bc.GetHeaderByNumber(info.Number)
+		if header == nil || header.ParentHash != info.BlockHash {
+			return &Error{fmt.Errorf("snapshot block %d not canonical", info.Number), other}
+		}
+		if err := dl.syncState(header.Root, fetch); err != nil {
+			return err
+		}
+		rawdb.WriteSnapshot(bc.db, types.SnapshotBlock{Number: info.Number, BlockHash: info.BlockHash}, types.SnapshotInfo{Root: header.Root})
+		log.Debug("Fast sync snapshot", "time", timestamp, "number", info.Number, "root", header.Root)
+		if timestamp <= earliest || info.Timestamp == 0 {
+			return nil
+		}
+		timestamp = info.Timestamp
+	}
+}
+
+// syncState downloads the state trie of root with fetch. Every node is checked
+// against the hash it was requested by, so the state matches the root of the
+// header.
+func (dl *Downloader) syncState(root common.Hash, fetch func([]common.Hash) ([][]byte, *Error)) *Error {
+	sched := trie.NewSync(root, dl.blockchain.db, nil)
+	queue := sched.Missing(0)
+	for len(queue) > 0 {
+		select {
+		case <-dl.quit:
+			return &Error{errors.New("downloader stopped"), ioClose}
+		default:
+		}
+
+		amount := len(queue)
+		if amount > maxNodeDataFetch {
+			amount = maxNodeDataFetch
+		}
+		req := queue[:amount]
+		nodes, err := fetch(req)
+		if err != nil {
+			return err
+		}
+
+		requested := make(map[common.Hash]bool, len(req))
+		for _, hash := range req {
+			requested[hash] = true
+		}
+		results := make([]trie.SyncResult, 0, len(nodes))
+		for _, node := range nodes {
+			hash := crypto.Keccak256Hash(node)
+			if !requested[hash] {
+				return &Error{fmt.Errorf("unrequested state node %x", hash), insertError}
+			}
+			delete(requested, hash)
+			results = append(results, trie.SyncResult{Hash: hash, Data: node})
+		}
+		if len(results) == 0 {
+			return &Error{errors.New("no state node delivered"), notFind}
+		}
+		if _, index, err := sched.Process(results); err != nil {
+			return &Error{fmt.Errorf("state node %x: %v", results[index].Hash, err), insertError}
+		}
+		batch := dl.blockchain.db.NewBatch()
+		if _, err := sched.Commit(batch); err != nil {
+			return &Error{err, other}
+		}
+		if err := batch.Write(); err != nil {
+			return &Error{err, other}
+		}
+
+		// undelivered nodes are requested again
+		queue = queue[amount:]
+		for hash := range requested {
+			queue = append(queue, hash)
+		}
+		queue = append(queue, sched.Missing(0)...)
+		log.Debug("Fast sync state", "processed", len(results), "pending", sched.Pending())
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/processor/vm"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+func stateFetcher(chain *BlockChain) func([]common.Hash) ([][]byte, *Error) {
+	return func(req []common.Hash) ([][]byte, *Error) {
+		nodes := make([][]byte, 0, len(req))
+		for _, hash := range req {
+			node, err := chain.stateCache.TrieDB().Node(hash)
+			if err != nil {
+				return nil, &Error{err, notFind}
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes, nil
+	}
+}
+
+func TestFastSync(t *testing.T) {
+	genesis := DefaultGenesis()
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	_, blocks := makeNewChain(t, genesis, chain, 10, canonicalSeed)
+
+	fastChain := newCanonical(t, genesis)
+	defer fastChain.Stop()
+
+	fetched := 0
+	fetch := func(req []common.Hash) ([][]byte, *Error) {
+		nodes := make([][]byte, 0, len(req))
+		for _, hash := range req {
+			node, err := chain.stateCache.TrieDB().Node(hash)
+			if err != nil {
+				return nil, &Error{err, notFind}
+			}
+			nodes = append(nodes, node)
+		}
+		fetched += len(nodes)
+		return nodes, nil
+	}
+	fc := newFastChain(fastChain, fetch)
+
+	forged := types.NewBlockWithHeader(blocks[0].Header()).WithBody(blocks[1].Txs)
+	if _, err := fc.insertBlocks(types.Blocks{forged}); err == nil {
+		t.Fatal("insert of a block with a forged body succeeded")
+	}
+	if _, err := fc.insertBlocks(blocks); err != nil {
+		t.Fatal(err)
+	}
+	pivot := blocks[len(blocks)-1]
+	if fastChain.GetBlockByNumber(pivot.NumberU64()) == nil {
+		t.Fatalf("pivot block %d not written", pivot.NumberU64())
+	}
+	if number := fc.irreversibleNumber(pivot.Header()); number != pivot.NumberU64() {
+		t.Fatalf("irreversible number mismatch: have %d, want %d", number, pivot.NumberU64())
+	}
+	if err := fastChain.commitFastSync(pivot); err == nil {
+		t.Fatal("commit without the pivot state succeeded")
+	}
+
+	fetched = 0
+	if err := fastChain.station.downloader.syncState(pivot.Root(), fetch); err != nil {
+		t.Fatal(err.error)
+	}
+	if fetched == 0 {
+		t.Fatal("no state node fetched")
+	}
+	if err := fastChain.commitFastSync(pivot); err != nil {
+		t.Fatal(err)
+	}
+
+	if fastChain.CurrentBlock().Hash() != pivot.Hash() {
+		t.Fatalf("head mismatch: have %x, want %x", fastChain.CurrentBlock().Hash(), pivot.Hash())
+	}
+	if fastChain.IrreversibleNumber() != pivot.NumberU64() {
+		t.Fatalf("irreversible number mismatch: have %d, want %d", fastChain.IrreversibleNumber(), pivot.NumberU64())
+	}
+	if _, err := state.New(pivot.Root(), fastChain.stateCache); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFastSyncSnapshots(t *testing.T) {
+	// epochs of 60 blocks with a snapshot every 6 blocks
+	genesis := DefaultGenesis()
+	genesis.Config = params.DefaultChainconfig.Copy()
+	genesis.Config.SnapshotInterval = 18000
+	genesis.Config.DposCfg.BlockFrequency = 1
+	genesis.Config.DposCfg.EpochInterval = 180000
+	vmConfig := vm.Config{AccountIndexFlag: true}
+	chain := newCanonicalWithConfig(t, genesis, vmConfig)
+	defer chain.Stop()
+
+	// the vote of block 140 reads the stake from the snapshot of block 120, the
+	// first of epoch 3, the fast synced nodes take block 130 as pivot
+	name := common.StrToName(genesis.Config.SysName)
+	tmpDB, err := deepCopyDB(chain.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := dpos.New(dposConfig(genesis.Config), chain)
+	engine.SetSignFn(func(content []byte, header *types.Header, state *state.StateDB) ([]byte, error) {
+		return crypto.Sign(content, systemPrivateKey)
+	})
+	blocks, _ := generateChain(genesis.Config, chain.CurrentBlock(), engine, chain, tmpDB, 150, canonicalSeed, func(i int, b *blockGenerator) {
+		b.SetCoinbase(name)
+		if i != 139 {
+			return
+		}
+		stake := new(big.Int).Mul(genesis.Config.DposCfg.UnitStake, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(genesis.Config.SysTokenDecimals)), nil))
+		payload, err := rlp.EncodeToBytes(&dpos.VoteCandidate{Candidate: name.String(), Stake: stake.Mul(stake, genesis.Config.DposCfg.VoterMinQuantity)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := types.NewTransaction(0, big.NewInt(1), types.NewAction(types.VoteCandidate, name, common.StrToName(genesis.Config.DposName), b.TxNonce(name), 0, 109000, big.NewInt(0), payload, nil))
+		keyPair := types.MakeKeyPair(systemPrivateKey, []uint64{0})
+		if err := types.SignActionWithMultiKey(tx.GetActions()[0], tx, types.NewSigner(genesis.Config.ChainID), 0, []*types.KeyPair{keyPair}); err != nil {
+			t.Fatal(err)
+		}
+		b.AddTxWithChain(tx)
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	vote := blocks[139]
+	if result := chain.GetReceiptsByHash(vote.Hash())[0].ActionResults[0]; result.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("vote failed: %v", result.Error)
+	}
+
+	fastSync := func(snapshots bool) (*BlockChain, error) {
+		fastChain := newCanonicalWithConfig(t, genesis, vmConfig)
+		fetch := stateFetcher(chain)
+		fc := newFastChain(fastChain, fetch)
+		// blocks above the pivot are written and dropped again
+		if _, err := fc.insertBlocks(blocks[:140]); err != nil {
+			t.Fatal(err)
+		}
+		pivot := blocks[129]
+		if err := fastChain.station.downloader.syncState(pivot.Root(), fetch); err != nil {
+			t.Fatal(err.error)
+		}
+		if snapshots {
+			if err := fastChain.station.downloader.syncSnapshots(pivot, fetch); err != nil {
+				t.Fatal(err.error)
+			}
+		}
+		if err := fastChain.commitFastSync(pivot); err != nil {
+			t.Fatal(err)
+		}
+		_, err := fastChain.InsertChain(blocks[130:])
+		return fastChain, err
+	}
+
+	withoutSnapshots, err := fastSync(false)
+	withoutSnapshots.Stop()
+	if err == nil {
+		t.Fatal("vote imported without the snapshot states")
+	}
+
+	fastChain, err := fastSync(true)
+	defer fastChain.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fastChain.CurrentBlock().Hash() != blocks[149].Hash() {
+		t.Fatalf("head mismatch: have %d, want %d", fastChain.CurrentBlock().NumberU64(), blocks[149].NumberU64())
+	}
+	if head, ok := rawdb.ReadAccountTxIndexHead(fastChain.db); !ok || head != 150 {
+		t.Fatalf("account tx index head mismatch: have %d %v, want 150", head, ok)
+	}
+	for _, name := range []common.Name{name, common.StrToName(genesis.Config.DposName)} {
+		if have, want := rawdb.ReadAccountTxCount(fastChain.db, name), rawdb.ReadAccountTxCount(chain.db, name); have != want {
+			t.Fatalf("account txs of %v mismatch: have %d, want %d", name, have, want)
+		}
+	}
+}
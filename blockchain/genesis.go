@@ -98,14 +98,17 @@ func dposConfig(cfg *params.ChainConfig) *dpos.Config {
 		SystemURL:                     cfg.ChainURL,
 		ExtraBlockReward:              cfg.DposCfg.ExtraBlockReward,
 		BlockReward:                   cfg.DposCfg.BlockReward,
+		SlashRate:                     cfg.DposCfg.SlashRate,
 		Decimals:                      cfg.SysTokenDecimals,
 		AssetID:                       cfg.SysTokenID,
 		ReferenceTime:                 cfg.ReferenceTime,
 	}
 }
 
-// SetupGenesisBlock The returned chain configuration is never nil.
-func SetupGenesisBlock(db fdb.Database, genesis *Genesis) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
+// SetupGenesisBlock The returned chain configuration is never nil. The detail
+// txs of a new genesis block are stored if contractLog is set, like those of
+// inserted blocks.
+func SetupGenesisBlock(db fdb.Database, genesis *Genesis, contractLog bool) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
 	if genesis != nil && genesis.Config == nil {
 		return params.DefaultChainconfig, dposConfig(params.DefaultChainconfig), common.Hash{}, errGenesisNoConfig
 	}
@@ -116,7 +119,7 @@ func SetupGenesisBlock(db fdb.Database, genesis *Genesis) (*params.ChainConfig,
 		if genesis == nil {
 			genesis = DefaultGenesis()
 		}
-		block, err := genesis.Commit(db)
+		block, err := genesis.Commit(db, contractLog)
 		if err != nil {
 			return nil, nil, common.Hash{}, err
 		}
@@ -528,8 +531,9 @@ func (g *Genesis) ToBlock(db fdb.Database) (*types.Block, []*types.Receipt, erro
 }
 
 // Commit writes the block and state of a genesis specification to the database.
-// The block is committed as the canonical head block.
-func (g *Genesis) Commit(db fdb.Database) (*types.Block, error) {
+// The block is committed as the canonical head block, its detail txs are only
+// written if contractLog is set.
+func (g *Genesis) Commit(db fdb.Database, contractLog bool) (*types.Block, error) {
 	block, receipts, err := g.ToBlock(db)
 	if err != nil {
 		return nil, err
@@ -541,6 +545,9 @@ func (g *Genesis) Commit(db fdb.Database) (*types.Block, error) {
 	rawdb.WriteBlock(db, block)
 	rawdb.WriteTxLookupEntries(db, block)
 	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts)
+	if contractLog {
+		rawdb.WriteDetailTxs(db, block.Hash(), block.NumberU64(), []*types.DetailTx{receipts[0].GetInternalTxsLog()})
+	}
 	rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
 	rawdb.WriteHeadBlockHash(db, block.Hash())
 	rawdb.WriteHeadHeaderHash(db, block.Hash())
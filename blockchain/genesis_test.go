@@ -31,7 +31,7 @@ import (
 	"github.com/unichainplatform/unichain/utils/fdb"
 )
 
-var defaultgenesisBlockHash = common.HexToHash("0xfff77195a34bae2cbe56990436ef0ae4f41f1a466a1a7943f7040ecdd19eceba")
+var defaultgenesisBlockHash = common.HexToHash("0x4f134b723c196e4391257248824f7e0a594d0010a18a09f4651eabcbb384cd4d")
 
 func TestDefaultGenesisBlock(t *testing.T) {
 	block, _, err := DefaultGenesis().ToBlock(nil)
@@ -45,7 +45,7 @@ func TestDefaultGenesisBlock(t *testing.T) {
 
 func TestSetupGenesis(t *testing.T) {
 	var (
-		customghash = common.HexToHash("0x64f60318de8612ad12a0d5332563597e9ffdbc1ebb302392982e75b2e43327f8")
+		customghash = common.HexToHash("0x5ac7f4814789b032f31d813a9bd1439a4b73c23e8f1d74acdc1a197df0cfd458")
 
 		customg = Genesis{
 			Config:          params.DefaultChainconfig.Copy(),
@@ -55,7 +55,7 @@ func TestSetupGenesis(t *testing.T) {
 		}
 		oldcustomg = customg
 
-		oldcustomghash = common.HexToHash("764340cd44e6401dec7aee1c43aa6759e083bbb60e2f0efa9aa4bbe808a2bb79")
+		oldcustomghash = common.HexToHash("5541c455efc7d2755dabdd8e82f5f9cbd38a87ebcbd26fa69832b47b13f1c256")
 	)
 	customg.Config.ChainID = big.NewInt(5)
 	oldcustomg.Config = customg.Config.Copy()
@@ -72,7 +72,7 @@ func TestSetupGenesis(t *testing.T) {
 		{
 			name: "genesis without ChainConfig",
 			fn: func(db fdb.Database) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
-				return SetupGenesisBlock(db, new(Genesis))
+				return SetupGenesisBlock(db, new(Genesis), false)
 			},
 			wantErr:    errGenesisNoConfig,
 			wantConfig: params.DefaultChainconfig,
@@ -80,7 +80,7 @@ func TestSetupGenesis(t *testing.T) {
 		{
 			name: "no block in DB, genesis == nil",
 			fn: func(db fdb.Database) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
-				return SetupGenesisBlock(db, nil)
+				return SetupGenesisBlock(db, nil, false)
 			},
 			wantHash:   defaultgenesisBlockHash,
 			wantConfig: params.DefaultChainconfig,
@@ -88,10 +88,10 @@ func TestSetupGenesis(t *testing.T) {
 		{
 			name: "mainnet block in DB, genesis == nil",
 			fn: func(db fdb.Database) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
-				if _, err := DefaultGenesis().Commit(db); err != nil {
+				if _, err := DefaultGenesis().Commit(db, false); err != nil {
 					return nil, nil, common.Hash{}, err
 				}
-				return SetupGenesisBlock(db, nil)
+				return SetupGenesisBlock(db, nil, false)
 			},
 			wantHash:   defaultgenesisBlockHash,
 			wantConfig: params.DefaultChainconfig,
@@ -99,10 +99,10 @@ func TestSetupGenesis(t *testing.T) {
 		{
 			name: "compatible config in DB",
 			fn: func(db fdb.Database) (*params.ChainConfig, *dpos.Config, common.Hash, error) {
-				if _, err := oldcustomg.Commit(db); err != nil {
+				if _, err := oldcustomg.Commit(db, false); err != nil {
 					return nil, nil, common.Hash{}, err
 				}
-				return SetupGenesisBlock(db, &customg)
+				return SetupGenesisBlock(db, &customg, false)
 			},
 			wantErr: &GenesisMismatchError{
 				oldcustomghash,
@@ -25,6 +25,7 @@ import (
 	"github.com/unichainplatform/unichain/common"
 	router "github.com/unichainplatform/unichain/event"
 	adaptor "github.com/unichainplatform/unichain/p2p/protoadaptor"
+	"github.com/unichainplatform/unichain/rawdb"
 	"github.com/unichainplatform/unichain/types"
 )
 
@@ -49,7 +50,7 @@ func newStation(bc *BlockChain, networkID uint64) *station {
 		networkID:  networkID,
 		quit:       make(chan struct{}),
 		downloader: NewDownloader(bc),
-		subs:       make([]router.Subscription, 6),
+		subs:       make([]router.Subscription, 8),
 	}
 	bs.subs[0] = router.Subscribe(nil, bs.peerCh, router.NewPeerNotify, nil)
 	bs.subs[1] = router.Subscribe(nil, bs.peerCh, router.DelPeerNotify, nil)
@@ -57,6 +58,8 @@ func newStation(bc *BlockChain, networkID uint64) *station {
 	bs.subs[3] = router.Subscribe(nil, bs.peerCh, router.P2PGetBlockHashMsg, &getBlockHashByNumber{})
 	bs.subs[4] = router.Subscribe(nil, bs.peerCh, router.P2PGetBlockHeadersMsg, &getBlockHeadersData{})
 	bs.subs[5] = router.Subscribe(nil, bs.peerCh, router.P2PGetBlockBodiesMsg, []common.Hash{})
+	bs.subs[6] = router.Subscribe(nil, bs.peerCh, router.P2PGetNodeDataMsg, []common.Hash{})
+	bs.subs[7] = router.Subscribe(nil, bs.peerCh, router.P2PGetReceiptsMsg, []common.Hash{})
 
 	bs.loopWG.Add(1)
 	go func() {
@@ -250,6 +253,41 @@ func (bs *station) handleMsg(e *router.Event) error {
 		}
 		router.ReplyEvent(e, router.P2PBlockBodiesMsg, bodies)
 		return nil
+	case router.P2PGetNodeDataMsg:
+		hashes := e.Data.([]common.Hash)
+		// Gather state trie nodes, skipping unknown ones, the requester checks them by hash
+		triedb := bs.blockchain.stateCache.TrieDB()
+		nodes := make([][]byte, 0, len(hashes))
+		for _, hash := range hashes {
+			if len(nodes) >= maxNodeDataFetch {
+				break
+			}
+			if node, err := triedb.Node(hash); err == nil && len(node) > 0 {
+				nodes = append(nodes, node)
+			}
+		}
+		router.ReplyEvent(e, router.P2PNodeDataMsg, nodes)
+		return nil
+	case router.P2PGetReceiptsMsg:
+		hashes := e.Data.([]common.Hash)
+		// Gather receipts until the fetch limit or an unknown block is reached
+		receipts := make([][]*types.Receipt, 0, len(hashes))
+		for _, hash := range hashes {
+			if len(receipts) >= maxReceiptsFetch {
+				break
+			}
+			number := rawdb.ReadHeaderNumber(bs.blockchain.db, hash)
+			if number == nil {
+				break
+			}
+			results := rawdb.ReadReceipts(bs.blockchain.db, hash, *number)
+			if results == nil {
+				break
+			}
+			receipts = append(receipts, results)
+		}
+		router.ReplyEvent(e, router.P2PReceiptsMsg, receipts)
+		return nil
 	}
 	return nil
 }
@@ -0,0 +1,234 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/fdb"
+)
+
+// internalActionKey identifies the internal actions of an account in one asset.
+type internalActionKey struct {
+	name    common.Name
+	assetID uint64
+}
+
+// internalActionIndexer keeps the internal action index in step with the
+// canonical chain. The internal actions of a block are read from the stored
+// detail txs, or from known for blocks not yet written to the database.
+type internalActionIndexer struct {
+	db     rawdb.DatabaseReader
+	head   uint64 // number of the last indexed block
+	valid  bool   // whether the index covers every block up to head
+	fresh  bool   // whether the index is rebuilt from scratch, ignoring stored counters
+	known  map[common.Hash][]*types.DetailTx
+	counts map[internalActionKey]uint64
+	dirty  map[internalActionKey]struct{}
+}
+
+func newInternalActionIndexer(db rawdb.DatabaseReader) *internalActionIndexer {
+	head, valid := rawdb.ReadInternalActionIndexHead(db)
+	return &internalActionIndexer{
+		db:     db,
+		head:   head,
+		valid:  valid,
+		known:  make(map[common.Hash][]*types.DetailTx),
+		counts: make(map[internalActionKey]uint64),
+		dirty:  make(map[internalActionKey]struct{}),
+	}
+}
+
+// internalActionKeys returns the keys an internal action is indexed under.
+func internalActionKeys(action *types.InternalAction) []internalActionKey {
+	if action.Action == nil {
+		return nil
+	}
+	from := internalActionKey{action.Action.From, action.Action.AssetID}
+	if action.Action.From == action.Action.To {
+		return []internalActionKey{from}
+	}
+	return []internalActionKey{from, {action.Action.To, action.Action.AssetID}}
+}
+
+func (idx *internalActionIndexer) count(key internalActionKey) uint64 {
+	if count, ok := idx.counts[key]; ok {
+		return count
+	}
+	if idx.fresh {
+		return 0
+	}
+	return rawdb.ReadInternalActionCount(idx.db, key.name, key.assetID)
+}
+
+func (idx *internalActionIndexer) setCount(key internalActionKey, count uint64) {
+	idx.counts[key] = count
+	idx.dirty[key] = struct{}{}
+}
+
+func (idx *internalActionIndexer) detailTxs(block *types.Block) []*types.DetailTx {
+	if dtxs, ok := idx.known[block.Hash()]; ok {
+		return dtxs
+	}
+	return rawdb.ReadDetailTxs(idx.db, block.Hash(), block.NumberU64())
+}
+
+// index appends the internal actions of the block to the index if the block
+// directly follows the indexed head, otherwise the index is left untouched.
+func (idx *internalActionIndexer) index(batch fdb.Batch, block *types.Block) {
+	if idx == nil || !idx.valid || idx.head+1 != block.NumberU64() {
+		return
+	}
+	idx.apply(batch, block)
+}
+
+// unindex removes the internal actions of the block from the index if the
+// block is the indexed head, otherwise the index is left untouched.
+func (idx *internalActionIndexer) unindex(batch fdb.Batch, block *types.Block) {
+	if idx == nil || !idx.valid || idx.head != block.NumberU64() || block.NumberU64() == 0 {
+		return
+	}
+	dtxs := idx.detailTxs(block)
+	for i := len(dtxs) - 1; i >= 0; i-- {
+		if dtxs[i] == nil {
+			continue
+		}
+		actions := dtxs[i].Actions
+		for j := len(actions) - 1; j >= 0; j-- {
+			internals := actions[j].InternalActions
+			for k := len(internals) - 1; k >= 0; k-- {
+				keys := internalActionKeys(internals[k])
+				for l := len(keys) - 1; l >= 0; l-- {
+					count := idx.count(keys[l]) - 1
+					rawdb.DeleteInternalActionIndexEntry(batch, keys[l].name, keys[l].assetID, count)
+					idx.setCount(keys[l], count)
+				}
+			}
+		}
+	}
+	idx.head = block.NumberU64() - 1
+}
+
+func (idx *internalActionIndexer) apply(batch fdb.Batch, block *types.Block) {
+	for i, dtx := range idx.detailTxs(block) {
+		if dtx == nil {
+			continue
+		}
+		for j, action := range dtx.Actions {
+			for k, internal := range action.InternalActions {
+				entry := &rawdb.InternalActionIndexEntry{
+					TxHash:        dtx.TxHash,
+					BlockHash:     block.Hash(),
+					BlockNumber:   block.NumberU64(),
+					TxIndex:       uint64(i),
+					ActionIndex:   uint64(j),
+					InternalIndex: uint64(k),
+				}
+				for _, key := range internalActionKeys(internal) {
+					count := idx.count(key)
+					rawdb.WriteInternalActionIndexEntry(batch, key.name, key.assetID, count, entry)
+					idx.setCount(key, count+1)
+				}
+			}
+		}
+	}
+	idx.head = block.NumberU64()
+}
+
+// commit writes the modified counters and the index head into the batch.
+func (idx *internalActionIndexer) commit(batch fdb.Batch) {
+	if idx == nil || !idx.valid {
+		return
+	}
+	for key := range idx.dirty {
+		rawdb.WriteInternalActionCount(batch, key.name, key.assetID, idx.counts[key])
+	}
+	idx.dirty = make(map[internalActionKey]struct{})
+	if !idx.fresh {
+		rawdb.WriteInternalActionIndexHead(batch, idx.head)
+	}
+}
+
+// checkInternalActionIndex makes sure the internal action index is usable, it
+// is built right away for a new chain and reported if it lags behind.
+func (bc *BlockChain) checkInternalActionIndex() error {
+	current := bc.CurrentBlock().NumberU64()
+	head, ok := rawdb.ReadInternalActionIndexHead(bc.db)
+	if !ok && current == 0 {
+		return bc.ReindexInternalActions()
+	}
+	if !ok || head != current {
+		log.Warn("Internal action index is not in step with the chain, run 'uni chain reindex' to rebuild it", "indexed", head, "current", current)
+	}
+	return nil
+}
+
+// ReindexInternalActions rebuilds the internal action index from the genesis
+// block up to the current head block. Only blocks whose detail txs have been
+// stored contribute to the index.
+func (bc *BlockChain) ReindexInternalActions() error {
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	rawdb.DeleteInternalActionIndexHead(bc.db)
+
+	var (
+		idx             = newInternalActionIndexer(bc.db)
+		batch           = bc.db.NewBatch()
+		last            = bc.CurrentBlock().NumberU64()
+		missing         uint64
+		start, reported = time.Now(), time.Now()
+	)
+	idx.valid, idx.fresh = true, true
+
+	for nr := uint64(0); nr <= last; nr++ {
+		block := bc.GetBlockByNumber(nr)
+		if block == nil {
+			return fmt.Errorf("reindex failed on #%d: not found", nr)
+		}
+		if len(block.Transactions()) > 0 && len(idx.detailTxs(block)) == 0 {
+			missing++
+		}
+		idx.apply(batch, block)
+		if batch.ValueSize() >= fdb.IdealBatchSize {
+			idx.commit(batch)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(reported) >= statsReportLimit {
+			log.Info("Reindexing internal actions", "number", nr, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	idx.commit(batch)
+	rawdb.WriteInternalActionIndexHead(batch, last)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if missing > 0 {
+		log.Warn("Blocks without stored internal actions were skipped, they are only stored with --contractlog", "blocks", missing)
+	}
+	log.Info("Reindexed internal actions", "blocks", last+1, "keys", len(idx.counts), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/unichainplatform/unichain/processor/vm"
+	"github.com/unichainplatform/unichain/rawdb"
+)
+
+func checkInternalActionIndex(t *testing.T, chain *BlockChain) {
+	head, ok := rawdb.ReadInternalActionIndexHead(chain.db)
+	if !ok || head != chain.CurrentBlock().NumberU64() {
+		t.Fatalf("index head mismatch: have %v/%v, want %v", head, ok, chain.CurrentBlock().NumberU64())
+	}
+
+	seqs := make(map[internalActionKey]uint64)
+	for nr := uint64(0); nr <= head; nr++ {
+		block := chain.GetBlockByNumber(nr)
+		dtxs := rawdb.ReadDetailTxs(chain.db, block.Hash(), nr)
+		if len(dtxs) == 0 {
+			t.Fatalf("block %v detail txs not found", nr)
+		}
+		for i, dtx := range dtxs {
+			for j, daction := range dtx.Actions {
+				for k, internal := range daction.InternalActions {
+					for _, key := range internalActionKeys(internal) {
+						seq := seqs[key]
+						entry := rawdb.ReadInternalActionIndexEntry(chain.db, key.name, key.assetID, seq)
+						if entry == nil {
+							t.Fatalf("%v/%v entry %v not found", key.name, key.assetID, seq)
+						}
+						if entry.TxHash != dtx.TxHash || entry.BlockHash != block.Hash() || entry.BlockNumber != nr ||
+							entry.TxIndex != uint64(i) || entry.ActionIndex != uint64(j) || entry.InternalIndex != uint64(k) {
+							t.Fatalf("%v/%v entry %v mismatch: have %x/%d/%d/%d/%d, want %x/%d/%d/%d/%d", key.name, key.assetID, seq,
+								entry.TxHash, entry.BlockNumber, entry.TxIndex, entry.ActionIndex, entry.InternalIndex, dtx.TxHash, nr, i, j, k)
+						}
+						seqs[key] = seq + 1
+					}
+				}
+			}
+		}
+	}
+	if len(seqs) == 0 {
+		t.Fatalf("no internal actions indexed")
+	}
+	for key, seq := range seqs {
+		if count := rawdb.ReadInternalActionCount(chain.db, key.name, key.assetID); count != seq {
+			t.Fatalf("%v/%v count mismatch: have %v, want %v", key.name, key.assetID, count, seq)
+		}
+	}
+}
+
+func TestInternalActionIndexReorg(t *testing.T) {
+	genesis := DefaultGenesis()
+	plain := newCanonical(t, genesis)
+	defer plain.Stop()
+	if dtxs := rawdb.ReadDetailTxs(plain.db, plain.Genesis().Hash(), 0); len(dtxs) != 0 {
+		t.Fatal("genesis detail txs written without contract log")
+	}
+
+	chain := newCanonicalWithConfig(t, genesis, vm.Config{ContractLogFlag: true, InternalIndexFlag: true})
+	defer chain.Stop()
+
+	chain, _ = makeNewChain(t, genesis, chain, 10, canonicalSeed)
+	checkInternalActionIndex(t, chain)
+
+	// generate fork blocks
+	forkChain := newCanonical(t, genesis)
+	defer forkChain.Stop()
+
+	_, forkBlocks := makeNewChain(t, genesis, forkChain, 11, forkSeed)
+	if _, err := chain.InsertChain(forkBlocks); err != nil {
+		t.Fatal(err)
+	}
+	checkBlocksInsert(t, chain, forkBlocks)
+	checkInternalActionIndex(t, chain)
+
+	if err := chain.ReindexInternalActions(); err != nil {
+		t.Fatal(err)
+	}
+	checkInternalActionIndex(t, chain)
+}
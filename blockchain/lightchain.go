@@ -0,0 +1,445 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	"github.com/unichainplatform/unichain/crypto"
+	router "github.com/unichainplatform/unichain/event"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/processor"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/fdb"
+)
+
+const (
+	maxHeaderFetch   = 192 // headers requested from a peer at once
+	maxReceiptsFetch = 128 // block receipts requested from a peer at once
+)
+
+// LightChain is a header only chain. It verifies the DPoS headers and their
+// irreversibility, and retrieves state, bodies and receipts from full peers on
+// demand, checking them against the verified headers.
+type LightChain struct {
+	chainConfig *params.ChainConfig
+
+	genesisBlock       *types.Block
+	db                 fdb.Database // Database of headers and data retrieved before
+	chainmu            sync.RWMutex // header insertion lock
+	currentHeader      atomic.Value // Current head of the header chain
+	irreversibleNumber atomic.Value // irreversible Number of the header chain
+
+	stateCache  state.Database // State database retrieving missing trie nodes from peers
+	fcontroller *forkController
+	engine      *dpos.Dpos
+	validator   processor.Validator
+	station     *lightStation
+
+	headerCache *lru.Cache // Cache for the most recent block headers
+	tdCache     *lru.Cache // Cache for the most recent block total difficulties
+}
+
+// NewLightChain returns a header chain using the genesis and headers in db.
+func NewLightChain(db fdb.Database, chainConfig *params.ChainConfig) (*LightChain, error) {
+	headerCache, _ := lru.New(headerCacheLimit)
+	tdCache, _ := lru.New(tdCacheLimit)
+
+	lc := &LightChain{
+		chainConfig: chainConfig,
+		db:          db,
+		headerCache: headerCache,
+		tdCache:     tdCache,
+		fcontroller: NewForkController(&ForkConfig{
+			ForkBlockNum:   chainConfig.ForkedCfg.ForkBlockNum,
+			Forkpercentage: chainConfig.ForkedCfg.Forkpercentage,
+		}, chainConfig),
+	}
+	lc.stateCache = state.NewDatabase(&odrDatabase{Database: db, retrieve: lc.retrieveNode})
+
+	lc.genesisBlock = rawdb.ReadBlock(db, rawdb.ReadCanonicalHash(db, 0), 0)
+	if lc.genesisBlock == nil {
+		return nil, ErrNoGenesis
+	}
+
+	head := lc.GetHeaderByHash(rawdb.ReadHeadHeaderHash(db))
+	if head == nil {
+		log.Warn("Head header missing, resetting light chain")
+		head = lc.genesisBlock.Header()
+		rawdb.WriteHeadHeaderHash(db, head.Hash())
+	}
+	lc.currentHeader.Store(head)
+
+	irreversibleNumber := rawdb.ReadIrreversibleNumber(db)
+	if head.Number.Uint64() < irreversibleNumber {
+		irreversibleNumber = head.Number.Uint64()
+	}
+	lc.irreversibleNumber.Store(irreversibleNumber)
+	log.Info("Loaded most recent local header", "number", head.Number, "hash", head.Hash(), "td", lc.GetTd(head.Hash(), head.Number.Uint64()), "irreversible", irreversibleNumber)
+	return lc, nil
+}
+
+// SetEngine sets the dpos engine verifying headers and starts syncing.
+func (lc *LightChain) SetEngine(engine *dpos.Dpos) {
+	lc.engine = engine
+	lc.validator = processor.NewBlockValidator(lc, engine)
+	lc.station = newLightStation(lc)
+}
+
+// Stop stops syncing and retrieving.
+func (lc *LightChain) Stop() {
+	if lc.station != nil {
+		lc.station.Stop()
+	}
+	log.Info("Light chain manager stopped")
+}
+
+// Config retrieves the blockchain's chain configuration.
+func (lc *LightChain) Config() *params.ChainConfig { return lc.chainConfig }
+
+// Genesis retrieves the chain's genesis block.
+func (lc *LightChain) Genesis() *types.Block { return lc.genesisBlock }
+
+// CurrentHeader retrieves the current head header of the canonical chain.
+func (lc *LightChain) CurrentHeader() *types.Header {
+	return lc.currentHeader.Load().(*types.Header)
+}
+
+// IrreversibleNumber retrieves the irreversible block number of the canonical chain.
+func (lc *LightChain) IrreversibleNumber() uint64 {
+	return lc.irreversibleNumber.Load().(uint64)
+}
+
+// GetHeader retrieves a block header from the database by hash and number, caching it if found.
+func (lc *LightChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, ok := lc.headerCache.Get(hash); ok {
+		return header.(*types.Header)
+	}
+	header := rawdb.ReadHeader(lc.db, hash, number)
+	if header == nil {
+		return nil
+	}
+	lc.headerCache.Add(hash, header)
+	return header
+}
+
+// GetHeaderByHash retrieves a block header from the database by hash, caching it if found.
+func (lc *LightChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	number := rawdb.ReadHeaderNumber(lc.db, hash)
+	if number == nil {
+		return nil
+	}
+	return lc.GetHeader(hash, *number)
+}
+
+// GetHeaderByNumber retrieves a canonical block header from the database by number.
+func (lc *LightChain) GetHeaderByNumber(number uint64) *types.Header {
+	hash := rawdb.ReadCanonicalHash(lc.db, number)
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	return lc.GetHeader(hash, number)
+}
+
+// GetTd retrieves a block's total difficulty from the database by hash and number, caching it if found.
+func (lc *LightChain) GetTd(hash common.Hash, number uint64) *big.Int {
+	if cached, ok := lc.tdCache.Get(hash); ok {
+		return cached.(*big.Int)
+	}
+	td := rawdb.ReadTd(lc.db, hash, number)
+	if td == nil {
+		return nil
+	}
+	lc.tdCache.Add(hash, td)
+	return td
+}
+
+// GetBlock retrieves a block from the database, only blocks retrieved before
+// are available.
+func (lc *LightChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return rawdb.ReadBlock(lc.db, hash, number)
+}
+
+// HasBlock checks if the header of a block is present, the light chain keeps
+// no bodies.
+func (lc *LightChain) HasBlock(hash common.Hash, number uint64) bool {
+	return rawdb.HasHeader(lc.db, hash, number)
+}
+
+// HasBlockAndState checks if the header of a block is present, its state is
+// retrieved on demand.
+func (lc *LightChain) HasBlockAndState(hash common.Hash, number uint64) bool {
+	return lc.HasBlock(hash, number)
+}
+
+// State returns the state of the current header.
+func (lc *LightChain) State() (*state.StateDB, error) {
+	return lc.StateAt(lc.CurrentHeader().Root)
+}
+
+// StateAt returns a state whose missing trie nodes are retrieved from peers.
+func (lc *LightChain) StateAt(root common.Hash) (*state.StateDB, error) {
+	return state.New(root, lc.stateCache)
+}
+
+// WriteBlockWithState is not supported by the light chain.
+func (lc *LightChain) WriteBlockWithState(block *types.Block, receipts []*types.Receipt, state *state.StateDB) (bool, error) {
+	return false, errors.New("light chain can not write blocks")
+}
+
+// CheckForkID checks the validity of forkID
+func (lc *LightChain) CheckForkID(header *types.Header) error {
+	parentHeader := lc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	state, err := lc.StateAt(parentHeader.Root)
+	if err != nil {
+		return err
+	}
+	return lc.fcontroller.checkForkID(header, state)
+}
+
+// FillForkID fills the current and next forkID
+func (lc *LightChain) FillForkID(header *types.Header, statedb *state.StateDB) error {
+	return lc.fcontroller.fillForkID(header, statedb)
+}
+
+// ForkUpdate is not supported by the light chain, which executes no blocks.
+func (lc *LightChain) ForkUpdate(block *types.Block, statedb *state.StateDB) error {
+	return errors.New("light chain can not update fork")
+}
+
+// ForkStatus returns current fork status.
+func (lc *LightChain) ForkStatus(statedb *state.StateDB) (*ForkConfig, ForkInfo, error) {
+	info, err := lc.fcontroller.getForkInfo(statedb)
+	return lc.fcontroller.cfg, info, err
+}
+
+// InsertHeaderChain verifies headers, including the producer signatures
+// against the state of their parents, and writes them. It returns the index of
+// the failing header.
+func (lc *LightChain) InsertHeaderChain(headers []*types.Header) (int, error) {
+	if lc.engine == nil {
+		return 0, errNoLightEngine
+	}
+	for i := 1; i < len(headers); i++ {
+		if headers[i].Number.Uint64() != headers[i-1].Number.Uint64()+1 || headers[i].ParentHash != headers[i-1].Hash() {
+			return i, fmt.Errorf("non contiguous header insert: item %d is #%d [%x], item %d is #%d [%x] (parent [%x])",
+				i-1, headers[i-1].Number, headers[i-1].Hash().Bytes()[:4], i, headers[i].Number, headers[i].Hash().Bytes()[:4], headers[i].ParentHash[:4])
+		}
+	}
+
+	lc.chainmu.Lock()
+	defer lc.chainmu.Unlock()
+
+	for i, header := range headers {
+		err := lc.validator.ValidateHeader(header, true)
+		if err == processor.ErrKnownBlock {
+			continue
+		}
+		if err != nil {
+			return i, err
+		}
+		if err := lc.writeHeader(header); err != nil {
+			return i, err
+		}
+	}
+	if len(headers) > 0 {
+		last := headers[len(headers)-1]
+		log.Debug("Imported new headers", "count", len(headers), "number", last.Number, "hash", last.Hash(), "irreversible", lc.IrreversibleNumber())
+	}
+	return len(headers), nil
+}
+
+// writeHeader writes a verified header and makes it the head if its total
+// difficulty is higher, never reorganizing irreversible headers.
+func (lc *LightChain) writeHeader(header *types.Header) error {
+	hash, number := header.Hash(), header.Number.Uint64()
+	ptd := lc.GetTd(header.ParentHash, number-1)
+	if ptd == nil {
+		return processor.ErrUnknownAncestor
+	}
+	td := new(big.Int).Add(ptd, header.Difficulty)
+
+	batch := lc.db.NewBatch()
+	rawdb.WriteHeader(batch, header)
+	rawdb.WriteTd(batch, hash, number, td)
+
+	head := lc.CurrentHeader()
+	if td.Cmp(lc.GetTd(head.Hash(), head.Number.Uint64())) <= 0 {
+		return batch.Write()
+	}
+
+	// Overwrite the stale canonical hashes down to the common ancestor
+	irreversible := lc.IrreversibleNumber()
+	headHash, headNumber := header.ParentHash, number-1
+	for rawdb.ReadCanonicalHash(lc.db, headNumber) != headHash {
+		if headNumber <= irreversible {
+			return ErrReorgIrreversible
+		}
+		rawdb.WriteCanonicalHash(batch, headHash, headNumber)
+		headHash = lc.GetHeader(headHash, headNumber).ParentHash
+		headNumber--
+	}
+	// Delete the canonical hashes above the new head
+	for i := number + 1; rawdb.ReadCanonicalHash(lc.db, i) != (common.Hash{}); i++ {
+		rawdb.DeleteCanonicalHash(batch, i)
+	}
+	rawdb.WriteCanonicalHash(batch, hash, number)
+	rawdb.WriteHeadHeaderHash(batch, hash)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	lc.currentHeader.Store(types.CopyHeader(header))
+
+	// Headers confirmed by enough producers are irreversible
+	if proposed := lc.engine.CalcProposedIrreversible(lc, header, false); proposed > irreversible {
+		rawdb.WriteIrreversibleNumber(lc.db, proposed)
+		lc.irreversibleNumber.Store(proposed)
+	}
+	return nil
+}
+
+// RetrieveBlock returns the block of hash, retrieving the body from peers and
+// checking it against the transaction root of the header.
+func (lc *LightChain) RetrieveBlock(hash common.Hash) (*types.Block, error) {
+	header := lc.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, fmt.Errorf("unknown block %x", hash)
+	}
+	if block := lc.GetBlock(hash, header.Number.Uint64()); block != nil {
+		return block, nil
+	}
+	if lc.station == nil {
+		return nil, errNoLightPeer
+	}
+	var block *types.Block
+	err := lc.station.retrieve(func(stationSearch router.Station, peer *stationStatus) error {
+		bodies, err := getBlocks(stationSearch, peer.station, []common.Hash{hash}, peer.errCh)
+		if err != nil {
+			return err
+		}
+		block = types.NewBlockWithHeader(header).WithBody(bodies[0].Transactions)
+		return lc.validator.ValidateBody(block)
+	})
+	if err != nil {
+		return nil, err
+	}
+	rawdb.WriteBody(lc.db, hash, header.Number.Uint64(), &types.Body{Transactions: block.Txs})
+	return block, nil
+}
+
+// RetrieveReceipts returns the receipts of the block of hash, retrieving them
+// from peers and checking them against the receipt root of the header.
+func (lc *LightChain) RetrieveReceipts(hash common.Hash) ([]*types.Receipt, error) {
+	header := lc.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, fmt.Errorf("unknown block %x", hash)
+	}
+	if receipts := rawdb.ReadReceipts(lc.db, hash, header.Number.Uint64()); receipts != nil {
+		return receipts, nil
+	}
+	if lc.station == nil {
+		return nil, errNoLightPeer
+	}
+	var receipts []*types.Receipt
+	err := lc.station.retrieve(func(stationSearch router.Station, peer *stationStatus) error {
+		results, err := getReceipts(stationSearch, peer.station, []common.Hash{hash}, peer.errCh)
+		if err != nil {
+			return err
+		}
+		if root := types.DeriveReceiptsMerkleRoot(results[0]); root != header.ReceiptsRoot {
+			return fmt.Errorf("receipt root hash mismatch: have %x, want %x", root, header.ReceiptsRoot)
+		}
+		receipts = results[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	rawdb.WriteReceipts(lc.db, hash, header.Number.Uint64(), receipts)
+	return receipts, nil
+}
+
+// retrieveNode returns the state trie node of hash from peers.
+func (lc *LightChain) retrieveNode(hash common.Hash) ([]byte, error) {
+	if lc.station == nil {
+		return nil, errNoLightPeer
+	}
+	var node []byte
+	err := lc.station.retrieve(func(stationSearch router.Station, peer *stationStatus) error {
+		nodes, err := getNodeData(stationSearch, peer.station, []common.Hash{hash}, peer.errCh)
+		if err != nil {
+			return err
+		}
+		if len(nodes) == 0 || crypto.Keccak256Hash(nodes[0]) != hash {
+			return &Error{fmt.Errorf("state node %x not delivered", hash), notFind}
+		}
+		node = nodes[0]
+		return nil
+	})
+	return node, err
+}
+
+// odrDatabase is the database of a light chain or a fast sync. State trie
+// nodes missing locally are retrieved from peers by hash, so every value read
+// from a state is proven by the root of a verified header.
+type odrDatabase struct {
+	fdb.Database
+	// nodes stores the retrieved trie nodes, the database itself if nil
+	nodes    fdb.Database
+	retrieve func(hash common.Hash) ([]byte, error)
+}
+
+// Get retrieves key locally, or from peers if key is the hash of a trie node.
+func (db *odrDatabase) Get(key []byte) ([]byte, error) {
+	value, err := db.Database.Get(key)
+	if (err == nil && len(value) > 0) || len(key) != common.HashLength {
+		return value, err
+	}
+	nodes := db.nodes
+	if nodes == nil {
+		nodes = db.Database
+	} else if node, err := nodes.Get(key); err == nil && len(node) > 0 {
+		return node, nil
+	}
+	node, err := db.retrieve(common.BytesToHash(key))
+	if err != nil {
+		return nil, err
+	}
+	if err := nodes.Put(key, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// Has checks key locally, trie nodes are retrieved on Get.
+func (db *odrDatabase) Has(key []byte) (bool, error) {
+	if ok, err := db.Database.Has(key); ok || err != nil || len(key) != common.HashLength {
+		return ok, err
+	}
+	value, err := db.Get(key)
+	return err == nil && len(value) > 0, nil
+}
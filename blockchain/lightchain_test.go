@@ -0,0 +1,102 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/fdb"
+	"github.com/unichainplatform/unichain/utils/fdb/memdb"
+)
+
+func newLightChain(t *testing.T, genesis *Genesis) (*LightChain, fdb.Database) {
+	db := rawdb.NewMemoryDatabase()
+	chainCfg, dposCfg, _, err := SetupGenesisBlock(db, genesis, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lc, err := NewLightChain(db, chainCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lc.SetEngine(dpos.New(dposCfg, lc))
+	return lc, db
+}
+
+func TestLightChainInsertHeaders(t *testing.T) {
+	genesis := DefaultGenesis()
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	_, blocks := makeNewChain(t, genesis, chain, 10, canonicalSeed)
+
+	lc, db := newLightChain(t, genesis)
+	defer lc.Stop()
+
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+
+	// Without peers the parent states needed by the validator are missing.
+	if _, err := lc.InsertHeaderChain(headers); err == nil {
+		t.Fatal("insert without state succeeded")
+	}
+
+	// Serve the trie nodes as peers would have delivered them.
+	diskdb := chain.stateCache.TrieDB().DiskDB().(*memdb.MemDatabase)
+	for _, key := range diskdb.Keys() {
+		if len(key) == common.HashLength {
+			value, _ := diskdb.Get(key)
+			db.Put(key, value)
+		}
+	}
+
+	tampered := types.CopyHeader(headers[0])
+	tampered.Time = new(big.Int).Add(tampered.Time, big.NewInt(1))
+	if _, err := lc.InsertHeaderChain([]*types.Header{tampered}); err == nil {
+		t.Fatal("insert tampered header succeeded")
+	}
+
+	if n, err := lc.InsertHeaderChain(headers); err != nil {
+		t.Fatalf("insert header %d: %v", n, err)
+	}
+	head := headers[len(headers)-1]
+	if lc.CurrentHeader().Hash() != head.Hash() {
+		t.Fatalf("head mismatch: have %x, want %x", lc.CurrentHeader().Hash(), head.Hash())
+	}
+	for _, header := range headers {
+		if have := lc.GetHeaderByNumber(header.Number.Uint64()); have == nil || have.Hash() != header.Hash() {
+			t.Fatalf("canonical header %d mismatch", header.Number.Uint64())
+		}
+	}
+	if lc.GetTd(head.Hash(), head.Number.Uint64()).Cmp(chain.GetTd(head.Hash(), head.Number.Uint64())) != 0 {
+		t.Fatal("total difficulty mismatch")
+	}
+
+	if _, err := lc.StateAt(head.Root); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lc.RetrieveReceipts(head.Hash()); err != errNoLightPeer {
+		t.Fatalf("retrieve receipts without peers: have %v, want %v", err, errNoLightPeer)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/unichainplatform/unichain/common"
+	router "github.com/unichainplatform/unichain/event"
+	adaptor "github.com/unichainplatform/unichain/p2p/protoadaptor"
+	"github.com/unichainplatform/unichain/types"
+)
+
+// maxRetrievePeers is the number of peers tried for one on demand retrieval.
+const maxRetrievePeers = 3
+
+// lightStation syncs the headers of a light chain from full peers and
+// retrieves data from them on demand.
+type lightStation struct {
+	lc          *LightChain
+	peerCh      chan *router.Event
+	peers       map[string]*stationStatus
+	peersMutex  sync.RWMutex
+	syncTrigger chan struct{}
+	quit        chan struct{}
+	loopWG      sync.WaitGroup
+	subs        []router.Subscription
+}
+
+func newLightStation(lc *LightChain) *lightStation {
+	ls := &lightStation{
+		lc:          lc,
+		peerCh:      make(chan *router.Event),
+		peers:       make(map[string]*stationStatus),
+		syncTrigger: make(chan struct{}, 1),
+		quit:        make(chan struct{}),
+		subs:        make([]router.Subscription, 4),
+	}
+	ls.subs[0] = router.Subscribe(nil, ls.peerCh, router.NewPeerNotify, nil)
+	ls.subs[1] = router.Subscribe(nil, ls.peerCh, router.DelPeerNotify, nil)
+	ls.subs[2] = router.Subscribe(nil, ls.peerCh, router.P2PGetStatus, "")
+	ls.subs[3] = router.Subscribe(nil, ls.peerCh, router.P2PNewBlockHashesMsg, &NewBlockHashesData{})
+
+	ls.loopWG.Add(2)
+	go ls.loop()
+	go ls.syncLoop()
+	return ls
+}
+
+// chainStatus reports the genesis as head, so full peers never download from
+// the light chain.
+func (ls *lightStation) chainStatus() *statusData {
+	genesis := ls.lc.Genesis()
+	return &statusData{
+		ProtocolVersion: uint32(1),
+		NetworkID:       0,
+		TD:              ls.lc.GetTd(genesis.Hash(), 0),
+		CurrentBlock:    genesis.Hash(),
+		CurrentNumber:   0,
+		GenesisBlock:    genesis.Hash(),
+	}
+}
+
+func (ls *lightStation) handshake(e *router.Event) {
+	station := router.NewLocalStation("lightshake"+e.From.Name(), nil)
+	ch := make(chan *router.Event)
+	sub := router.Subscribe(station, ch, router.P2PStatusMsg, &statusData{})
+	defer sub.Unsubscribe()
+	router.StationRegister(station)
+	defer router.StationUnregister(station)
+
+	router.SendTo(station, e.From, router.P2PGetStatus, "")
+
+	timer := time.After(5 * time.Second)
+	select {
+	case <-ls.quit:
+	case e := <-ch:
+		remote := e.Data.(*statusData)
+		if err := checkChainStatus(ls.chainStatus(), remote); err != nil {
+			router.SendTo(nil, nil, router.OneMinuteLimited, e.From) // disconnect and put into blacklist
+			log.Warn("Light handshake failure", "error", err, "station", fmt.Sprintf("%x", e.From.Name()))
+			return
+		}
+		log.Info("Light handshake complete", "station", fmt.Sprintf("%x", e.From.Name()))
+		ls.addPeer(e.From, &NewBlockHashesData{
+			Hash:      remote.CurrentBlock,
+			Number:    remote.CurrentNumber,
+			TD:        remote.TD,
+			Completed: true,
+		})
+		router.SendTo(e.From, nil, router.NewPeerPassedNotify, e.Data)
+	case <-timer:
+		log.Warn("Light handshake timeout", "station", fmt.Sprintf("%x", e.From.Name()))
+		router.SendTo(nil, nil, router.DisconectCtrl, e.From)
+	}
+}
+
+func (ls *lightStation) loop() {
+	defer ls.loopWG.Done()
+	for {
+		select {
+		case <-ls.quit:
+			return
+		case e := <-ls.peerCh:
+			switch e.Typecode {
+			case router.NewPeerNotify:
+				ls.loopWG.Add(1)
+				go func() {
+					ls.handshake(e)
+					ls.loopWG.Done()
+				}()
+			case router.DelPeerNotify:
+				ls.delPeer(e.From)
+			case router.P2PGetStatus:
+				router.ReplyEvent(e, router.P2PStatusMsg, ls.chainStatus())
+			case router.P2PNewBlockHashesMsg:
+				if hashdata := e.Data.(*NewBlockHashesData); hashdata.Completed {
+					ls.updatePeer(e.From.Name(), hashdata)
+				}
+			}
+		}
+	}
+}
+
+func (ls *lightStation) addPeer(station router.Station, news *NewBlockHashesData) {
+	status := &stationStatus{
+		station: station,
+		errCh:   make(chan struct{}),
+	}
+	status.updateStatus(news)
+	ls.peersMutex.Lock()
+	ls.peers[station.Name()] = status
+	ls.peersMutex.Unlock()
+	ls.triggerSync(news.TD)
+}
+
+func (ls *lightStation) delPeer(station router.Station) {
+	ls.peersMutex.Lock()
+	defer ls.peersMutex.Unlock()
+	if status, ok := ls.peers[station.Name()]; ok {
+		delete(ls.peers, station.Name())
+		close(status.errCh)
+	}
+}
+
+func (ls *lightStation) updatePeer(name string, news *NewBlockHashesData) {
+	ls.peersMutex.RLock()
+	status, ok := ls.peers[name]
+	ls.peersMutex.RUnlock()
+	if ok {
+		status.updateStatus(news)
+		ls.triggerSync(news.TD)
+	}
+}
+
+// sortedPeers returns the peers ordered by their total difficulty.
+func (ls *lightStation) sortedPeers() []*stationStatus {
+	ls.peersMutex.RLock()
+	peers := make([]*stationStatus, 0, len(ls.peers))
+	for _, status := range ls.peers {
+		peers = append(peers, status)
+	}
+	ls.peersMutex.RUnlock()
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].getStatus().TD.Cmp(peers[j].getStatus().TD) > 0
+	})
+	return peers
+}
+
+func (ls *lightStation) triggerSync(td *big.Int) {
+	head := ls.lc.CurrentHeader()
+	if td.Cmp(ls.lc.GetTd(head.Hash(), head.Number.Uint64())) <= 0 {
+		return
+	}
+	select {
+	case ls.syncTrigger <- struct{}{}:
+	default:
+	}
+}
+
+func (ls *lightStation) syncLoop() {
+	defer ls.loopWG.Done()
+	for {
+		select {
+		case <-ls.quit:
+			return
+		case <-ls.syncTrigger:
+			for ls.syncHeaders() {
+			}
+		}
+	}
+}
+
+func newSearchStation(prefix string) router.Station {
+	station := router.NewLocalStation(fmt.Sprintf("%s%d", prefix, rand.Int()), nil)
+	router.StationRegister(station)
+	return station
+}
+
+// syncHeaders downloads and inserts headers from the best peer, it returns
+// true if it should be called again.
+func (ls *lightStation) syncHeaders() bool {
+	peers := ls.sortedPeers()
+	if len(peers) == 0 {
+		return false
+	}
+	status := peers[0]
+	latest := status.getStatus()
+	head := ls.lc.CurrentHeader()
+	if latest.TD.Cmp(ls.lc.GetTd(head.Hash(), head.Number.Uint64())) <= 0 {
+		return false
+	}
+
+	stationSearch := newSearchStation("lightSearch")
+	defer router.StationUnregister(stationSearch)
+
+	headNumber := head.Number.Uint64()
+	if headNumber > latest.Number {
+		headNumber = latest.Number
+	}
+	ancestor, _, err := findAncestor(ls.lc, stationSearch, status.station, headNumber, status.ancestor, status.errCh)
+	if err != nil {
+		log.Warn("Light ancestor err", "err", err, "errID:", err.eid)
+		router.AddErr(status.station, 1)
+		if err.eid == notFind {
+			log.Warn("Disconnect because ancestor not find:", "node:", adaptor.GetFnode(status.station))
+			router.SendTo(nil, nil, router.OneMinuteLimited, status.station) // disconnect and put into blacklist
+		}
+		return false
+	}
+	amount := latest.Number - ancestor
+	if amount == 0 { // maybe the status of remote was changed
+		return false
+	}
+	if amount > maxHeaderFetch {
+		amount = maxHeaderFetch
+	}
+	headers, err := getHeaders(stationSearch, status.station, &getBlockHeadersData{
+		Origin: hashOrNumber{Number: ancestor + 1},
+		Amount: amount,
+	}, status.errCh)
+	if err != nil {
+		log.Warn("Light get headers err", "err", err)
+		router.AddErr(status.station, 1)
+		return false
+	}
+	if n, err := ls.lc.InsertHeaderChain(headers); err != nil {
+		log.Warn("Disconnect because header insert error:", "node:", adaptor.GetFnode(status.station), "number", headers[n].Number, "err", err)
+		router.AddErr(status.station, uint64(len(headers)-n))
+		router.SendTo(nil, nil, router.OneMinuteLimited, status.station) // disconnect and put into blacklist
+		return false
+	}
+	status.ancestor = ancestor + amount
+	return true
+}
+
+// retrieve calls fetch with up to maxRetrievePeers peers until one succeeds.
+func (ls *lightStation) retrieve(fetch func(stationSearch router.Station, peer *stationStatus) error) error {
+	stationSearch := newSearchStation("lightRetrieve")
+	defer router.StationUnregister(stationSearch)
+
+	err := errNoLightPeer
+	for i, peer := range ls.sortedPeers() {
+		if i >= maxRetrievePeers {
+			break
+		}
+		if err = fetch(stationSearch, peer); err == nil {
+			return nil
+		}
+		log.Debug("Light retrieve failed", "node", adaptor.GetFnode(peer.station), "err", err)
+	}
+	return err
+}
+
+func getReceipts(from router.Station, to router.Station, req []common.Hash, errch chan struct{}) ([][]*types.Receipt, *Error) {
+	se := &router.Event{
+		From:     from,
+		To:       to,
+		Typecode: router.P2PGetReceiptsMsg,
+		Data:     req,
+	}
+	timeout := time.Second + time.Duration(len(req))*(100*time.Millisecond)
+	e, err := syncReq(se, router.P2PReceiptsMsg, [][]*types.Receipt{}, timeout, errch)
+	if err != nil {
+		return nil, err
+	}
+	receipts := e.Data.([][]*types.Receipt)
+	if len(receipts) != len(req) {
+		return receipts, &Error{fmt.Errorf("wrong size, expected %d got %d", len(req), len(receipts)), sizeNotEqual}
+	}
+	return receipts, nil
+}
+
+func (ls *lightStation) Stop() {
+	log.Info("Light station stopping...")
+	close(ls.quit)
+	for _, sub := range ls.subs {
+		sub.Unsubscribe()
+	}
+	ls.loopWG.Wait()
+	ls.peersMutex.Lock()
+	for name, status := range ls.peers {
+		delete(ls.peers, name)
+		close(status.errCh)
+	}
+	ls.peersMutex.Unlock()
+	log.Info("Light station stopped.")
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/unichainplatform/unichain/processor"
 	"github.com/unichainplatform/unichain/processor/vm"
 	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/snapshot"
 	"github.com/unichainplatform/unichain/state"
 	"github.com/unichainplatform/unichain/txpool"
 	"github.com/unichainplatform/unichain/types"
@@ -55,15 +56,19 @@ func (fe *fakeEngine) VerifySeal(chain consensus.IChainReader, header *types.Hea
 }
 
 func newCanonical(t *testing.T, genesis *Genesis) *BlockChain {
+	return newCanonicalWithConfig(t, genesis, vm.Config{})
+}
+
+func newCanonicalWithConfig(t *testing.T, genesis *Genesis, vmConfig vm.Config) *BlockChain {
 	// Initialize a fresh chain with only a genesis block
 	chainDb := rawdb.NewMemoryDatabase()
 
-	chainCfg, dposCfg, _, err := SetupGenesisBlock(chainDb, genesis)
+	chainCfg, dposCfg, _, err := SetupGenesisBlock(chainDb, genesis, vmConfig.ContractLogFlag || vmConfig.InternalIndexFlag)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	blockchain, err := NewBlockChain(chainDb, false, vm.Config{}, chainCfg, nil, 0, txpool.SenderCacher)
+	blockchain, err := NewBlockChain(chainDb, false, vmConfig, chainCfg, nil, 0, txpool.SenderCacher)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -117,7 +122,7 @@ func makeNewChain(t *testing.T, genesis *Genesis, chain *BlockChain, n, seed int
 			name := common.StrToName(genesis.Config.SysName)
 			b.SetCoinbase(name)
 
-			engine.SetSignFn(func(content []byte, state *state.StateDB) ([]byte, error) {
+			engine.SetSignFn(func(content []byte, header *types.Header, state *state.StateDB) ([]byte, error) {
 				return crypto.Sign(content, systemPrivateKey)
 			})
 		})
@@ -165,9 +170,7 @@ func generateChain(config *params.ChainConfig, parent *types.Block, engine *dpos
 
 		if b.engine != nil {
 			// Finalize and seal the block
-			if err := b.engine.Prepare(b, b.header, b.txs, nil, b.stateDB); err != nil {
-				panic(fmt.Sprintf("engine prepare error: %v", err))
-			}
+			b.prepare()
 
 			name := common.StrToName(chain.chainConfig.SysName)
 
@@ -204,6 +207,13 @@ func generateChain(config *params.ChainConfig, parent *types.Block, engine *dpos
 				panic(fmt.Sprintf("trie write error: %v", err))
 			}
 
+			// snapshots are read by the following blocks from the database of
+			// the state, written as in WriteBlockWithState
+			if number, hash, err := snapshot.NewSnapshotManager(b.stateDB).GetCurrentSnapshotHash(); err == nil &&
+				number == block.NumberU64() && hash == block.ParentHash() {
+				rawdb.WriteSnapshot(b.stateDB.Database().GetDB(), types.SnapshotBlock{Number: number, BlockHash: hash}, types.SnapshotInfo{Root: root})
+			}
+
 			if err := batch.Write(); err != nil {
 				panic(fmt.Sprintf("batch Write error: %v", err))
 			}
@@ -0,0 +1,198 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/unichainplatform/unichain/accountmanager"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/types"
+)
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Manage keys in the keystore and send account transactions",
+	Long:  `Manage keys in the keystore and send account transactions. There is no command for DeleteAccount actions, the node does not process them.`,
+	Args:  cobra.NoArgs,
+}
+
+var newKeyCmd = &cobra.Command{
+	Use:   "newkey",
+	Short: "Generate a new key encrypted in the keystore.",
+	Long:  `Generate a new key encrypted in the keystore.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		key, err := openKeystore().NewKey(readPassphrase("Passphrase: ", true))
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		printJSON(key.PubKey)
+	},
+}
+
+var importKeyCmd = &cobra.Command{
+	Use:   "importkey <privateKey file>",
+	Short: "Import a hex private key from a file into the keystore.",
+	Long:  `Import a hex private key from a file into the keystore.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		priv, err := crypto.LoadECDSA(args[0])
+		if err != nil {
+			jww.ERROR.Println("load private key failed", "path", args[0], "err", err)
+			os.Exit(1)
+		}
+		key, err := openKeystore().ImportECDSA(priv, readPassphrase("Passphrase: ", true))
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		printJSON(key.PubKey)
+	},
+}
+
+var listKeysCmd = &cobra.Command{
+	Use:   "listkeys",
+	Short: "List the public keys in the keystore.",
+	Long:  `List the public keys in the keystore.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		keys, err := openKeystore().Keys()
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		printJSON(keys)
+	},
+}
+
+var accountCreateCmd = &cobra.Command{
+	Use:   "create <payload json>",
+	Short: "Create an account, the payload is {\"accountName\",\"founder\",\"publicKey\",\"description\"}.",
+	Long:  `Create an account, the payload is {"accountName","founder","publicKey","description"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.CreateAccountAction{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.CreateAccount, payload)
+	},
+}
+
+var accountUpdateCmd = &cobra.Command{
+	Use:   "update <payload json>",
+	Short: "Update the founder of the account, the payload is {\"founder\"}.",
+	Long:  `Update the founder of the account, the payload is {"founder"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.UpdataAccountAction{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.UpdateAccount, payload)
+	},
+}
+
+var accountUpdateAuthorCmd = &cobra.Command{
+	Use:   "updateauthor <payload json>",
+	Short: "Update the authors of the account, the payload is {\"threshold\",\"updateAuthorThreshold\",\"authorActions\"}.",
+	Long:  `Update the authors of the account, the payload is {"threshold","updateAuthorThreshold","authorActions"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.AccountAuthorAction{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.UpdateAccountAuthor, payload)
+	},
+}
+
+var lockedTransferCmd = &cobra.Command{
+	Use:   "lockedtransfer <payload json>",
+	Short: "Transfer --value of --asset released on a vesting schedule, the payload is {\"to\",\"start\",\"cliff\",\"end\"}.",
+	Long:  `Transfer --value of --asset released on a vesting schedule, the payload is {"to","start","cliff","end"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.LockedTransferAction{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.LockedTransfer, payload)
+	},
+}
+
+var claimLockedCmd = &cobra.Command{
+	Use:   "claimlocked",
+	Short: "Claim the vested locked balance of --asset.",
+	Long:  `Claim the vested locked balance of --asset.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.ClaimLocked, nil)
+	},
+}
+
+var setRecoveryCmd = &cobra.Command{
+	Use:   "setrecovery <payload json>",
+	Short: "Set the guardians of the account, the payload is {\"guardians\",\"threshold\",\"delay\"}.",
+	Long:  `Set the guardians of the account, the payload is {"guardians","threshold","delay"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.SetRecoveryAction{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.SetAccountRecovery, payload)
+	},
+}
+
+var approveRecoveryCmd = &cobra.Command{
+	Use:   "approverecovery <payload json>",
+	Short: "Approve as a guardian the author reset of an account, the payload is {\"account\",\"reset\"}.",
+	Long:  `Approve as a guardian the author reset of an account, the payload is {"account","reset"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.ApproveRecoveryAction{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.ApproveAccountRecovery, payload)
+	},
+}
+
+var executeRecoveryCmd = &cobra.Command{
+	Use:   "executerecovery <account>",
+	Short: "Execute as a guardian the approved author reset of an account.",
+	Long:  `Execute as a guardian the approved author reset of an account.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.ExecuteRecoveryAction{Account: common.Name(args[0])}
+		sendSystemAction(types.ExecuteAccountRecovery, payload)
+	},
+}
+
+var cancelRecoveryCmd = &cobra.Command{
+	Use:   "cancelrecovery",
+	Short: "Cancel the pending author reset of the account.",
+	Long:  `Cancel the pending author reset of the account.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.CancelAccountRecovery, nil)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(accountCmd)
+	accountCmd.AddCommand(newKeyCmd, importKeyCmd, listKeysCmd)
+	txCmds := []*cobra.Command{accountCreateCmd, accountUpdateCmd, accountUpdateAuthorCmd, lockedTransferCmd,
+		claimLockedCmd, setRecoveryCmd, approveRecoveryCmd, executeRecoveryCmd, cancelRecoveryCmd}
+	addWalletFlags(txCmds...)
+	accountCmd.AddCommand(txCmds...)
+	addKeystoreFlags(accountCmd)
+}
@@ -0,0 +1,189 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/unichainplatform/unichain/accountmanager"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/types"
+)
+
+var assetCmd = &cobra.Command{
+	Use:   "asset",
+	Short: "Send asset transactions signed with keys in the keystore",
+	Long:  `Send asset transactions signed with keys in the keystore`,
+	Args:  cobra.NoArgs,
+}
+
+var transferCmd = &cobra.Command{
+	Use:   "transfer <to>",
+	Short: "Transfer --value of --asset to an account.",
+	Long:  `Transfer --value of --asset to an account.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendAction(types.Transfer, common.Name(args[0]), nil)
+	},
+}
+
+var issueAssetCmd = &cobra.Command{
+	Use:   "issue <payload json>",
+	Short: "Issue an asset, the payload is {\"assetName\",\"symbol\",\"amount\",\"decimals\",\"founder\",\"owner\",\"upperLimit\",\"contract\",\"description\"}.",
+	Long:  `Issue an asset, the payload is {"assetName","symbol","amount","decimals","founder","owner","upperLimit","contract","description"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.IssueAsset{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.IssueAsset, payload)
+	},
+}
+
+var increaseAssetCmd = &cobra.Command{
+	Use:   "increase <payload json>",
+	Short: "Increase the amount of an asset, the payload is {\"assetId\",\"amount\",\"acceptor\"}.",
+	Long:  `Increase the amount of an asset, the payload is {"assetId","amount","acceptor"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.IncAsset{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.IncreaseAsset, payload)
+	},
+}
+
+var destroyAssetCmd = &cobra.Command{
+	Use:   "destroy",
+	Short: "Destroy --value of --asset.",
+	Long:  `Destroy --value of --asset.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.DestroyAsset, nil)
+	},
+}
+
+var setAssetOwnerCmd = &cobra.Command{
+	Use:   "setowner <payload json>",
+	Short: "Set the owner of an asset, the payload is {\"assetId\",\"owner\"}.",
+	Long:  `Set the owner of an asset, the payload is {"assetId","owner"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.UpdateAssetOwner{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.SetAssetOwner, payload)
+	},
+}
+
+var updateAssetCmd = &cobra.Command{
+	Use:   "update <payload json>",
+	Short: "Update the founder of an asset, the payload is {\"assetId\",\"founder\"}.",
+	Long:  `Update the founder of an asset, the payload is {"assetId","founder"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.UpdateAsset{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.UpdateAsset, payload)
+	},
+}
+
+var updateAssetContractCmd = &cobra.Command{
+	Use:   "updatecontract <payload json>",
+	Short: "Update the contract of an asset, the payload is {\"assetId\",\"contract\"}.",
+	Long:  `Update the contract of an asset, the payload is {"assetId","contract"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.UpdateAssetContract{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.UpdateAssetContract, payload)
+	},
+}
+
+var freezeAssetCmd = &cobra.Command{
+	Use:   "freeze <payload json>",
+	Short: "Freeze the asset of an account, the payload is {\"assetId\",\"account\"}.",
+	Long:  `Freeze the asset of an account, the payload is {"assetId","account"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.FreezeAccountAsset{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.FreezeAccountAsset, payload)
+	},
+}
+
+var unfreezeAssetCmd = &cobra.Command{
+	Use:   "unfreeze <payload json>",
+	Short: "Unfreeze the asset of an account, the payload is {\"assetId\",\"account\"}.",
+	Long:  `Unfreeze the asset of an account, the payload is {"assetId","account"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.FreezeAccountAsset{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.UnfreezeAccountAsset, payload)
+	},
+}
+
+var pauseAssetCmd = &cobra.Command{
+	Use:   "pause <assetId>",
+	Short: "Pause all transfers of an asset.",
+	Long:  `Pause all transfers of an asset.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.PauseAsset, &accountmanager.PauseAsset{AssetID: parseUint64(args[0])})
+	},
+}
+
+var unpauseAssetCmd = &cobra.Command{
+	Use:   "unpause <assetId>",
+	Short: "Unpause all transfers of an asset.",
+	Long:  `Unpause all transfers of an asset.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.UnpauseAsset, &accountmanager.PauseAsset{AssetID: parseUint64(args[0])})
+	},
+}
+
+var approveAssetCmd = &cobra.Command{
+	Use:   "approve <payload json>",
+	Short: "Allow an account to transfer the asset of the sender, the payload is {\"spender\",\"assetId\",\"amount\"}.",
+	Long:  `Allow an account to transfer the asset of the sender, the payload is {"spender","assetId","amount"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.ApproveAssetAction{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.ApproveAsset, payload)
+	},
+}
+
+var transferFromCmd = &cobra.Command{
+	Use:   "transferfrom <payload json>",
+	Short: "Transfer the asset of another account out of the allowance, the payload is {\"from\",\"to\",\"assetId\",\"amount\"}.",
+	Long:  `Transfer the asset of another account out of the allowance, the payload is {"from","to","assetId","amount"}.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		payload := &accountmanager.TransferFromAction{}
+		parsePayload(args[0], payload)
+		sendSystemAction(types.TransferFromAsset, payload)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(assetCmd)
+	txCmds := []*cobra.Command{transferCmd, issueAssetCmd, increaseAssetCmd, destroyAssetCmd, setAssetOwnerCmd,
+		updateAssetCmd, updateAssetContractCmd, freezeAssetCmd, unfreezeAssetCmd, pauseAssetCmd, unpauseAssetCmd,
+		approveAssetCmd, transferFromCmd}
+	addWalletFlags(txCmds...)
+	assetCmd.AddCommand(txCmds...)
+	addKeystoreFlags(assetCmd)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/unichainplatform/unichain/params"
 	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/uniservice"
 )
 
 var (
@@ -55,12 +56,44 @@ var (
 			printJSON(result)
 		},
 	}
+
+	reindexCommand = &cobra.Command{
+		Use:   "reindex -d <datadir>",
+		Short: "Rebuild the account transaction and internal action indexes of an existing datadir. ",
+		Long:  "Rebuild the account transaction and internal action indexes of an existing datadir, the node must be stopped. ",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			uniCfgInstance.LogCfg.Setup()
+			if err := reindexChain(); err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
 )
 
 func init() {
 	RootCmd.AddCommand(chainCommand)
-	chainCommand.AddCommand(statePureCommand, forkStatusCommand)
+	chainCommand.AddCommand(statePureCommand, forkStatusCommand, reindexCommand)
 	statePureCommand.Flags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
+	reindexCommand.Flags().StringVarP(&uniCfgInstance.NodeCfg.DataDir, "datadir", "d", uniCfgInstance.NodeCfg.DataDir, "Data directory for the databases ")
+}
+
+func reindexChain() error {
+	stack, err := makeNode()
+	if err != nil {
+		return err
+	}
+
+	ctx := stack.GetNodeConfig()
+	unisrv, err := uniservice.New(ctx, uniCfgInstance.UniServiceCfg)
+	if err != nil {
+		return err
+	}
+	defer unisrv.Stop()
+	if err := unisrv.BlockChain().ReindexAccountTxs(); err != nil {
+		return err
+	}
+	return unisrv.BlockChain().ReindexInternalActions()
 }
 
 func pruneState(arg string) error {
@@ -92,6 +92,7 @@ func defaultUniServiceConfig() *uniservice.Config {
 		MetricsConf:     defaultMetricsConfig(),
 		ContractLogFlag: false,
 		StatePruning:    true,
+		KeyStore:        "keystore",
 	}
 }
 
@@ -0,0 +1,255 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+var dposCmd = &cobra.Command{
+	Use:   "dpos",
+	Short: "Send dpos candidate and vote transactions signed with keys in the keystore",
+	Long:  `Send dpos candidate and vote transactions signed with keys in the keystore`,
+	Args:  cobra.NoArgs,
+}
+
+var regCandidateCmd = &cobra.Command{
+	Use:   "regcandidate <info>",
+	Short: "Register the sender as candidate staking --value.",
+	Long:  `Register the sender as candidate staking --value.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.RegCandidate, &dpos.RegisterCandidate{Info: args[0]})
+	},
+}
+
+var commission uint64
+
+var updateCandidateCmd = &cobra.Command{
+	Use:   "updatecandidate <info>",
+	Short: "Update the info of the candidate.",
+	Long:  `Update the info of the candidate, and the commission rate in percent the candidate keep of its rewards if --commission set.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		arg := &dpos.UpdateCandidate{Info: args[0]}
+		if cmd.Flags().Changed("commission") {
+			arg.Commission = []uint64{commission}
+		}
+		sendSystemAction(types.UpdateCandidate, arg)
+	},
+}
+
+var updateCandidatePubKeyCmd = &cobra.Command{
+	Use:   "updatecandidatepubkey <pubkey>",
+	Short: "Update the public key signing the blocks of the candidate.",
+	Long:  `Update the public key signing the blocks of the candidate.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !common.IsHexPubKey(args[0]) {
+			jww.ERROR.Printf("invalid public key %v", args[0])
+			os.Exit(1)
+		}
+		sendSystemAction(types.UpdateCandidatePubKey, &dpos.UpdateCandidatePubKey{PubKey: common.HexToPubKey(args[0])})
+	},
+}
+
+var unregCandidateCmd = &cobra.Command{
+	Use:   "unregcandidate",
+	Short: "Unregister the candidate.",
+	Long:  `Unregister the candidate.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.UnregCandidate, nil)
+	},
+}
+
+var refundCandidateCmd = &cobra.Command{
+	Use:   "refundcandidate",
+	Short: "Refund the stake of the unregistered candidate.",
+	Long:  `Refund the stake of the unregistered candidate.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.RefundCandidate, nil)
+	},
+}
+
+var voteCandidateCmd = &cobra.Command{
+	Use:   "vote <candidate> <stake>",
+	Short: "Vote a candidate with stake.",
+	Long:  `Vote a candidate with stake.`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.VoteCandidate, &dpos.VoteCandidate{Candidate: args[0], Stake: parseBigInt(args[1])})
+	},
+}
+
+var kickedCandidateCmd = &cobra.Command{
+	Use:   "kickcandidate <candidates...>",
+	Short: "Kick candidates while the system account takes over.",
+	Long:  `Kick candidates while the system account takes over.`,
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.KickedCandidate, &dpos.KickedCandidate{Candidates: args})
+	},
+}
+
+var removeKickedCandidateCmd = &cobra.Command{
+	Use:   "removekickedcandidate <candidates...>",
+	Short: "Remove kicked candidates while the system account takes over.",
+	Long:  `Remove kicked candidates while the system account takes over.`,
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.RemoveKickedCandidate, &dpos.RemoveKickedCandidate{Candidates: args})
+	},
+}
+
+var exitTakeOverCmd = &cobra.Command{
+	Use:   "exittakeover",
+	Short: "Exit the take over of the system account.",
+	Long:  `Exit the take over of the system account.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.ExitTakeOver, nil)
+	},
+}
+
+var reportDoubleSignCmd = &cobra.Command{
+	Use:   "reportdoublesign <evidence hash>",
+	Short: "Report the double sign evidence detected by the node to slash the candidate.",
+	Long:  `Report the double sign evidence detected by the node to slash the candidate.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var result dpos.RPCEvidence
+		clientCall(ipcEndpoint, &result, "dpos_evidence", common.HexToHash(args[0]))
+		ev := &dpos.Evidence{}
+		if err := rlp.DecodeBytes(common.Hex2Bytes(result.Raw), ev); err != nil {
+			jww.ERROR.Println("decode evidence failed", "err", err)
+			os.Exit(1)
+		}
+		sendSystemAction(types.ReportDoubleSign, &dpos.ReportDoubleSign{Evidence: ev})
+	},
+}
+
+var claimRewardCmd = &cobra.Command{
+	Use:   "claimreward",
+	Short: "Claim the rewards shared by the candidates voted.",
+	Long:  `Claim the rewards shared by the candidates voted.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.ClaimReward, nil)
+	},
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report [epoch]",
+	Short: "Report the blocks expected, produced and missed of the candidates in the epoch.",
+	Long:  `Report the blocks expected, produced and missed, the average propagation delay and the gross block rewards of the candidates in the epoch, the current epoch if not set.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		epoch := uint64(0)
+		if len(args) == 1 {
+			epoch = parseUint64(args[0])
+		}
+		var result interface{}
+		clientCall(ipcEndpoint, &result, "dpos_producerReport", epoch)
+		printJSON(result)
+	},
+}
+
+var createProposalCmd = &cobra.Command{
+	Use:   "propose <info> <name=value...>",
+	Short: "Propose to change chain parameters.",
+	Long:  `Propose to change chain parameters, named as in the chain config, e.g. blockReward=3. The changes take effect at the epoch after the passed proposal executed.`,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		changes := []*dpos.ParamChange{}
+		for _, arg := range args[1:] {
+			kv := strings.SplitN(arg, "=", 2)
+			if len(kv) != 2 {
+				jww.ERROR.Printf("invalid parameter change %v, use name=value", arg)
+				os.Exit(1)
+			}
+			changes = append(changes, &dpos.ParamChange{Name: kv[0], Value: parseBigInt(kv[1])})
+		}
+		sendSystemAction(types.CreateProposal, &dpos.CreateProposal{Info: args[0], Changes: changes})
+	},
+}
+
+var voteProposalCmd = &cobra.Command{
+	Use:   "voteproposal <id> <yes|no>",
+	Short: "Vote for or against a proposal with the stake.",
+	Long:  `Vote for or against a proposal with the stake of the candidate and its votes, voting again replaces the vote.`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var approve bool
+		switch strings.ToLower(args[1]) {
+		case "yes":
+			approve = true
+		case "no":
+		default:
+			jww.ERROR.Printf("invalid vote %v, use yes or no", args[1])
+			os.Exit(1)
+		}
+		sendSystemAction(types.VoteProposal, &dpos.VoteProposal{ID: parseUint64(args[0]), Approve: approve})
+	},
+}
+
+var executeProposalCmd = &cobra.Command{
+	Use:   "executeproposal <id>",
+	Short: "Tally a proposal whose voting has ended.",
+	Long:  `Tally a proposal whose voting has ended, the changes of the passed proposal take effect at the next epoch.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendSystemAction(types.ExecuteProposal, &dpos.ExecuteProposal{ID: parseUint64(args[0])})
+	},
+}
+
+var proposalsCmd = &cobra.Command{
+	Use:   "proposals [id]",
+	Short: "Get the proposals, or the proposal by id.",
+	Long:  `Get the proposals, or the proposal by id.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var result interface{}
+		if len(args) == 1 {
+			clientCall(ipcEndpoint, &result, "dpos_proposal", parseUint64(args[0]))
+		} else {
+			clientCall(ipcEndpoint, &result, "dpos_proposals")
+		}
+		printJSON(result)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(dposCmd)
+	txCmds := []*cobra.Command{regCandidateCmd, updateCandidateCmd, updateCandidatePubKeyCmd, unregCandidateCmd,
+		refundCandidateCmd, voteCandidateCmd, kickedCandidateCmd, removeKickedCandidateCmd, exitTakeOverCmd, reportDoubleSignCmd, claimRewardCmd,
+		createProposalCmd, voteProposalCmd, executeProposalCmd}
+	addWalletFlags(txCmds...)
+	updateCandidateCmd.Flags().Uint64Var(&commission, "commission", dpos.MaxCommission, "commission rate in percent the candidate keep of its rewards")
+	dposCmd.AddCommand(txCmds...)
+	dposCmd.AddCommand(reportCmd, proposalsCmd)
+	addKeystoreFlags(dposCmd)
+}
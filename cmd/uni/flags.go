@@ -257,6 +257,22 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("uniservice.contractlog", flags.Lookup("contractlog"))
 
+	flags.BoolVar(
+		&uniCfgInstance.UniServiceCfg.AccountIndexFlag,
+		"accountindex",
+		uniCfgInstance.UniServiceCfg.AccountIndexFlag,
+		"flag for db to store the account transaction index.",
+	)
+	viper.BindPFlag("uniservice.accountindex", flags.Lookup("accountindex"))
+
+	flags.BoolVar(
+		&uniCfgInstance.UniServiceCfg.InternalIndexFlag,
+		"internalindex",
+		uniCfgInstance.UniServiceCfg.InternalIndexFlag,
+		"flag for db to store the internal action index, implies contractlog.",
+	)
+	viper.BindPFlag("uniservice.internalindex", flags.Lookup("internalindex"))
+
 	// state pruning
 	flags.BoolVar(
 		&uniCfgInstance.UniServiceCfg.StatePruning,
@@ -284,6 +300,33 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("uniservice.badhashes", flags.Lookup("bad_hashes"))
 
+	// fast sync
+	flags.BoolVar(
+		&uniCfgInstance.UniServiceCfg.FastSync,
+		"fastsync",
+		uniCfgInstance.UniServiceCfg.FastSync,
+		"download the state of a recent block instead of executing every block when the node is fresh.",
+	)
+	viper.BindPFlag("uniservice.fastsync", flags.Lookup("fastsync"))
+
+	// light client
+	flags.BoolVar(
+		&uniCfgInstance.UniServiceCfg.Light,
+		"light",
+		uniCfgInstance.UniServiceCfg.Light,
+		"run as a light client which syncs headers only and retrieves state from full peers on demand.",
+	)
+	viper.BindPFlag("uniservice.light", flags.Lookup("light"))
+
+	// keystore
+	flags.StringVar(
+		&uniCfgInstance.UniServiceCfg.KeyStore,
+		"keystore",
+		uniCfgInstance.UniServiceCfg.KeyStore,
+		"Directory of the encrypted key files, relative to the node data directory",
+	)
+	viper.BindPFlag("uniservice.keystore", flags.Lookup("keystore"))
+
 	// txpool
 	flags.BoolVar(
 		&uniCfgInstance.UniServiceCfg.TxPool.NoLocals,
@@ -423,6 +466,30 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("uniservice.miner.private", flags.Lookup("miner_private"))
 
+	flags.StringSliceVar(
+		&uniCfgInstance.UniServiceCfg.Miner.KeyStoreKeys,
+		"miner_keystorekeys",
+		uniCfgInstance.UniServiceCfg.Miner.KeyStoreKeys,
+		"Hex of public keys of keystore keys for block mining rewards, used instead of miner_private",
+	)
+	viper.BindPFlag("uniservice.miner.keystorekeys", flags.Lookup("miner_keystorekeys"))
+
+	flags.StringVar(
+		&uniCfgInstance.UniServiceCfg.Miner.PasswordFile,
+		"miner_password",
+		uniCfgInstance.UniServiceCfg.Miner.PasswordFile,
+		"File containing the passphrase of the miner keystore keys",
+	)
+	viper.BindPFlag("uniservice.miner.password", flags.Lookup("miner_password"))
+
+	flags.StringVar(
+		&uniCfgInstance.UniServiceCfg.Miner.Signer,
+		"miner_signer",
+		uniCfgInstance.UniServiceCfg.Miner.Signer,
+		"IPC path or HTTP url of the remote signer holding the block signing keys, used instead of miner keys",
+	)
+	viper.BindPFlag("uniservice.miner.signer", flags.Lookup("miner_signer"))
+
 	flags.StringVar(
 		&uniCfgInstance.UniServiceCfg.Miner.ExtraData,
 		"miner_extra",
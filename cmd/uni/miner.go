@@ -122,6 +122,17 @@ var setCoinbaseCmd = &cobra.Command{
 	},
 }
 
+var setSignerCmd = &cobra.Command{
+	Use:   "setsigner <name> <signer url>",
+	Short: "Set the coinbase of the miner whose blocks are signed by a remote signer.",
+	Long:  `Set the coinbase of the miner whose blocks are signed by the keys of the remote signer at the IPC path or HTTP url.`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		clientCall(ipcEndpoint, nil, "miner_setRemoteSigner", common.Name(args[0]), args[1])
+		printJSON(true)
+	},
+}
+
 var setExtraCmd = &cobra.Command{
 	Use:   "setextra <extra>",
 	Short: "Set the extra of the miner.",
@@ -146,6 +157,6 @@ var setDelayCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(minerCmd)
-	minerCmd.AddCommand(startCmd, forceCmd, stopCmd, miningCmd, setCoinbaseCmd, setExtraCmd, setDelayCmd)
+	minerCmd.AddCommand(startCmd, forceCmd, stopCmd, miningCmd, setCoinbaseCmd, setSignerCmd, setExtraCmd, setDelayCmd)
 	minerCmd.PersistentFlags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
 }
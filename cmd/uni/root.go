@@ -30,6 +30,7 @@ import (
 	"github.com/unichainplatform/unichain/blockchain"
 	"github.com/unichainplatform/unichain/cmd/utils"
 	"github.com/unichainplatform/unichain/debug"
+	"github.com/unichainplatform/unichain/light"
 	"github.com/unichainplatform/unichain/uniservice"
 	"github.com/unichainplatform/unichain/metrics"
 	"github.com/unichainplatform/unichain/metrics/influxdb"
@@ -162,6 +163,11 @@ func startNode(stack *node.Node) error {
 }
 
 func registerService(stack *node.Node) error {
+	if uniCfgInstance.UniServiceCfg.Light {
+		return stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+			return light.New(ctx, uniCfgInstance.UniServiceCfg)
+		})
+	}
 	return stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
 		return uniservice.New(ctx, uniCfgInstance.UniServiceCfg)
 	})
@@ -23,13 +23,13 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func readAndUnmarshal(t *testing.T, file string) *ftConfig {
+func readAndUnmarshal(t *testing.T, file string) *uniConfig {
 	v := viper.New()
 	v.SetConfigFile(file)
 	if err := v.ReadInConfig(); err != nil {
 		t.Fatalf("read config %v file err %v", file, err)
 	}
-	fc := new(ftConfig)
+	fc := new(uniConfig)
 	err := v.Unmarshal(fc)
 	if err != nil {
 		t.Fatalf("unmarshal %v err %v", file, err)
@@ -0,0 +1,119 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/unichainplatform/unichain/accountmanager"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/types"
+)
+
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Send contract and raw transactions and query their results",
+	Long:  `Send contract and raw transactions and query their results. There is no command for WithdrawFee actions, the node rejects them, fees are withdrawn by contracts with the WITHDRAWFEE instruction.`,
+	Args:  cobra.NoArgs,
+}
+
+var deployContractCmd = &cobra.Command{
+	Use:   "deploy <code hex>",
+	Short: "Deploy the contract code to the sender account.",
+	Long:  `Deploy the contract code to the sender account.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendAction(types.CreateContract, common.Name(walletCfg.from), parseHex(args[0]))
+	},
+}
+
+var deploy2ContractCmd = &cobra.Command{
+	Use:   "deploy2 <salt hex> <code hex>",
+	Short: "Deploy the contract code to the sub account of the sender derived from the salt and the code hash.",
+	Long:  `Deploy the contract code to the sub account of the sender derived from the salt and the code hash, the account name is known before deploying, see account_getCreate2ContractName.`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		from := common.Name(walletCfg.from)
+		payload := &accountmanager.Create2ContractAction{Salt: common.HexToHash(args[0]), Code: parseHex(args[1])}
+		sendAction(types.Create2Contract, accountmanager.Create2ContractName(from, payload.Salt, payload.Code), payload)
+	},
+}
+
+var callContractCmd = &cobra.Command{
+	Use:   "call <contract> <input hex>",
+	Short: "Call a contract with the input, sending --value of --asset.",
+	Long:  `Call a contract with the input, sending --value of --asset.`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		sendAction(types.CallContract, common.Name(args[0]), parseHex(args[1]))
+	},
+}
+
+var sendRawTxCmd = &cobra.Command{
+	Use:   "sendraw <rawtx hex>",
+	Short: "Submit a raw signed transaction, such as one printed by --offline.",
+	Long:  `Submit a raw signed transaction, such as one printed by --offline.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var hash common.Hash
+		clientCall(ipcEndpoint, &hash, "uni_sendRawTransaction", hexutil.Bytes(parseHex(args[0])))
+		printJSON(hash)
+	},
+}
+
+var getTxCmd = &cobra.Command{
+	Use:   "get <txhash>",
+	Short: "Returns the transaction for the given hash.",
+	Long:  `Returns the transaction for the given hash.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var result interface{}
+		clientCall(ipcEndpoint, &result, "uni_getTransactionByHash", common.HexToHash(args[0]))
+		printJSON(result)
+	},
+}
+
+var getReceiptCmd = &cobra.Command{
+	Use:   "receipt <txhash>",
+	Short: "Returns the receipt of the transaction for the given hash.",
+	Long:  `Returns the receipt of the transaction for the given hash.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var result interface{}
+		clientCall(ipcEndpoint, &result, "uni_getTransactionReceipt", common.HexToHash(args[0]))
+		printJSON(result)
+	},
+}
+
+func parseHex(arg string) []byte {
+	data, err := hexutil.Decode(arg)
+	if err != nil {
+		jww.ERROR.Printf("%v can not convert bytes, err: %v", arg, err)
+		os.Exit(1)
+	}
+	return data
+}
+
+func init() {
+	RootCmd.AddCommand(txCmd)
+	addWalletFlags(deployContractCmd, deploy2ContractCmd, callContractCmd)
+	txCmd.AddCommand(deployContractCmd, deploy2ContractCmd, callContractCmd, sendRawTxCmd, getTxCmd, getReceiptCmd)
+	addKeystoreFlags(txCmd)
+}
@@ -0,0 +1,274 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/keystore"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// walletConfig holds the flags shared by the commands which sign transactions.
+type walletConfig struct {
+	keystoreDir  string
+	passwordFile string
+	from         string
+	assetID      uint64
+	value        string
+	pubKey       string
+	authorIndex  uint64
+	gasLimit     uint64
+	gasPrice     string
+	gasAssetID   uint64
+	nonce        uint64
+	chainID      uint64
+	remark       string
+	dryRun       bool
+	offline      bool
+}
+
+var walletCfg = &walletConfig{}
+
+func defaultKeystoreDir() string {
+	return filepath.Join(defaultDataDir(), params.ClientIdentifier, "keystore")
+}
+
+// addKeystoreFlags adds the flags of the command groups which use the keystore
+// and the node.
+func addKeystoreFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path or HTTP URL of the node")
+	cmd.PersistentFlags().StringVar(&walletCfg.keystoreDir, "keystore", defaultKeystoreDir(), "directory of the encrypted key files")
+	cmd.PersistentFlags().StringVar(&walletCfg.passwordFile, "password", "", "file containing the passphrase of the key, prompted if empty")
+}
+
+// addWalletFlags adds the flags of the commands which build, sign and submit
+// transactions.
+func addWalletFlags(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		flags := cmd.Flags()
+		flags.StringVar(&walletCfg.from, "from", "", "name of the account sending the transaction")
+		flags.Uint64Var(&walletCfg.assetID, "asset", 0, "id of the asset of the action value")
+		flags.StringVar(&walletCfg.value, "value", "0", "value of the action")
+		flags.StringVar(&walletCfg.pubKey, "pubkey", "", "public key of the signing key in the keystore, the only key if empty")
+		flags.Uint64Var(&walletCfg.authorIndex, "authorindex", 0, "index of the signing key in the authors of the account")
+		flags.Uint64Var(&walletCfg.gasLimit, "gas", 200000, "gas limit of the action")
+		flags.StringVar(&walletCfg.gasPrice, "gasprice", "", "gas price of the transaction, suggested by the node if empty")
+		flags.Uint64Var(&walletCfg.gasAssetID, "gasasset", 0, "id of the asset paying the gas")
+		flags.Uint64Var(&walletCfg.nonce, "nonce", math.MaxUint64, "nonce of the sender, fetched from the node if not set")
+		flags.Uint64Var(&walletCfg.chainID, "chainid", 0, "chain id signed in the transaction, fetched from the node if not set")
+		flags.StringVar(&walletCfg.remark, "remark", "", "remark of the action")
+		flags.BoolVar(&walletCfg.dryRun, "dry-run", false, "simulate the signed transaction on the node instead of submitting it")
+		flags.BoolVar(&walletCfg.offline, "offline", false, "print the raw signed transaction without connecting to the node, requires --nonce, --chainid and --gasprice")
+	}
+}
+
+func openKeystore() *keystore.KeyStore {
+	return keystore.NewKeyStore(walletCfg.keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+}
+
+// readPassphrase returns the passphrase from the --password file, or prompts
+// for it on the terminal.
+func readPassphrase(prompt string, confirm bool) string {
+	if walletCfg.passwordFile != "" {
+		content, err := ioutil.ReadFile(walletCfg.passwordFile)
+		if err != nil {
+			jww.ERROR.Println("read password file failed", "err", err)
+			os.Exit(1)
+		}
+		return strings.TrimRight(strings.SplitN(string(content), "\n", 2)[0], "\r")
+	}
+	passphrase := promptPassphrase(prompt)
+	if confirm && promptPassphrase("Repeat passphrase: ") != passphrase {
+		jww.ERROR.Println("passphrases do not match")
+		os.Exit(1)
+	}
+	return passphrase
+}
+
+func promptPassphrase(prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+	if terminal.IsTerminal(int(os.Stdin.Fd())) {
+		passphrase, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			jww.ERROR.Println("read passphrase failed", "err", err)
+			os.Exit(1)
+		}
+		return string(passphrase)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		jww.ERROR.Println("read passphrase failed", "err", err)
+		os.Exit(1)
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+// unlockKey decrypts the signing key selected by --pubkey.
+func unlockKey() *keystore.Key {
+	ks := openKeystore()
+	var pubKey common.PubKey
+	if walletCfg.pubKey != "" {
+		if !common.IsHexPubKey(walletCfg.pubKey) {
+			jww.ERROR.Printf("invalid public key %v", walletCfg.pubKey)
+			os.Exit(1)
+		}
+		pubKey = common.HexToPubKey(walletCfg.pubKey)
+	} else {
+		keys, err := ks.Keys()
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		if len(keys) != 1 {
+			jww.ERROR.Printf("%d keys in keystore %v, select one with --pubkey", len(keys), ks.Dir())
+			os.Exit(1)
+		}
+		pubKey = keys[0]
+	}
+	key, err := ks.GetKey(pubKey, readPassphrase(fmt.Sprintf("Passphrase of %v: ", pubKey.String()), false))
+	if err != nil {
+		jww.ERROR.Println(err)
+		os.Exit(1)
+	}
+	return key
+}
+
+// actionRecipient returns the system account receiving actions of type t.
+func actionRecipient(t types.ActionType, cfg *params.ChainConfig) common.Name {
+	switch {
+	case t >= types.CreateAccount && t < types.IncreaseAsset:
+		return common.Name(cfg.AccountName)
+	case t >= types.IncreaseAsset && t < types.RegCandidate:
+		return common.Name(cfg.AssetName)
+	default:
+		return common.Name(cfg.DposName)
+	}
+}
+
+// walletChainConfig returns the chain config of the node, or the default one
+// when offline.
+func walletChainConfig() *params.ChainConfig {
+	if walletCfg.offline {
+		return params.DefaultChainconfig
+	}
+	cfg := &params.ChainConfig{}
+	clientCall(ipcEndpoint, cfg, "uni_getChainConfig")
+	return cfg
+}
+
+// sendSystemAction sends an action of type t with the --asset and --value to
+// the system account handling it.
+func sendSystemAction(t types.ActionType, payload interface{}) {
+	sendAction(t, actionRecipient(t, walletChainConfig()), payload)
+}
+
+// sendAction builds an action of type t from --from to to, signs it with the
+// key from the keystore, and submits, simulates or prints the transaction.
+func sendAction(t types.ActionType, to common.Name, payload interface{}) {
+	if walletCfg.from == "" {
+		jww.ERROR.Println("sender not set, use --from")
+		os.Exit(1)
+	}
+	from := common.Name(walletCfg.from)
+	value := parseBigInt(walletCfg.value)
+
+	var data []byte
+	switch p := payload.(type) {
+	case nil:
+	case []byte:
+		data = p
+	default:
+		var err error
+		if data, err = rlp.EncodeToBytes(payload); err != nil {
+			jww.ERROR.Println("encode payload failed", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	nonce, chainID, gasPrice := walletCfg.nonce, new(big.Int).SetUint64(walletCfg.chainID), new(big.Int)
+	if walletCfg.gasPrice != "" {
+		gasPrice = parseBigInt(walletCfg.gasPrice)
+	}
+	if walletCfg.offline {
+		if nonce == math.MaxUint64 || walletCfg.chainID == 0 || walletCfg.gasPrice == "" {
+			jww.ERROR.Println("offline signing requires --nonce, --chainid and --gasprice")
+			os.Exit(1)
+		}
+	} else {
+		if nonce == math.MaxUint64 {
+			clientCall(ipcEndpoint, &nonce, "account_getNonce", from)
+		}
+		if walletCfg.chainID == 0 {
+			chainID = walletChainConfig().ChainID
+		}
+		if walletCfg.gasPrice == "" {
+			clientCall(ipcEndpoint, gasPrice, "uni_gasPrice")
+		}
+	}
+
+	key := unlockKey()
+	action := types.NewAction(t, from, to, nonce, walletCfg.assetID, walletCfg.gasLimit, value, data, []byte(walletCfg.remark))
+	tx := types.NewTransaction(walletCfg.gasAssetID, gasPrice, action)
+	keyPair := types.MakeKeyPair(key.PrivateKey, []uint64{walletCfg.authorIndex})
+	if err := types.SignActionWithMultiKey(action, tx, types.NewSigner(chainID), 0, []*types.KeyPair{keyPair}); err != nil {
+		jww.ERROR.Println("sign transaction failed", "err", err)
+		os.Exit(1)
+	}
+	rawTx, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		jww.ERROR.Println("encode transaction failed", "err", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case walletCfg.offline:
+		jww.FEEDBACK.Println(hexutil.Encode(rawTx))
+	case walletCfg.dryRun:
+		var result interface{}
+		clientCall(ipcEndpoint, &result, "uni_simulateTransaction", hexutil.Bytes(rawTx), "latest")
+		printJSON(result)
+	default:
+		var hash common.Hash
+		clientCall(ipcEndpoint, &hash, "uni_sendRawTransaction", hexutil.Bytes(rawTx))
+		printJSON(hash)
+	}
+}
+
+// parsePayload decodes the JSON payload argument of an action into v.
+func parsePayload(arg string, v interface{}) {
+	if err := json.Unmarshal([]byte(arg), v); err != nil {
+		jww.ERROR.Printf("invalid payload %v, err: %v", arg, err)
+		os.Exit(1)
+	}
+}
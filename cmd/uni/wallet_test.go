@@ -0,0 +1,243 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+	"github.com/spf13/pflag"
+	"github.com/unichainplatform/unichain/accountmanager"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/keystore"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+const walletPassphrase = "wallet test"
+
+// newWalletKeystore returns a keystore directory with one key and the file
+// of its passphrase.
+func newWalletKeystore(t *testing.T) (string, string, common.PubKey) {
+	dir, err := ioutil.TempDir("", "uni-wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP).NewKey(walletPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	password := filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(password, []byte(walletPassphrase+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir, password, key.PubKey
+}
+
+// runWallet executes the command line with the flags of every command reset
+// to their defaults, it returns the output.
+func runWallet(t *testing.T, args ...string) string {
+	var reset func(cmd *cobra.Command)
+	reset = func(cmd *cobra.Command) {
+		for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags()} {
+			flags.VisitAll(func(flag *pflag.Flag) {
+				flag.Value.Set(flag.DefValue)
+				flag.Changed = false
+			})
+		}
+		for _, child := range cmd.Commands() {
+			reset(child)
+		}
+	}
+	reset(RootCmd)
+
+	// the feedback logger of jww writes to the stdout it was created with
+	out := new(bytes.Buffer)
+	feedback := jww.FEEDBACK
+	jww.FEEDBACK = jww.NewNotepad(jww.LevelError, jww.LevelWarn, out, ioutil.Discard, "", 0).FEEDBACK
+	defer func() { jww.FEEDBACK = feedback }()
+	RootCmd.SetArgs(args)
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("%v: %v", args, err)
+	}
+	return strings.TrimSpace(out.String())
+}
+
+func decodeRawTx(t *testing.T, raw string) (*types.Transaction, *types.Action) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(hexutil.MustDecode(raw), tx); err != nil {
+		t.Fatalf("decode raw tx %v: %v", raw, err)
+	}
+	if len(tx.GetActions()) != 1 {
+		t.Fatalf("actions: have %v, want 1", len(tx.GetActions()))
+	}
+	return tx, tx.GetActions()[0]
+}
+
+func TestWalletOffline(t *testing.T) {
+	dir, password, pubKey := newWalletKeystore(t)
+	defer os.RemoveAll(dir)
+
+	raw := runWallet(t, "asset", "transfer", "a123456789rcpt", "--keystore", dir, "--password", password,
+		"--from", "a123456789send", "--asset", "2", "--value", "1000", "--gas", "30000", "--remark", "memo",
+		"--nonce", "5", "--chainid", "3", "--gasprice", "100", "--gasasset", "1", "--offline")
+	tx, action := decodeRawTx(t, raw)
+
+	if tx.GasAssetID() != 1 || tx.GasPrice().Int64() != 100 {
+		t.Fatalf("tx gas: have asset %v price %v", tx.GasAssetID(), tx.GasPrice())
+	}
+	if action.Type() != types.Transfer || action.Sender() != "a123456789send" || action.Recipient() != "a123456789rcpt" ||
+		action.Nonce() != 5 || action.AssetID() != 2 || action.Value().Int64() != 1000 || action.Gas() != 30000 ||
+		string(action.Remark()) != "memo" {
+		t.Fatalf("action: have %+v", action.NewRPCAction(0))
+	}
+	pubKeys, err := types.RecoverMultiKey(types.NewSigner(big.NewInt(3)), action, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pubKeys) != 1 || pubKeys[0] != pubKey {
+		t.Fatalf("signer: have %v, want %v", pubKeys, pubKey)
+	}
+}
+
+func TestWalletPayloads(t *testing.T) {
+	dir, password, _ := newWalletKeystore(t)
+	defer os.RemoveAll(dir)
+	cfg := params.DefaultChainconfig
+	from := common.Name("a123456789send")
+	code := []byte{0x60, 0x00}
+	salt := common.BytesToHash([]byte{1})
+
+	for _, test := range []struct {
+		args    []string
+		typ     types.ActionType
+		to      common.Name
+		payload interface{}
+		want    interface{}
+	}{
+		{
+			args:    []string{"account", "lockedtransfer", `{"to":"a123456789rcpt","start":10,"cliff":20,"end":30}`},
+			typ:     types.LockedTransfer,
+			to:      common.Name(cfg.AccountName),
+			payload: new(accountmanager.LockedTransferAction),
+			want:    &accountmanager.LockedTransferAction{To: "a123456789rcpt", Start: 10, Cliff: 20, End: 30},
+		},
+		{
+			args:    []string{"asset", "pause", "7"},
+			typ:     types.PauseAsset,
+			to:      common.Name(cfg.AssetName),
+			payload: new(accountmanager.PauseAsset),
+			want:    &accountmanager.PauseAsset{AssetID: 7},
+		},
+		{
+			args:    []string{"dpos", "vote", "a123456789cand", "100"},
+			typ:     types.VoteCandidate,
+			to:      common.Name(cfg.DposName),
+			payload: new(dpos.VoteCandidate),
+			want:    &dpos.VoteCandidate{Candidate: "a123456789cand", Stake: big.NewInt(100)},
+		},
+		{
+			args:    []string{"tx", "deploy2", hexutil.Encode(salt[:]), hexutil.Encode(code)},
+			typ:     types.Create2Contract,
+			to:      accountmanager.Create2ContractName(from, salt, code),
+			payload: new(accountmanager.Create2ContractAction),
+			want:    &accountmanager.Create2ContractAction{Salt: salt, Code: code},
+		},
+	} {
+		args := append(test.args, "--keystore", dir, "--password", password, "--from", from.String(),
+			"--nonce", "0", "--chainid", "1", "--gasprice", "1", "--offline")
+		_, action := decodeRawTx(t, runWallet(t, args...))
+		if action.Type() != test.typ || action.Recipient() != test.to {
+			t.Fatalf("%v: have type %v to %v, want %v to %v", test.args, action.Type(), action.Recipient(), test.typ, test.to)
+		}
+		if err := rlp.DecodeBytes(action.Data(), test.payload); err != nil {
+			t.Fatalf("%v: decode payload: %v", test.args, err)
+		}
+		want, _ := rlp.EncodeToBytes(test.want)
+		if have, _ := rlp.EncodeToBytes(test.payload); !bytes.Equal(have, want) {
+			t.Fatalf("%v: payload have %+v, want %+v", test.args, test.payload, test.want)
+		}
+	}
+}
+
+// MockNode serves the node values a wallet command reads and records the
+// raw transactions submitted and simulated.
+type MockNode struct {
+	sent, simulated []hexutil.Bytes
+}
+
+func (n *MockNode) GetChainConfig() *params.ChainConfig { return params.DefaultChainconfig }
+
+func (n *MockNode) GasPrice() *big.Int { return big.NewInt(10) }
+
+func (n *MockNode) GetNonce(name common.Name) uint64 { return 9 }
+
+func (n *MockNode) SendRawTransaction(raw hexutil.Bytes) common.Hash {
+	n.sent = append(n.sent, raw)
+	return crypto.Keccak256Hash(raw)
+}
+
+func (n *MockNode) SimulateTransaction(raw hexutil.Bytes, blockNr string) map[string]interface{} {
+	n.simulated = append(n.simulated, raw)
+	return map[string]interface{}{"stateDiff": map[string]interface{}{}}
+}
+
+func TestWalletDryRun(t *testing.T) {
+	dir, password, _ := newWalletKeystore(t)
+	defer os.RemoveAll(dir)
+	node := new(MockNode)
+	server := rpc.NewServer()
+	for _, namespace := range []string{"uni", "account"} {
+		if err := server.RegisterName(namespace, node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	args := []string{"asset", "transfer", "a123456789rcpt", "--keystore", dir, "--password", password,
+		"--from", "a123456789send", "--value", "1", "-i", httpServer.URL}
+	runWallet(t, append(args, "--dry-run")...)
+	if len(node.simulated) != 1 || len(node.sent) != 0 {
+		t.Fatalf("dry run: simulated %v sent %v, want 1 and 0", len(node.simulated), len(node.sent))
+	}
+	tx, action := decodeRawTx(t, node.simulated[0].String())
+	if action.Nonce() != 9 || tx.GasPrice().Int64() != 10 {
+		t.Fatalf("node values: have nonce %v price %v, want 9 and 10", action.Nonce(), tx.GasPrice())
+	}
+	if _, err := types.RecoverMultiKey(types.NewSigner(params.DefaultChainconfig.ChainID), action, tx); err != nil {
+		t.Fatalf("signed with the chain id of the node: %v", err)
+	}
+
+	runWallet(t, args...)
+	if len(node.simulated) != 1 || len(node.sent) != 1 || !bytes.Equal(node.sent[0], node.simulated[0]) {
+		t.Fatalf("send: simulated %v sent %v, want the dry run tx sent", len(node.simulated), len(node.sent))
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/unichainplatform/unichain/cmd/utils"
+	"github.com/unichainplatform/unichain/utils/abi/bind"
+)
+
+type abigenConfig struct {
+	abiFile string
+	binFile string
+	typ     string
+	pkg     string
+	outFile string
+}
+
+var cfg = &abigenConfig{
+	pkg: "main",
+}
+
+// RootCmd represents the base command when called without any subcommands
+var RootCmd = &cobra.Command{
+	Use:   "uniabigen",
+	Short: "uniabigen generates Go bindings of unichain contracts",
+	Long:  `uniabigen generates Go bindings of unichain contracts from their ABI, calling, transacting and watching the contracts through the sdk`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := run(); err != nil {
+			fmt.Fprintln(os.Stderr, "uniabigen failed:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func run() error {
+	if cfg.abiFile == "" {
+		return fmt.Errorf("abi file is required")
+	}
+	input, err := ioutil.ReadFile(cfg.abiFile)
+	if err != nil {
+		return err
+	}
+	var bytecode []byte
+	if cfg.binFile != "" {
+		if bytecode, err = ioutil.ReadFile(cfg.binFile); err != nil {
+			return err
+		}
+	}
+	typ := cfg.typ
+	if typ == "" {
+		typ = strings.TrimSuffix(filepath.Base(cfg.abiFile), filepath.Ext(cfg.abiFile))
+	}
+
+	code, err := bind.Bind([]string{typ}, []string{string(input)}, []string{string(bytecode)}, cfg.pkg)
+	if err != nil {
+		return err
+	}
+	if cfg.outFile == "" {
+		fmt.Print(code)
+		return nil
+	}
+	return ioutil.WriteFile(cfg.outFile, []byte(code), 0600)
+}
+
+func init() {
+	RootCmd.AddCommand(utils.VersionCmd)
+	flags := RootCmd.Flags()
+	flags.StringVar(&cfg.abiFile, "abi", cfg.abiFile, "Path of the contract ABI JSON to generate the binding of")
+	flags.StringVar(&cfg.binFile, "bin", cfg.binFile, "Path of the contract hex bytecode, no deploy method if empty")
+	flags.StringVar(&cfg.typ, "type", cfg.typ, "Go type name of the binding, the ABI file name if empty")
+	flags.StringVar(&cfg.pkg, "pkg", cfg.pkg, "Go package name of the generated file")
+	flags.StringVar(&cfg.outFile, "out", cfg.outFile, "Output file of the generated binding, stdout if empty")
+}
+
+// Execute adds all child commands to the root command sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := RootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(-1)
+	}
+}
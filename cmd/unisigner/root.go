@@ -0,0 +1,171 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/spf13/cobra"
+	"github.com/unichainplatform/unichain/cmd/utils"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/keystore"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/signer"
+)
+
+type signerConfig struct {
+	dataDir      string
+	keystoreDir  string
+	passwordFile string
+	pubKeys      []string
+	ipcPath      string
+	httpAddr     string
+}
+
+var cfg = &signerConfig{
+	dataDir: ".",
+	ipcPath: "unisigner.ipc",
+}
+
+// RootCmd represents the base command when called without any subcommands
+var RootCmd = &cobra.Command{
+	Use:   "unisigner",
+	Short: "unisigner is a remote signer of unichain block production keys",
+	Long:  `unisigner is a remote signer of unichain block production keys, it refuses to sign two headers for the same slot or an earlier one`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := run(); err != nil {
+			log.Error("unisigner start failed", "error", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func run() error {
+	keys, err := loadKeys()
+	if err != nil {
+		return err
+	}
+	s, err := signer.New(keys, filepath.Join(cfg.dataDir, "highwatermark.json"))
+	if err != nil {
+		return err
+	}
+	for _, pubKey := range s.PubKeys() {
+		log.Info("Signing key loaded", "pubKey", pubKey.String())
+	}
+
+	ipcListener, ipcHandler, err := rpc.StartIPCEndpoint(resolvePath(cfg.ipcPath), s.APIs())
+	if err != nil {
+		return err
+	}
+	defer ipcHandler.Stop()
+	defer ipcListener.Close()
+	log.Info("IPC endpoint opened", "url", resolvePath(cfg.ipcPath))
+
+	if cfg.httpAddr != "" {
+		httpListener, httpHandler, err := rpc.StartHTTPEndpoint(cfg.httpAddr, s.APIs(), []string{"signer"}, nil, []string{"localhost"})
+		if err != nil {
+			return err
+		}
+		defer httpHandler.Stop()
+		defer httpListener.Close()
+		log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", cfg.httpAddr))
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	<-sigc
+	log.Info("Got interrupt, shutting down...")
+	return nil
+}
+
+// loadKeys decrypts the keys of the keystore, all of them unless public keys
+// are given, with the passphrase of the password file.
+func loadKeys() ([]*ecdsa.PrivateKey, error) {
+	if cfg.keystoreDir == "" {
+		return nil, fmt.Errorf("keystore directory is required")
+	}
+	if cfg.passwordFile == "" {
+		return nil, fmt.Errorf("password file is required")
+	}
+	text, err := ioutil.ReadFile(cfg.passwordFile)
+	if err != nil {
+		return nil, err
+	}
+	passphrase := strings.TrimRight(strings.Split(string(text), "\n")[0], "\r")
+
+	ks := keystore.NewKeyStore(cfg.keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	var pubKeys []common.PubKey
+	if len(cfg.pubKeys) == 0 {
+		if pubKeys, err = ks.Keys(); err != nil {
+			return nil, err
+		}
+	}
+	for _, pub := range cfg.pubKeys {
+		pubKeys = append(pubKeys, common.HexToPubKey(pub))
+	}
+	if len(pubKeys) == 0 {
+		return nil, fmt.Errorf("no keys in keystore %v", cfg.keystoreDir)
+	}
+
+	keys := make([]*ecdsa.PrivateKey, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		key, err := ks.GetKey(pubKey, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unlock key %v: %v", pubKey.String(), err)
+		}
+		keys = append(keys, key.PrivateKey)
+	}
+	return keys, nil
+}
+
+func resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cfg.dataDir, path)
+}
+
+func init() {
+	RootCmd.AddCommand(utils.VersionCmd)
+	flags := RootCmd.Flags()
+	flags.StringVarP(&cfg.dataDir, "datadir", "d", cfg.dataDir, "Data directory for the high-water mark and the IPC endpoint")
+	flags.StringVar(&cfg.keystoreDir, "keystore", cfg.keystoreDir, "Directory of the encrypted key files")
+	flags.StringVar(&cfg.passwordFile, "password", cfg.passwordFile, "File containing the passphrase of the keys")
+	flags.StringSliceVar(&cfg.pubKeys, "pubkeys", cfg.pubKeys, "Hex of public keys of the keystore keys to sign with, all keys if empty")
+	flags.StringVar(&cfg.ipcPath, "ipcpath", cfg.ipcPath, "Filename for IPC socket/pipe within the datadir (explicit paths escape it)")
+	flags.StringVar(&cfg.httpAddr, "http", cfg.httpAddr, "HTTP endpoint address of the signer, disabled if empty")
+	utils.DefaultLogConfig().Setup()
+}
+
+// Execute adds all child commands to the root command sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := RootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(-1)
+	}
+}
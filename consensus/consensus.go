@@ -122,6 +122,9 @@ type IEngine interface {
 	// CalcBFTIrreversible get chain rreversible number
 	CalcBFTIrreversible() uint64
 
+	// ChainConfigAt get chain config with the parameters changed by governance in effect at timestamp
+	ChainConfigAt(config *params.ChainConfig, state *state.StateDB, timestamp uint64) (*params.ChainConfig, error)
+
 	IAPI
 
 	IValidator
@@ -623,6 +623,140 @@ func (api *API) SnapShotTime(epoch uint64) (interface{}, error) {
 	return res, nil
 }
 
+// Evidences get the double sign evidences detected, neither reported nor expired
+func (api *API) Evidences() ([]*RPCEvidence, error) {
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	epoch, err := sys.GetLastestEpoch()
+	if err != nil {
+		return nil, err
+	}
+	evs := []*RPCEvidence{}
+	for _, ev := range api.dpos.Evidences() {
+		if sys.config.epoch(ev.HeaderA.Time.Uint64())+1 < epoch {
+			continue
+		}
+		if reported, err := sys.HasEvidence(ev.Hash()); err != nil {
+			return nil, err
+		} else if !reported {
+			evs = append(evs, ev.NewRPCEvidence())
+		}
+	}
+	return evs, nil
+}
+
+// Evidence get the double sign evidence detected by hash
+func (api *API) Evidence(hash common.Hash) (*RPCEvidence, error) {
+	ev := api.dpos.GetEvidence(hash)
+	if ev == nil {
+		return nil, fmt.Errorf("not found evidence %v", hash.Hex())
+	}
+	return ev.NewRPCEvidence(), nil
+}
+
+// PendingRewards get the rewards shared by candidates to voter, claimable and still growing in current epoch,
+// a claim settles at most maxRewardEpochs of the claimable vote epochs
+func (api *API) PendingRewards(voter string) (interface{}, error) {
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	epoch := api.dpos.config.epoch(api.chain.CurrentHeader().Time.Uint64())
+	claimable, remain, err := sys.settleRewards(epoch, voter, 0)
+	if err != nil {
+		return nil, err
+	}
+	unsettled := big.NewInt(0)
+	for _, e := range remain {
+		reward, err := sys.voteReward(e, voter)
+		if err != nil {
+			return nil, err
+		}
+		unsettled = new(big.Int).Add(unsettled, reward)
+	}
+	ret := map[string]interface{}{}
+	ret["voter"] = voter
+	ret["epoch"] = epoch
+	ret["claimable"] = claimable
+	ret["unsettled"] = unsettled
+	return ret, nil
+}
+
+// Commission get the commission rate in percent the candidate keep of its rewards
+func (api *API) Commission(candidate string) (uint64, error) {
+	sys, err := api.system()
+	if err != nil {
+		return 0, err
+	}
+	return sys.GetCommission(candidate)
+}
+
+// ProducerReport get the blocks expected, produced and missed of candidates in epoch, 0 for current epoch
+func (api *API) ProducerReport(epoch uint64) (*ProducerReport, error) {
+	state, err := api.chain.StateAt(api.chain.CurrentHeader().Root)
+	if err != nil {
+		return nil, err
+	}
+	return api.dpos.ProducerReport(api.chain, state, epoch)
+}
+
+// Proposals get the governance proposals
+func (api *API) Proposals() ([]*Proposal, error) {
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	count, err := sys.GetProposalCount()
+	if err != nil {
+		return nil, err
+	}
+	proposals := []*Proposal{}
+	for id := uint64(0); id < count; id++ {
+		proposal, err := sys.GetProposal(id)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, proposal)
+	}
+	return proposals, nil
+}
+
+// Proposal get the governance proposal by id
+func (api *API) Proposal(id uint64) (*Proposal, error) {
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := sys.GetProposal(id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil {
+		return nil, fmt.Errorf("not found proposal %v", id)
+	}
+	return proposal, nil
+}
+
+// ProposalVote get the vote of voter on the governance proposal
+func (api *API) ProposalVote(id uint64, voter string) (*ProposalVote, error) {
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	return sys.GetProposalVote(id, voter)
+}
+
+// GovernedParams get the chain parameters changed by passed proposals and the epochs they take effect
+func (api *API) GovernedParams() ([]*GovernedParam, error) {
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	return sys.GovernedParams()
+}
+
 func (api *API) epoch(number uint64) (uint64, error) {
 	header := api.chain.GetHeaderByNumber(number)
 	if header == nil {
@@ -25,6 +25,10 @@ import (
 	"github.com/unichainplatform/unichain/params"
 )
 
+// DefaultSlashRate percent of stake slashed for double signing, used while
+// SlashRate is not set
+const DefaultSlashRate = uint64(10)
+
 // DefaultConfig configures
 var DefaultConfig = &Config{
 	MaxURLLen:                     512,
@@ -71,6 +75,7 @@ type Config struct {
 	SystemURL                     string   `json:"systemURL"`
 	ExtraBlockReward              *big.Int `json:"extraBlockReward"`
 	BlockReward                   *big.Int `json:"blockReward"`
+	SlashRate                     uint64   `json:"slashRate,omitempty"` // percent of stake slashed for double signing
 	Decimals                      uint64   `json:"decimals"`
 	AssetID                       uint64   `json:"assetID"`
 	ReferenceTime                 uint64   `json:"referenceTime"`
@@ -107,6 +112,19 @@ func (cfg *Config) blockReward() *big.Int {
 	return new(big.Int).Mul(cfg.BlockReward, cfg.decimals())
 }
 
+// producedReward the reward of a produced block with the extra rewards of extraCounter blocks, before the voters share
+func (cfg *Config) producedReward(extraCounter int64) *big.Int {
+	extraReward := new(big.Int).Mul(cfg.extraBlockReward(), big.NewInt(extraCounter))
+	return new(big.Int).Add(cfg.blockReward(), extraReward)
+}
+
+func (cfg *Config) slashRate() uint64 {
+	if cfg.SlashRate == 0 {
+		return DefaultSlashRate
+	}
+	return cfg.SlashRate
+}
+
 func (cfg *Config) blockInterval() uint64 {
 	if blockInter := cfg.blockInter.Load(); blockInter != nil {
 		return blockInter.(uint64)
@@ -54,12 +54,34 @@ type IDB interface {
 	SetTakeOver(uint64) error
 	GetTakeOver() (uint64, error)
 
+	SetEvidence(common.Hash) error
+	HasEvidence(common.Hash) (bool, error)
+
+	SetCommission(string, uint64) error
+	GetCommission(string) (uint64, error)
+	SetRewardPool(uint64, *RewardPool) error
+	GetRewardPool(uint64, string) (*RewardPool, error)
+	SetRewardEpochs(string, []uint64) error
+	GetRewardEpochs(string) ([]uint64, error)
+
+	SetProposalCount(uint64) error
+	GetProposalCount() (uint64, error)
+	SetProposal(*Proposal) error
+	GetProposal(uint64) (*Proposal, error)
+	SetProposalVote(*ProposalVote) error
+	GetProposalVote(uint64, string) (*ProposalVote, error)
+	SetGovernedIndex(*GovernedIndex) error
+	GetGovernedIndex() (*GovernedIndex, error)
+	SetGovernedParam(string, []*GovernedParam) error
+	GetGovernedParam(string) ([]*GovernedParam, error)
+
 	Undelegate(string, *big.Int) (*types.Action, error)
 	IncAsset2Acct(string, string, *big.Int, uint64) (*types.Action, error)
 	GetBalanceByTime(name string, timestamp uint64) (*big.Int, error)
 	GetCandidateInfoByTime(epoch uint64, name string, timestamp uint64) (*CandidateInfo, error)
 
 	CanMine(name string, pub []byte) error
+	CanMineAt(name string, pub []byte, timestamp uint64) error
 }
 
 // CandidateType candidate status
@@ -162,6 +184,16 @@ func (candidateInfo *CandidateInfo) invalid() bool {
 	return candidateInfo.Type != Normal
 }
 
+// MaxCommission commission rate in percent, the candidate keep all rewards
+const MaxCommission = uint64(100)
+
+// RewardPool rewards of candidate shared by the voters of epoch
+type RewardPool struct {
+	Candidate string   `json:"candidate"`
+	Quantity  *big.Int `json:"quantity"`
+	Votes     *big.Int `json:"votes"` // stake voted to candidate in epoch
+}
+
 // VoterInfo info
 type VoterInfo struct {
 	Epoch               uint64   `json:"epoch"`
@@ -52,7 +52,7 @@ var (
 	// ErrSystemTakeOver system taking over
 	ErrSystemTakeOver  = errors.New("system account take over")
 	errUnknownBlock    = errors.New("unknown block")
-	extraSeal          = 65
+	extraSeal          = ExtraSeal
 	timeOfGenesisBlock int64
 )
 
@@ -101,6 +101,21 @@ func (s *stateDB) IsValidSign(name string, pubkey []byte) error {
 	}
 	return accountDB.IsValidSign(common.StrToName(name), common.BytesToPubKey(pubkey))
 }
+func (s *stateDB) IsValidSignByTime(name string, pubkey []byte, timestamp uint64) error {
+	accountDB, err := accountmanager.NewAccountManager(s.state)
+	if err != nil {
+		return err
+	}
+	return accountDB.IsValidSignByTime(common.StrToName(name), common.BytesToPubKey(pubkey), timestamp)
+}
+func (s *stateDB) GetSnapshotTime(timestamp uint64) (uint64, error) {
+	snapshotManager := snapshot.NewSnapshotManager(s.state)
+	time, err := snapshotManager.GetLastSnapshotTime()
+	for err == nil && time > timestamp {
+		time, err = snapshotManager.GetPrevSnapshotTime(time)
+	}
+	return time, err
+}
 func (s *stateDB) GetBalanceByTime(name string, timestamp uint64) (*big.Int, error) {
 	accountDB, err := accountmanager.NewAccountManager(s.state)
 	if err != nil {
@@ -145,8 +160,8 @@ func Genesis(cfg *Config, state *state.StateDB, timestamp uint64, number uint64)
 	return nil
 }
 
-// SignFn signature function
-type SignFn func([]byte, *state.StateDB) ([]byte, error)
+// SignFn signature function, content is the seal hash of header
+type SignFn func(content []byte, header *types.Header, state *state.StateDB) ([]byte, error)
 
 // Dpos dpos engine
 type Dpos struct {
@@ -158,14 +173,21 @@ type Dpos struct {
 
 	// cache
 	bftIrreversibles *lru.Cache
+	governed         *lru.Cache
+
+	evidences *evidencePool
+	delays    *delayTracker
 }
 
 // New creates a DPOS consensus engine
 func New(config *Config, chain consensus.IChainReader) *Dpos {
 	dpos := &Dpos{
-		config: config,
+		config:    config,
+		evidences: newEvidencePool(),
+		delays:    newDelayTracker(),
 	}
 	dpos.bftIrreversibles, _ = lru.New(int(config.CandidateScheduleSize))
+	dpos.governed, _ = lru.New(governedCacheSize)
 	return dpos
 }
 
@@ -209,10 +231,14 @@ func (dpos *Dpos) Prepare(chain consensus.IChainReader, header *types.Header, tx
 
 func (dpos *Dpos) prepare0(chain consensus.IChainReader, header *types.Header, txs []*types.Transaction, receipts []*types.Receipt, state *state.StateDB) error {
 	header.Extra = append(header.Extra, make([]byte, extraSeal)...)
-	sys := NewSystem(state, dpos.config)
 	parent := chain.GetHeaderByHash(header.ParentHash)
 	pepoch := dpos.config.epoch(parent.Time.Uint64())
 	epoch := dpos.config.epoch(header.Time.Uint64())
+	cfg, err := dpos.configAt(state, epoch)
+	if err != nil {
+		return err
+	}
+	sys := NewSystem(state, cfg)
 	if header.Number.Uint64() != 1 {
 		gstate, err := sys.GetState(pepoch)
 		if err != nil {
@@ -357,10 +383,14 @@ func (dpos *Dpos) prepare0(chain consensus.IChainReader, header *types.Header, t
 func (dpos *Dpos) prepare1(chain consensus.IChainReader, header *types.Header, txs []*types.Transaction, receipts []*types.Receipt, state *state.StateDB) error {
 	header.Extra = append(header.Extra, make([]byte, extraSeal)...)
 
-	sys := NewSystem(state, dpos.config)
 	parent := chain.GetHeaderByHash(header.ParentHash)
 	pepoch := dpos.config.epoch(parent.Time.Uint64())
 	epoch := dpos.config.epoch(header.Time.Uint64())
+	cfg, err := dpos.configAt(state, epoch)
+	if err != nil {
+		return err
+	}
+	sys := NewSystem(state, cfg)
 
 	gstate, err := sys.GetState(pepoch)
 	if err != nil {
@@ -527,11 +557,16 @@ func (dpos *Dpos) Finalize(chain consensus.IChainReader, header *types.Header, t
 }
 
 func (dpos *Dpos) finalize0(chain consensus.IChainReader, header *types.Header, txs []*types.Transaction, receipts []*types.Receipt, state *state.StateDB) (*types.Block, error) {
-	sys := NewSystem(state, dpos.config)
+	cfg, err := dpos.configAt(state, dpos.config.epoch(header.Time.Uint64()))
+	if err != nil {
+		return nil, err
+	}
+	sys := NewSystem(state, cfg)
 	counter := int64(0)
-	extraReward := new(big.Int).Mul(dpos.config.extraBlockReward(), big.NewInt(counter))
-	reward := new(big.Int).Add(dpos.config.blockReward(), extraReward)
-	sys.IncAsset2Acct(dpos.config.SystemName, header.Coinbase.String(), reward, header.CurForkID())
+	reward := sys.config.producedReward(counter)
+	if err := dpos.reward(sys, header, reward); err != nil {
+		return nil, err
+	}
 
 	blk := types.NewBlock(header, txs, receipts)
 
@@ -683,13 +718,18 @@ func (dpos *Dpos) finalize0(chain consensus.IChainReader, header *types.Header,
 
 func (dpos *Dpos) finalize1(chain consensus.IChainReader, header *types.Header, txs []*types.Transaction, receipts []*types.Receipt, state *state.StateDB) (*types.Block, error) {
 	parent := chain.GetHeaderByHash(header.ParentHash)
-	sys := NewSystem(state, dpos.config)
+	cfg, err := dpos.configAt(state, dpos.config.epoch(header.Time.Uint64()))
+	if err != nil {
+		return nil, err
+	}
+	sys := NewSystem(state, cfg)
 
 	// reward
 	extraCounter := int64(0)
-	extraReward := new(big.Int).Mul(dpos.config.extraBlockReward(), big.NewInt(extraCounter))
-	reward := new(big.Int).Add(dpos.config.blockReward(), extraReward)
-	sys.IncAsset2Acct(dpos.config.SystemName, header.Coinbase.String(), reward, header.CurForkID())
+	reward := sys.config.producedReward(extraCounter)
+	if err := dpos.reward(sys, header, reward); err != nil {
+		return nil, err
+	}
 
 	blk := types.NewBlock(header, txs, receipts)
 	// first hard fork at a specific number
@@ -721,6 +761,19 @@ func (dpos *Dpos) finalize1(chain consensus.IChainReader, header *types.Header,
 	return blk, nil
 }
 
+// reward pay the block reward to the coinbase, since ForkID5 after the share of its voters
+func (dpos *Dpos) reward(sys *System, header *types.Header, reward *big.Int) error {
+	if header.CurForkID() >= params.ForkID5 {
+		share, err := sys.shareReward(dpos.config.epoch(header.Time.Uint64()), header.Coinbase.String(), reward, header.CurForkID())
+		if err != nil {
+			return err
+		}
+		reward = share
+	}
+	sys.IncAsset2Acct(dpos.config.SystemName, header.Coinbase.String(), reward, header.CurForkID())
+	return nil
+}
+
 // Seal generates a new block for the given input block with the local miner's seal place on top.
 func (dpos *Dpos) Seal(chain consensus.IChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
 	header := block.Header()
@@ -734,7 +787,7 @@ func (dpos *Dpos) Seal(chain consensus.IChainReader, block *types.Block, stop <-
 	if err != nil {
 		return nil, err
 	}
-	sighash, err := dpos.signFn(signHash(header, chain.Config().ChainID.Bytes()).Bytes(), state)
+	sighash, err := dpos.signFn(signHash(header, chain.Config().ChainID.Bytes()).Bytes(), header, state)
 	if err != nil {
 		return nil, err
 	}
@@ -768,6 +821,10 @@ func (dpos *Dpos) VerifySeal(chain consensus.IChainReader, header *types.Header)
 		return err
 	}
 
+	if header.CurForkID() >= params.ForkID5 {
+		dpos.checkDoubleSign(header, chain.Config().ChainID)
+	}
+	dpos.recordDelay(header)
 	return nil
 }
 
@@ -1305,12 +1362,27 @@ func ecrecover(header *types.Header, extra []byte) ([]byte, error) {
 	return pubkey, nil
 }
 
+// signHash since ForkID5 covers the chain id given by extra, so a header
+// signed for another chain is not valid here.
 func signHash(header *types.Header, extra []byte) (hash common.Hash) {
 	theader := types.CopyHeader(header)
 	theader.Extra = theader.Extra[:len(theader.Extra)-extraSeal]
+	if header.CurForkID() >= params.ForkID5 {
+		return common.BytesToHash(crypto.Keccak256(theader.Hash().Bytes(), extra))
+	}
 	return theader.Hash()
 }
 
+// ExtraSeal is the length of the producer signature at the end of the extra-data
+// of a header.
+const ExtraSeal = 65
+
+// SealHash returns the hash of header signed by its producer on the chain of
+// chainID.
+func SealHash(header *types.Header, chainID *big.Int) common.Hash {
+	return signHash(header, chainID.Bytes())
+}
+
 // UInt64Slice attaches the methods of sort.Interface to []uint64, sorting in increasing order.
 type UInt64Slice []uint64
 
@@ -0,0 +1,305 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+	router "github.com/unichainplatform/unichain/event"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+const (
+	sealedHeadersCacheSize = 1024
+	evidencesCacheSize     = 128
+)
+
+var (
+	errEvidenceMissingHeader = errors.New("evidence missing header")
+	errEvidenceNotConflict   = errors.New("evidence headers not conflict")
+	errEvidenceSigner        = errors.New("evidence headers signed by different keys")
+	errEvidenceFork          = errors.New("evidence headers before ForkID5 not signed for the chain")
+	errEvidenceExpired       = errors.New("evidence not of the current or the previous epoch")
+)
+
+// Evidence two different headers signed by the same candidate for the same slot
+type Evidence struct {
+	HeaderA *types.Header
+	HeaderB *types.Header
+}
+
+// NewEvidence create evidence of the conflicting headers
+func NewEvidence(a, b *types.Header) *Evidence {
+	if bytes.Compare(a.Hash().Bytes(), b.Hash().Bytes()) > 0 {
+		a, b = b, a
+	}
+	return &Evidence{HeaderA: types.CopyHeader(a), HeaderB: types.CopyHeader(b)}
+}
+
+// Hash return the hash of evidence, independent of the headers order
+func (ev *Evidence) Hash() common.Hash {
+	a, b := ev.HeaderA.Hash(), ev.HeaderB.Hash()
+	if bytes.Compare(a.Bytes(), b.Bytes()) > 0 {
+		a, b = b, a
+	}
+	return common.BytesToHash(crypto.Keccak256(a.Bytes(), b.Bytes()))
+}
+
+// Candidate return the candidate who signed the headers
+func (ev *Evidence) Candidate() string {
+	return ev.HeaderA.Coinbase.String()
+}
+
+// Verify check the headers conflict and return the public key signed both for
+// the chain of chainID
+func (ev *Evidence) Verify(chainID *big.Int) ([]byte, error) {
+	if ev.HeaderA == nil || ev.HeaderB == nil {
+		return nil, errEvidenceMissingHeader
+	}
+	if ev.HeaderA.Coinbase != ev.HeaderB.Coinbase ||
+		ev.HeaderA.Time.Cmp(ev.HeaderB.Time) != 0 ||
+		ev.HeaderA.Hash() == ev.HeaderB.Hash() {
+		return nil, errEvidenceNotConflict
+	}
+	// the signatures of earlier headers don't cover the chain id
+	if ev.HeaderA.CurForkID() < params.ForkID5 || ev.HeaderB.CurForkID() < params.ForkID5 {
+		return nil, errEvidenceFork
+	}
+	pubA, err := ecrecover(ev.HeaderA, chainID.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	pubB, err := ecrecover(ev.HeaderB, chainID.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(pubA, pubB) {
+		return nil, errEvidenceSigner
+	}
+	return pubA, nil
+}
+
+// RPCEvidence evidence info for the RPC interface
+type RPCEvidence struct {
+	Hash      common.Hash `json:"hash"`
+	Candidate string      `json:"candidate"`
+	Number    uint64      `json:"number"`
+	Timestamp uint64      `json:"timestamp"`
+	HeaderA   common.Hash `json:"headerA"`
+	HeaderB   common.Hash `json:"headerB"`
+	Raw       string      `json:"raw"`
+}
+
+// NewRPCEvidence returns evidence info for the RPC interface
+func (ev *Evidence) NewRPCEvidence() *RPCEvidence {
+	raw, _ := rlp.EncodeToBytes(ev)
+	return &RPCEvidence{
+		Hash:      ev.Hash(),
+		Candidate: ev.Candidate(),
+		Number:    ev.HeaderA.Number.Uint64(),
+		Timestamp: ev.HeaderA.Time.Uint64(),
+		HeaderA:   ev.HeaderA.Hash(),
+		HeaderB:   ev.HeaderB.Hash(),
+		Raw:       common.Bytes2Hex(raw),
+	}
+}
+
+// evidencePool remember the recent sealed headers and the double sign evidences detected
+type evidencePool struct {
+	mu        sync.Mutex
+	headers   *lru.Cache // coinbase and slot -> sealed header
+	evidences *lru.Cache // evidence hash -> evidence
+}
+
+func newEvidencePool() *evidencePool {
+	headers, _ := lru.New(sealedHeadersCacheSize)
+	evidences, _ := lru.New(evidencesCacheSize)
+	return &evidencePool{
+		headers:   headers,
+		evidences: evidences,
+	}
+}
+
+// check remember the header and return the evidence if conflict with the remembered
+func (pool *evidencePool) check(header *types.Header) *Evidence {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	key := fmt.Sprintf("%s:%s", header.Coinbase.String(), header.Time.String())
+	val, ok := pool.headers.Get(key)
+	if !ok {
+		pool.headers.Add(key, types.CopyHeader(header))
+		return nil
+	}
+	if prev := val.(*types.Header); prev.Hash() != header.Hash() {
+		return NewEvidence(prev, header)
+	}
+	return nil
+}
+
+// add return false if the evidence already exist
+func (pool *evidencePool) add(ev *Evidence) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	ok, _ := pool.evidences.ContainsOrAdd(ev.Hash(), ev)
+	return !ok
+}
+
+func (pool *evidencePool) get(hash common.Hash) *Evidence {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if val, ok := pool.evidences.Get(hash); ok {
+		return val.(*Evidence)
+	}
+	return nil
+}
+
+func (pool *evidencePool) list() []*Evidence {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	evs := make([]*Evidence, 0, pool.evidences.Len())
+	for _, key := range pool.evidences.Keys() {
+		if val, ok := pool.evidences.Peek(key); ok {
+			evs = append(evs, val.(*Evidence))
+		}
+	}
+	return evs
+}
+
+// AddEvidence add the evidence verified for the chain of chainID to the pool, return false if already exist
+func (dpos *Dpos) AddEvidence(ev *Evidence, chainID *big.Int) (bool, error) {
+	if _, err := ev.Verify(chainID); err != nil {
+		return false, err
+	}
+	return dpos.evidences.add(ev), nil
+}
+
+// GetEvidence get the evidence detected by hash
+func (dpos *Dpos) GetEvidence(hash common.Hash) *Evidence {
+	return dpos.evidences.get(hash)
+}
+
+// Evidences return the evidences detected
+func (dpos *Dpos) Evidences() []*Evidence {
+	return dpos.evidences.list()
+}
+
+// checkDoubleSign emit evidence if the header conflict with a sealed header of the same candidate and slot
+func (dpos *Dpos) checkDoubleSign(header *types.Header, chainID *big.Int) {
+	ev := dpos.evidences.check(header)
+	if ev == nil {
+		return
+	}
+	if added, err := dpos.AddEvidence(ev, chainID); err != nil || !added {
+		return
+	}
+	log.Warn("Double sign detected", "candidate", ev.Candidate(), "number", header.Number, "timestamp", header.Time, "evidence", ev.Hash().Hex())
+	router.SendTo(nil, nil, router.NewEvidenceEv, []*Evidence{ev})
+}
+
+// EvidenceStation is responsible for double sign evidences broadcasting and receiving
+type EvidenceStation struct {
+	evChan  chan *router.Event
+	dpos    *Dpos
+	chainID *big.Int
+	peers   map[string]router.Station
+	quit    chan struct{}
+	loopWG  sync.WaitGroup
+	subs    []router.Subscription
+}
+
+// NewEvidenceStation create a new EvidenceStation for the chain of chainID
+func NewEvidenceStation(dpos *Dpos, chainID *big.Int) *EvidenceStation {
+	station := &EvidenceStation{
+		evChan:  make(chan *router.Event, 64),
+		dpos:    dpos,
+		chainID: chainID,
+		peers:   make(map[string]router.Station),
+		quit:    make(chan struct{}),
+		subs:    make([]router.Subscription, 4),
+	}
+	station.subs[0] = router.Subscribe(nil, station.evChan, router.P2PEvidenceMsg, []*Evidence{}) // recive evidences form remote
+	station.subs[1] = router.Subscribe(nil, station.evChan, router.NewPeerPassedNotify, nil)      // new peer is handshake completed
+	station.subs[2] = router.Subscribe(nil, station.evChan, router.DelPeerNotify, new(string))    // peer is disconnected
+	station.subs[3] = router.Subscribe(nil, station.evChan, router.NewEvidenceEv, []*Evidence{})  // evidence detected, prepare to broadcast
+	station.loopWG.Add(1)
+	go station.handleMsg()
+	return station
+}
+
+func (s *EvidenceStation) broadcast(evs []*Evidence, except string) {
+	for name, peer := range s.peers {
+		if name != except {
+			router.SendTo(nil, peer, router.P2PEvidenceMsg, evs)
+		}
+	}
+}
+
+func (s *EvidenceStation) handleMsg() {
+	defer s.loopWG.Done()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case e := <-s.evChan:
+			switch e.Typecode {
+			case router.NewEvidenceEv:
+				s.broadcast(e.Data.([]*Evidence), "")
+			case router.P2PEvidenceMsg:
+				var evs []*Evidence
+				for _, ev := range e.Data.([]*Evidence) {
+					if added, err := s.dpos.AddEvidence(ev, s.chainID); err != nil {
+						log.Debug("Invalid double sign evidence", "peer", e.From.Name(), "err", err)
+					} else if added {
+						log.Warn("Double sign evidence received", "candidate", ev.Candidate(), "evidence", ev.Hash().Hex())
+						evs = append(evs, ev)
+					}
+				}
+				if len(evs) > 0 {
+					s.broadcast(evs, e.From.Name())
+				}
+			case router.NewPeerPassedNotify:
+				s.peers[e.From.Name()] = e.From
+				if evs := s.dpos.Evidences(); len(evs) > 0 {
+					router.SendTo(nil, e.From, router.P2PEvidenceMsg, evs)
+				}
+			case router.DelPeerNotify:
+				delete(s.peers, e.From.Name())
+			}
+		}
+	}
+}
+
+// Stop stop the station
+func (s *EvidenceStation) Stop() {
+	log.Info("EvidenceStation stopping...")
+	close(s.quit)
+	for _, sub := range s.subs {
+		sub.Unsubscribe()
+	}
+	s.loopWG.Wait()
+	log.Info("EvidenceStation stopped.")
+}
@@ -0,0 +1,225 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+var evidenceChainID = big.NewInt(1)
+
+func newSignedHeader(prv *ecdsa.PrivateKey, coinbase string, time uint64, root common.Hash) *types.Header {
+	return newChainHeader(prv, coinbase, time, root, params.ForkID5, evidenceChainID)
+}
+
+func newChainHeader(prv *ecdsa.PrivateKey, coinbase string, time uint64, root common.Hash, fid uint64, chainID *big.Int) *types.Header {
+	header := &types.Header{
+		Coinbase:   common.Name(coinbase),
+		Root:       root,
+		Difficulty: big.NewInt(1),
+		Number:     big.NewInt(10),
+		Time:       new(big.Int).SetUint64(time),
+		Extra:      make([]byte, extraSeal),
+	}
+	header.WithForkID(fid, fid)
+	sig, err := crypto.Sign(signHash(header, chainID.Bytes()).Bytes(), prv)
+	if err != nil {
+		panic(fmt.Errorf("Sign --- %v", err))
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+	return header
+}
+
+func TestEvidence(t *testing.T) {
+	prv, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+
+	a := newSignedHeader(prv, candidates[0], 3000, common.Hash{1})
+	b := newSignedHeader(prv, candidates[0], 3000, common.Hash{2})
+	ev := NewEvidence(a, b)
+	if pubkey, err := ev.Verify(evidenceChainID); err != nil {
+		t.Fatalf("Verify --- %v", err)
+	} else if !bytes.Equal(pubkey, crypto.FromECDSAPub(&prv.PublicKey)) {
+		t.Fatal("Verify pubkey mismatch")
+	}
+	if ev.Hash() != (&Evidence{HeaderA: b, HeaderB: a}).Hash() {
+		t.Fatal("Hash depends on headers order")
+	}
+	if ev.Candidate() != candidates[0] {
+		t.Fatalf("Candidate mismatch %v", ev.Candidate())
+	}
+
+	raw, err := rlp.EncodeToBytes(&ReportDoubleSign{Evidence: ev})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg := &ReportDoubleSign{}
+	if err := rlp.DecodeBytes(raw, &arg); err != nil {
+		t.Fatal(err)
+	} else if arg.Evidence.Hash() != ev.Hash() {
+		t.Fatal("decoded evidence mismatch")
+	}
+
+	for i, test := range []struct {
+		a, b *types.Header
+		err  error
+	}{
+		{a, nil, errEvidenceMissingHeader},
+		{a, a, errEvidenceNotConflict},
+		{a, newSignedHeader(prv, candidates[0], 6000, common.Hash{2}), errEvidenceNotConflict},
+		{a, newSignedHeader(prv, candidates[1], 3000, common.Hash{2}), errEvidenceNotConflict},
+		{a, newSignedHeader(other, candidates[0], 3000, common.Hash{2}), errEvidenceSigner},
+		{a, newChainHeader(prv, candidates[0], 3000, common.Hash{2}, params.ForkID4, evidenceChainID), errEvidenceFork},
+		{a, newChainHeader(prv, candidates[0], 3000, common.Hash{2}, params.ForkID5, big.NewInt(2)), errEvidenceSigner},
+	} {
+		if _, err := (&Evidence{HeaderA: test.a, HeaderB: test.b}).Verify(evidenceChainID); err != test.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+}
+
+func TestEvidencePool(t *testing.T) {
+	prv, _ := crypto.GenerateKey()
+	a := newSignedHeader(prv, candidates[0], 3000, common.Hash{1})
+	b := newSignedHeader(prv, candidates[0], 3000, common.Hash{2})
+
+	pool := newEvidencePool()
+	if ev := pool.check(a); ev != nil {
+		t.Fatal("unexpected evidence of first header")
+	}
+	if ev := pool.check(a); ev != nil {
+		t.Fatal("unexpected evidence of same header")
+	}
+	if ev := pool.check(newSignedHeader(prv, candidates[1], 3000, common.Hash{2})); ev != nil {
+		t.Fatal("unexpected evidence of other candidate")
+	}
+	ev := pool.check(b)
+	if ev == nil {
+		t.Fatal("missing evidence of conflicting header")
+	}
+	if !pool.add(ev) || pool.add(NewEvidence(b, a)) {
+		t.Fatal("add evidence mismatch")
+	}
+	if pool.get(ev.Hash()) == nil || len(pool.list()) != 1 {
+		t.Fatal("get evidence mismatch")
+	}
+}
+
+type slashDB struct {
+	*levelDB
+	slashed *big.Int
+	pubKeys map[uint64][]byte // snapshot time -> candidate pubkey
+}
+
+func (db *slashDB) GetSnapshot(key string, timestamp uint64) ([]byte, error) {
+	if strings.HasPrefix(key, CandidatePubKeyPrefix+Separator) {
+		return db.pubKeys[timestamp], nil
+	}
+	return nil, nil
+}
+
+func (db *slashDB) Undelegate(to string, amount *big.Int) (*types.Action, error) {
+	db.slashed = new(big.Int).Add(db.slashed, amount)
+	return types.NewAction(types.Transfer, common.Name(DefaultConfig.AccountName), common.Name(to), 0, DefaultConfig.AssetID, 0, amount, nil, nil), nil
+}
+
+func TestReportDoubleSign(t *testing.T) {
+	ldb, function := newTestLDB()
+	defer function()
+	sdb := &slashDB{levelDB: ldb, slashed: big.NewInt(0), pubKeys: make(map[uint64][]byte)}
+	db, _ := NewLDB(sdb)
+	config := DefaultConfig.copy()
+	sys := &System{
+		config: config,
+		IDB:    db,
+	}
+
+	epoch, candidate := uint64(1), candidates[0]
+	if err := db.SetState(&GlobalState{
+		Epoch:         epoch,
+		PreEpoch:      epoch,
+		TotalQuantity: big.NewInt(0),
+	}); err != nil {
+		t.Fatalf("SetState --- %v", err)
+	}
+	if err := db.SetAvailableQuantity(epoch, candidate, new(big.Int).Mul(big10, minStakeCandidate)); err != nil {
+		t.Fatalf("SetAvailableQuantity --- %v", err)
+	}
+	if err := sys.RegCandidate(epoch, candidate, "", new(big.Int).Mul(big10, minStakeCandidate), 1, 0); err != nil {
+		t.Fatalf("RegCandidate --- %v", err)
+	}
+
+	// the candidate signed with prv in the slot and with other since
+	prv, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	slot := config.epochTimeStamp(epoch) + config.blockInterval()
+	sdb.pubKeys[slot] = crypto.FromECDSAPub(&prv.PublicKey)
+	sdb.pubKeys[slot+config.blockInterval()] = crypto.FromECDSAPub(&other.PublicKey)
+	if err := sdb.Put(strings.Join([]string{CandidatePubKeyPrefix, candidate}, Separator), crypto.FromECDSAPub(&other.PublicKey)); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := NewEvidence(newSignedHeader(prv, candidate, slot+config.blockInterval(), common.Hash{1}), newSignedHeader(prv, candidate, slot+config.blockInterval(), common.Hash{2}))
+	if err := sys.ReportDoubleSign(epoch, stale, evidenceChainID, 2, 0); err == nil || !strings.Contains(err.Error(), "invalid evidence signer") {
+		t.Fatalf("ReportDoubleSign with the replaced key --- %v", err)
+	}
+	ev := NewEvidence(newSignedHeader(prv, candidate, slot, common.Hash{1}), newSignedHeader(prv, candidate, slot, common.Hash{2}))
+	if err := sys.ReportDoubleSign(epoch+2, ev, evidenceChainID, 2, 0); err != errEvidenceExpired {
+		t.Fatalf("ReportDoubleSign expired --- %v", err)
+	}
+	if err := sys.ReportDoubleSign(epoch, ev, big.NewInt(2), 2, 0); err != errEvidenceSigner {
+		t.Fatalf("ReportDoubleSign of another chain --- %v", err)
+	}
+	if err := sys.ReportDoubleSign(epoch, ev, evidenceChainID, 2, 0); err != nil {
+		t.Fatalf("ReportDoubleSign --- %v", err)
+	}
+
+	quantity := new(big.Int).Mul(big10, DefaultConfig.CandidateMinQuantity)
+	slash := new(big.Int).Div(new(big.Int).Mul(quantity, new(big.Int).SetUint64(DefaultSlashRate)), big.NewInt(100))
+	remain := new(big.Int).Sub(quantity, slash)
+	if prod, _ := sys.GetCandidate(epoch, candidate); prod.Quantity.Cmp(remain) != 0 || prod.TotalQuantity.Cmp(remain) != 0 {
+		t.Fatalf("candidate quantity mismatch %v %v, want %v", prod.Quantity, prod.TotalQuantity, remain)
+	}
+	if gstate, _ := sys.GetState(epoch); gstate.TotalQuantity.Cmp(remain) != 0 {
+		t.Fatalf("state quantity mismatch %v, want %v", gstate.TotalQuantity, remain)
+	}
+	if stake := new(big.Int).Mul(slash, DefaultConfig.unitStake()); sdb.slashed.Cmp(stake) != 0 {
+		t.Fatalf("slashed stake mismatch %v, want %v", sdb.slashed, stake)
+	}
+	if len(sys.internalActions) != 1 {
+		t.Fatalf("internal actions mismatch %v", len(sys.internalActions))
+	}
+
+	if err := sys.ReportDoubleSign(epoch+1, ev, evidenceChainID, 3, 0); err == nil || !strings.Contains(err.Error(), "already reported") {
+		t.Fatalf("ReportDoubleSign twice --- %v", err)
+	}
+	sysev := NewEvidence(newSignedHeader(prv, DefaultConfig.SystemName, slot, common.Hash{1}), newSignedHeader(prv, DefaultConfig.SystemName, slot, common.Hash{2}))
+	if err := sys.ReportDoubleSign(epoch, sysev, evidenceChainID, 3, 0); err == nil || !strings.Contains(err.Error(), "no permission") {
+		t.Fatalf("ReportDoubleSign system --- %v", err)
+	}
+}
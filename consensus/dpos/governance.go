@@ -0,0 +1,489 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+const (
+	// proposalVotingEpochs epochs after the proposing epoch the proposal can be voted
+	proposalVotingEpochs = uint64(1)
+	// maxProposalChanges max parameter changes of a proposal
+	maxProposalChanges = 16
+	// governedCacheSize governed configs cached by epoch
+	governedCacheSize = 16
+)
+
+// ProposalStatus proposal status
+type ProposalStatus uint64
+
+const (
+	// Voting proposal in voting
+	Voting ProposalStatus = iota
+	// Passed proposal passed, changes take effect at the active epoch
+	Passed
+	// Rejected proposal rejected
+	Rejected
+)
+
+// MarshalText returns the text of s.
+func (s ProposalStatus) MarshalText() ([]byte, error) {
+	switch s {
+	case Voting:
+		return []byte("voting"), nil
+	case Passed:
+		return []byte("passed"), nil
+	case Rejected:
+		return []byte("rejected"), nil
+	}
+	return []byte("unkown"), nil
+}
+
+// UnmarshalText parses the text of status.
+func (s *ProposalStatus) UnmarshalText(input []byte) error {
+	switch strings.ToLower(string(input)) {
+	case "voting":
+		*s = Voting
+	case "passed":
+		*s = Passed
+	case "rejected":
+		*s = Rejected
+	default:
+		return fmt.Errorf("unkown proposal status %v", string(input))
+	}
+	return nil
+}
+
+// ParamChange new value of chain parameter, named as in the chain config
+type ParamChange struct {
+	Name  string   `json:"name"`
+	Value *big.Int `json:"value"`
+}
+
+// GovernedParam parameter changed by passed proposal since epoch
+type GovernedParam struct {
+	Epoch    uint64   `json:"epoch"`
+	Proposal uint64   `json:"proposal"`
+	Name     string   `json:"name"`
+	Value    *big.Int `json:"value"`
+}
+
+// GovernedIndex names of the governed parameters, the root hashes all passed
+// proposals and identifies the governed config of a chain
+type GovernedIndex struct {
+	Root  common.Hash `json:"root"`
+	Names []string    `json:"names"`
+}
+
+// Proposal proposal info
+type Proposal struct {
+	ID            uint64         `json:"id"`
+	Proposer      string         `json:"proposer"`
+	Info          string         `json:"info"`
+	Changes       []*ParamChange `json:"changes"`
+	Epoch         uint64         `json:"epoch"`         // proposing epoch, the stakes of this epoch weight the votes
+	EndEpoch      uint64         `json:"endEpoch"`      // last epoch of voting
+	TotalQuantity *big.Int       `json:"totalQuantity"` // stake quantity of all candidates in the proposing epoch, set when executed
+	Yes           *big.Int       `json:"yes"`           // stake quantity approved
+	No            *big.Int       `json:"no"`            // stake quantity rejected
+	Status        ProposalStatus `json:"status"`
+	ActiveEpoch   uint64         `json:"activeEpoch"` // epoch the changes take effect
+	Number        uint64         `json:"number"`
+}
+
+// ProposalVote vote of proposal
+type ProposalVote struct {
+	ID       uint64   `json:"id"`
+	Voter    string   `json:"voter"`
+	Approve  bool     `json:"approve"`
+	Quantity *big.Int `json:"quantity"`
+	Number   uint64   `json:"number"`
+}
+
+// governable parameter, the parameters changing the slots, the epochs or the
+// unit of recorded stakes (candidateScheduleSize, unitStake) can only be changed
+// by a fork. The gas costs checked by the txpool are params.GasTableInstance,
+// not part of the chain config, and are not governable either.
+type governable struct {
+	min, max uint64
+	dpos     func(cfg *Config, val *big.Int)
+	chain    func(cfg *params.ChainConfig, val *big.Int)
+}
+
+var governables = map[string]*governable{
+	"maxURLLen": {1, math.MaxUint64,
+		func(cfg *Config, val *big.Int) { cfg.MaxURLLen = val.Uint64() },
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.DposCfg.MaxURLLen = val.Uint64() }},
+	"candidateMinQuantity": {1, math.MaxUint64,
+		func(cfg *Config, val *big.Int) { cfg.CandidateMinQuantity = val },
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.DposCfg.CandidateMinQuantity = val }},
+	"candidateAvailableMinQuantity": {0, math.MaxUint64,
+		func(cfg *Config, val *big.Int) { cfg.CandidateAvailableMinQuantity = val },
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.DposCfg.CandidateAvailableMinQuantity = val }},
+	"voterMinQuantity": {1, math.MaxUint64,
+		func(cfg *Config, val *big.Int) { cfg.VoterMinQuantity = val },
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.DposCfg.VoterMinQuantity = val }},
+	"activatedMinCandidate": {1, math.MaxUint64,
+		func(cfg *Config, val *big.Int) { cfg.ActivatedMinCandidate = val.Uint64() },
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.DposCfg.ActivatedMinCandidate = val.Uint64() }},
+	"activatedMinQuantity": {0, math.MaxUint64,
+		func(cfg *Config, val *big.Int) { cfg.ActivatedMinQuantity = val },
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.DposCfg.ActivatedMinQuantity = val }},
+	"extraBlockReward": {0, math.MaxUint64,
+		func(cfg *Config, val *big.Int) { cfg.ExtraBlockReward = val },
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.DposCfg.ExtraBlockReward = val }},
+	"blockReward": {0, math.MaxUint64,
+		func(cfg *Config, val *big.Int) { cfg.BlockReward = val },
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.DposCfg.BlockReward = val }},
+	"slashRate": {1, 100,
+		func(cfg *Config, val *big.Int) { cfg.SlashRate = val.Uint64() },
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.DposCfg.SlashRate = val.Uint64() }},
+	"assetRatio": {0, 100,
+		nil,
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.ChargeCfg.AssetRatio = val.Uint64() }},
+	"contractRatio": {0, 100,
+		nil,
+		func(cfg *params.ChainConfig, val *big.Int) { cfg.ChargeCfg.ContractRatio = val.Uint64() }},
+}
+
+func checkParamChanges(changes []*ParamChange) error {
+	if len(changes) == 0 {
+		return fmt.Errorf("no parameter changes")
+	}
+	if len(changes) > maxProposalChanges {
+		return fmt.Errorf("too many parameter changes %v(max %v)", len(changes), maxProposalChanges)
+	}
+	names := map[string]bool{}
+	for _, change := range changes {
+		g, ok := governables[change.Name]
+		if !ok {
+			return fmt.Errorf("parameter %v not governable", change.Name)
+		}
+		if names[change.Name] {
+			return fmt.Errorf("duplicate parameter %v", change.Name)
+		}
+		names[change.Name] = true
+		if change.Value == nil || change.Value.Sign() < 0 || !change.Value.IsUint64() ||
+			change.Value.Uint64() < g.min || change.Value.Uint64() > g.max {
+			return fmt.Errorf("invalid value %v of parameter %v(range %v-%v)", change.Value, change.Name, g.min, g.max)
+		}
+	}
+	return nil
+}
+
+// stakeQuantity the stake quantity of name in epoch, its own candidate stake and its votes
+func (sys *System) stakeQuantity(epoch uint64, name string) (*big.Int, error) {
+	quantity := big.NewInt(0)
+	prod, err := sys.GetCandidate(epoch, name)
+	if err != nil {
+		return nil, err
+	}
+	if prod != nil && !prod.invalid() {
+		quantity = new(big.Int).Add(quantity, prod.Quantity)
+	}
+	voters, err := sys.GetVotersByVoter(epoch, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, voter := range voters {
+		quantity = new(big.Int).Add(quantity, voter.Quantity)
+	}
+	return quantity, nil
+}
+
+// CreateProposal propose the parameter changes
+func (sys *System) CreateProposal(epoch uint64, proposer string, info string, changes []*ParamChange, number uint64, fid uint64) error {
+	if err := checkParamChanges(changes); err != nil {
+		return err
+	}
+	if uint64(len(info)) > sys.config.MaxURLLen {
+		return fmt.Errorf("invalid info %v(too long, max %v)", info, sys.config.MaxURLLen)
+	}
+	quantity, err := sys.stakeQuantity(epoch, proposer)
+	if err != nil {
+		return err
+	}
+	if quantity.Sign() == 0 {
+		return fmt.Errorf("invalid proposer %v(no stake)", proposer)
+	}
+	id, err := sys.GetProposalCount()
+	if err != nil {
+		return err
+	}
+	if err := sys.SetProposalCount(id + 1); err != nil {
+		return err
+	}
+	return sys.SetProposal(&Proposal{
+		ID:            id,
+		Proposer:      proposer,
+		Info:          info,
+		Changes:       changes,
+		Epoch:         epoch,
+		EndEpoch:      epoch + proposalVotingEpochs,
+		TotalQuantity: big.NewInt(0),
+		Yes:           big.NewInt(0),
+		No:            big.NewInt(0),
+		Status:        Voting,
+		Number:        number,
+	})
+}
+
+// VoteProposal vote for or against the proposal with the stake quantity of voter in the proposing epoch,
+// voting starts after the proposing epoch ended so the stakes can not change, revoting replaces the vote
+func (sys *System) VoteProposal(epoch uint64, voter string, id uint64, approve bool, number uint64, fid uint64) error {
+	proposal, err := sys.GetProposal(id)
+	if err != nil {
+		return err
+	}
+	if proposal == nil {
+		return fmt.Errorf("invalid proposal %v(not exist)", id)
+	}
+	if proposal.Status != Voting || epoch > proposal.EndEpoch {
+		return fmt.Errorf("invalid proposal %v(voting ended)", id)
+	}
+	if epoch <= proposal.Epoch {
+		return fmt.Errorf("invalid proposal %v(voting from epoch %v)", id, proposal.Epoch+1)
+	}
+	quantity, err := sys.stakeQuantity(proposal.Epoch, voter)
+	if err != nil {
+		return err
+	}
+	if quantity.Sign() == 0 {
+		return fmt.Errorf("invalid voter %v(no stake)", voter)
+	}
+
+	vote, err := sys.GetProposalVote(id, voter)
+	if err != nil {
+		return err
+	}
+	if vote != nil {
+		if vote.Approve {
+			proposal.Yes = new(big.Int).Sub(proposal.Yes, vote.Quantity)
+		} else {
+			proposal.No = new(big.Int).Sub(proposal.No, vote.Quantity)
+		}
+	}
+	if approve {
+		proposal.Yes = new(big.Int).Add(proposal.Yes, quantity)
+	} else {
+		proposal.No = new(big.Int).Add(proposal.No, quantity)
+	}
+	if err := sys.SetProposalVote(&ProposalVote{
+		ID:       id,
+		Voter:    voter,
+		Approve:  approve,
+		Quantity: quantity,
+		Number:   number,
+	}); err != nil {
+		return err
+	}
+	return sys.SetProposal(proposal)
+}
+
+// passed whether more than two thirds of the stake approved
+func (proposal *Proposal) passed() bool {
+	return new(big.Int).Mul(proposal.Yes, big.NewInt(3)).Cmp(new(big.Int).Mul(proposal.TotalQuantity, big.NewInt(2))) > 0
+}
+
+// ExecuteProposal tally the proposal after voting ended, the changes of passed proposal take effect at the next epoch
+func (sys *System) ExecuteProposal(epoch uint64, id uint64, number uint64, fid uint64) error {
+	proposal, err := sys.GetProposal(id)
+	if err != nil {
+		return err
+	}
+	if proposal == nil {
+		return fmt.Errorf("invalid proposal %v(not exist)", id)
+	}
+	if proposal.Status != Voting {
+		return fmt.Errorf("invalid proposal %v(executed)", id)
+	}
+	if epoch <= proposal.EndEpoch {
+		return fmt.Errorf("invalid proposal %v(voting until epoch %v)", id, proposal.EndEpoch)
+	}
+	gstate, err := sys.GetState(proposal.Epoch)
+	if err != nil {
+		return err
+	}
+	if gstate != nil {
+		proposal.TotalQuantity = new(big.Int).Set(gstate.TotalQuantity)
+	}
+	if !proposal.passed() {
+		proposal.Status = Rejected
+		return sys.SetProposal(proposal)
+	}
+
+	proposal.Status = Passed
+	proposal.ActiveEpoch = epoch + 1
+	index, err := sys.GetGovernedIndex()
+	if err != nil {
+		return err
+	}
+	for _, change := range proposal.Changes {
+		values, err := sys.GetGovernedParam(change.Name)
+		if err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			index.Names = append(index.Names, change.Name)
+		}
+		// keep only the value in effect, a pending value of the same active epoch is replaced
+		kept := []*GovernedParam{}
+		for _, value := range values {
+			if value.Epoch <= epoch {
+				kept = []*GovernedParam{value}
+			}
+		}
+		kept = append(kept, &GovernedParam{
+			Epoch:    proposal.ActiveEpoch,
+			Proposal: proposal.ID,
+			Name:     change.Name,
+			Value:    change.Value,
+		})
+		if err := sys.SetGovernedParam(change.Name, kept); err != nil {
+			return err
+		}
+	}
+	raw, err := rlp.EncodeToBytes(proposal)
+	if err != nil {
+		return err
+	}
+	index.Root = crypto.Keccak256Hash(index.Root.Bytes(), raw)
+	if err := sys.SetGovernedIndex(index); err != nil {
+		return err
+	}
+	return sys.SetProposal(proposal)
+}
+
+// GovernedParams the governed parameters, the value in effect and the pending one of each
+func (sys *System) GovernedParams() ([]*GovernedParam, error) {
+	index, err := sys.GetGovernedIndex()
+	if err != nil {
+		return nil, err
+	}
+	governed := []*GovernedParam{}
+	for _, name := range index.Names {
+		values, err := sys.GetGovernedParam(name)
+		if err != nil {
+			return nil, err
+		}
+		governed = append(governed, values...)
+	}
+	return governed, nil
+}
+
+// governedAt the governed parameters in effect at epoch
+func (sys *System) governedAt(index *GovernedIndex, epoch uint64) ([]*GovernedParam, error) {
+	governed := []*GovernedParam{}
+	for _, name := range index.Names {
+		values, err := sys.GetGovernedParam(name)
+		if err != nil {
+			return nil, err
+		}
+		var active *GovernedParam
+		for _, value := range values {
+			if value.Epoch <= epoch {
+				active = value
+			}
+		}
+		if active != nil {
+			governed = append(governed, active)
+		}
+	}
+	return governed, nil
+}
+
+func (cfg *Config) copy() *Config {
+	bts, _ := json.Marshal(cfg)
+	c := &Config{}
+	json.Unmarshal(bts, c)
+	return c
+}
+
+// governedKey the governed config of base at epoch on the chain with the governed root
+type governedKey struct {
+	base  interface{}
+	epoch uint64
+	root  common.Hash
+}
+
+// configAt the dpos config with the governed parameters in effect at epoch, the configs are cached by epoch
+func (dpos *Dpos) configAt(state *state.StateDB, epoch uint64) (*Config, error) {
+	sys := NewSystem(state, dpos.config)
+	index, err := sys.GetGovernedIndex()
+	if err != nil {
+		return nil, err
+	}
+	if len(index.Names) == 0 {
+		return dpos.config, nil
+	}
+	key := governedKey{base: dpos.config, epoch: epoch, root: index.Root}
+	if cfg, ok := dpos.governed.Get(key); ok {
+		return cfg.(*Config), nil
+	}
+	governed, err := sys.governedAt(index, epoch)
+	if err != nil {
+		return nil, err
+	}
+	cfg := dpos.config.copy()
+	for _, param := range governed {
+		if g := governables[param.Name]; g != nil && g.dpos != nil {
+			g.dpos(cfg, param.Value)
+		}
+	}
+	dpos.governed.Add(key, cfg)
+	return cfg, nil
+}
+
+// ChainConfigAt the chain config with the governed parameters in effect at timestamp, the configs are cached by epoch
+func (dpos *Dpos) ChainConfigAt(config *params.ChainConfig, state *state.StateDB, timestamp uint64) (*params.ChainConfig, error) {
+	sys := NewSystem(state, dpos.config)
+	index, err := sys.GetGovernedIndex()
+	if err != nil {
+		return nil, err
+	}
+	if len(index.Names) == 0 {
+		return config, nil
+	}
+	epoch := dpos.config.epoch(timestamp)
+	key := governedKey{base: config, epoch: epoch, root: index.Root}
+	if cfg, ok := dpos.governed.Get(key); ok {
+		return cfg.(*params.ChainConfig), nil
+	}
+	governed, err := sys.governedAt(index, epoch)
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.Copy()
+	for _, param := range governed {
+		if g := governables[param.Name]; g != nil {
+			g.chain(cfg, param.Value)
+		}
+	}
+	dpos.governed.Add(key, cfg)
+	return cfg, nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/state"
+)
+
+func TestProposal(t *testing.T) {
+	config := &Config{}
+	if raw, err := json.Marshal(DefaultConfig); err != nil {
+		t.Fatal(err)
+	} else if err := json.Unmarshal(raw, config); err != nil {
+		t.Fatal(err)
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	dpos := New(config, nil)
+	sys := NewSystem(statedb, config)
+
+	pepoch, epoch, candidate := uint64(1), uint64(2), candidates[0]
+	if err := sys.SetState(&GlobalState{Epoch: pepoch, PreEpoch: pepoch, TotalQuantity: big.NewInt(100)}); err != nil {
+		t.Fatalf("SetState --- %v", err)
+	}
+	if err := sys.SetState(&GlobalState{Epoch: epoch, PreEpoch: pepoch, TotalQuantity: big.NewInt(80)}); err != nil {
+		t.Fatalf("SetState --- %v", err)
+	}
+	if err := sys.SetCandidate(&CandidateInfo{Epoch: epoch, Name: candidate, Quantity: big.NewInt(40), TotalQuantity: big.NewInt(80)}); err != nil {
+		t.Fatalf("SetCandidate --- %v", err)
+	}
+	for index, quantity := range []int64{30, 10} {
+		if err := sys.SetVoter(&VoterInfo{Epoch: epoch, Name: voters[index], Candidate: candidate, Quantity: big.NewInt(quantity)}); err != nil {
+			t.Fatalf("SetVoter --- %v", err)
+		}
+	}
+
+	changes := []*ParamChange{{Name: "blockReward", Value: big.NewInt(3)}, {Name: "assetRatio", Value: big.NewInt(50)}}
+	for _, invalid := range [][]*ParamChange{
+		nil,
+		{{Name: "unitStake", Value: big.NewInt(1)}},
+		{{Name: "slashRate", Value: big.NewInt(101)}},
+		{{Name: "blockReward", Value: big.NewInt(-1)}},
+		{{Name: "blockReward", Value: big.NewInt(1)}, {Name: "blockReward", Value: big.NewInt(2)}},
+	} {
+		if err := sys.CreateProposal(epoch, candidate, "", invalid, 1, 0); err == nil {
+			t.Fatalf("invalid changes accepted %v", invalid)
+		}
+	}
+	if err := sys.CreateProposal(epoch, voters[2], "", changes, 1, 0); err == nil {
+		t.Fatal("proposal without stake accepted")
+	}
+	if err := sys.CreateProposal(epoch, candidate, "lower block reward", changes, 1, 0); err != nil {
+		t.Fatalf("CreateProposal --- %v", err)
+	}
+	proposal, _ := sys.GetProposal(0)
+	if proposal == nil || proposal.EndEpoch != epoch+proposalVotingEpochs {
+		t.Fatalf("proposal mismatch %v", proposal)
+	}
+
+	// votes are weighted by the stakes of the proposing epoch
+	if err := sys.VoteProposal(epoch, candidate, 0, true, 2, 0); err == nil {
+		t.Fatal("vote in proposing epoch accepted")
+	}
+	vepoch := epoch + 1
+	if err := sys.SetVoter(&VoterInfo{Epoch: vepoch, Name: voters[2], Candidate: candidate, Quantity: big.NewInt(50)}); err != nil {
+		t.Fatalf("SetVoter --- %v", err)
+	}
+	if err := sys.VoteProposal(vepoch, voters[2], 0, true, 2, 0); err == nil {
+		t.Fatal("vote without stake in proposing epoch accepted")
+	}
+	if err := sys.VoteProposal(vepoch, candidate, 1, true, 2, 0); err == nil {
+		t.Fatal("vote of unknown proposal accepted")
+	}
+	if err := sys.VoteProposal(vepoch, candidate, 0, true, 2, 0); err != nil {
+		t.Fatalf("VoteProposal --- %v", err)
+	}
+	if err := sys.VoteProposal(vepoch, voters[0], 0, false, 2, 0); err != nil {
+		t.Fatalf("VoteProposal --- %v", err)
+	}
+	if err := sys.VoteProposal(vepoch, voters[0], 0, true, 3, 0); err != nil {
+		t.Fatalf("VoteProposal --- %v", err)
+	}
+	if proposal, _ = sys.GetProposal(0); proposal.Yes.Cmp(big.NewInt(70)) != 0 || proposal.No.Sign() != 0 {
+		t.Fatalf("tally mismatch yes %v no %v", proposal.Yes, proposal.No)
+	}
+
+	if err := sys.ExecuteProposal(epoch+proposalVotingEpochs, 0, 4, 0); err == nil {
+		t.Fatal("execute in voting accepted")
+	}
+	executed := epoch + proposalVotingEpochs + 1
+	if err := sys.VoteProposal(executed, voters[1], 0, true, 5, 0); err == nil {
+		t.Fatal("vote after voting ended accepted")
+	}
+	if err := sys.ExecuteProposal(executed, 0, 5, 0); err != nil {
+		t.Fatalf("ExecuteProposal --- %v", err)
+	}
+	if proposal, _ = sys.GetProposal(0); proposal.Status != Passed || proposal.ActiveEpoch != executed+1 || proposal.TotalQuantity.Cmp(big.NewInt(80)) != 0 {
+		t.Fatalf("proposal not passed %v %v", proposal.Status, proposal.ActiveEpoch)
+	}
+	if err := sys.ExecuteProposal(executed, 0, 6, 0); err == nil {
+		t.Fatal("execute twice accepted")
+	}
+
+	// changes take effect from the active epoch
+	if cfg, err := dpos.configAt(statedb, executed); err != nil || cfg.BlockReward.Cmp(config.BlockReward) != 0 {
+		t.Fatalf("config changed before active epoch %v", err)
+	}
+	cfg, err := dpos.configAt(statedb, executed+1)
+	if err != nil || cfg.BlockReward.Cmp(big.NewInt(3)) != 0 || config.BlockReward.Cmp(big.NewInt(3)) == 0 {
+		t.Fatalf("config not changed at active epoch %v", err)
+	}
+	chainCfg, err := dpos.ChainConfigAt(params.DefaultChainconfig, statedb, config.epochTimeStamp(executed+1))
+	if err != nil || chainCfg.DposCfg.BlockReward.Cmp(big.NewInt(3)) != 0 || chainCfg.ChargeCfg.AssetRatio != 50 {
+		t.Fatalf("chain config not changed at active epoch %v", err)
+	}
+	if params.DefaultChainconfig.ChargeCfg.AssetRatio == 50 {
+		t.Fatal("default chain config modified")
+	}
+
+	// rejected without two thirds of the stake
+	if err := sys.CreateProposal(epoch, voters[0], "", changes, 7, 0); err != nil {
+		t.Fatalf("CreateProposal --- %v", err)
+	}
+	if err := sys.VoteProposal(vepoch, candidate, 1, true, 8, 0); err != nil {
+		t.Fatalf("VoteProposal --- %v", err)
+	}
+	if err := sys.ExecuteProposal(executed, 1, 9, 0); err != nil {
+		t.Fatalf("ExecuteProposal --- %v", err)
+	}
+	if proposal, _ = sys.GetProposal(1); proposal.Status != Rejected {
+		t.Fatalf("proposal not rejected %v", proposal.Status)
+	}
+	if governed, _ := sys.GovernedParams(); len(governed) != len(changes) {
+		t.Fatalf("governed params mismatch %v", governed)
+	}
+
+	// a later change keeps one value in effect per parameter
+	if err := sys.CreateProposal(epoch, candidate, "", []*ParamChange{{Name: "blockReward", Value: big.NewInt(5)}}, 10, 0); err != nil {
+		t.Fatalf("CreateProposal --- %v", err)
+	}
+	if err := sys.VoteProposal(vepoch, candidate, 2, true, 11, 0); err != nil {
+		t.Fatalf("VoteProposal --- %v", err)
+	}
+	if err := sys.VoteProposal(vepoch, voters[0], 2, true, 11, 0); err != nil {
+		t.Fatalf("VoteProposal --- %v", err)
+	}
+	if err := sys.ExecuteProposal(executed+1, 2, 12, 0); err != nil {
+		t.Fatalf("ExecuteProposal --- %v", err)
+	}
+	if values, _ := sys.GetGovernedParam("blockReward"); len(values) != 2 || values[0].Proposal != 0 || values[1].Proposal != 2 {
+		t.Fatalf("governed values mismatch %v", values)
+	}
+	if cfg, _ := dpos.configAt(statedb, executed+1); cfg.BlockReward.Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("config changed before active epoch %v", cfg.BlockReward)
+	}
+	if cfg, _ := dpos.configAt(statedb, executed+2); cfg.BlockReward.Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("config not changed at active epoch %v", cfg.BlockReward)
+	}
+}
@@ -39,7 +39,9 @@ type IDatabase interface {
 	IncAsset2Acct(string, string, *big.Int, uint64) (*types.Action, error)
 	GetBalanceByTime(name string, timestamp uint64) (*big.Int, error)
 	IsValidSign(name string, pubkey []byte) error
+	IsValidSignByTime(name string, pubkey []byte, timestamp uint64) error
 	GetSnapshot(key string, timestamp uint64) ([]byte, error)
+	GetSnapshotTime(timestamp uint64) (uint64, error)
 }
 
 var (
@@ -61,6 +63,27 @@ var (
 	// TakeOver key
 	TakeOver = "takeover"
 
+	// EvidenceKeyPrefix reported double sign evidence
+	EvidenceKeyPrefix = "ev"
+
+	// CommissionKeyPrefix candidate commission rate
+	CommissionKeyPrefix = "cm"
+	// RewardPoolKeyPrefix rewards shared by voters in epoch
+	RewardPoolKeyPrefix = "rp"
+	// RewardEpochKeyPrefix epochs voted by voter and not claimed
+	RewardEpochKeyPrefix = "re"
+
+	// ProposalCountKey proposal count
+	ProposalCountKey = "gc"
+	// ProposalKeyPrefix proposal info
+	ProposalKeyPrefix = "gp"
+	// ProposalVoteKeyPrefix proposal vote
+	ProposalVoteKeyPrefix = "gv"
+	// GovernedIndexKey names of the parameters changed by passed proposals
+	GovernedIndexKey = "governed"
+	// GovernedParamKeyPrefix value of parameter changed by passed proposals
+	GovernedParamKeyPrefix = "gd"
+
 	// StateKeyPrefix globalState
 	StateKeyPrefix = "s"
 	// LastestStateKey lastest
@@ -99,6 +122,24 @@ func (db *LDB) CanMine(name string, pub []byte) error {
 	return db.IsValidSign(name, pub)
 }
 
+// CanMineAt allow mining the block of timestamp, with the keys of the last snapshot before it
+func (db *LDB) CanMineAt(name string, pub []byte, timestamp uint64) error {
+	time, err := db.GetSnapshotTime(timestamp)
+	if err != nil {
+		return err
+	}
+	pubkey := strings.Join([]string{CandidatePubKeyPrefix, fmt.Sprintf("%s", name)}, Separator)
+	if val, _ := db.GetSnapshot(pubkey, time); val != nil {
+		if bytes.Compare(common.EmptyPubKey.Bytes(), val) != 0 {
+			if bytes.Compare(pub, val) == 0 {
+				return nil
+			}
+			return fmt.Errorf("need pubkey %s", common.BytesToPubKey(val).String())
+		}
+	}
+	return db.IsValidSignByTime(name, pub, time)
+}
+
 // SetCandidate update candidate info
 func (db *LDB) SetCandidate(candidate *CandidateInfo) error {
 	if candidate.Name != CandidateHead && len(candidate.PrevKey) == 0 && len(candidate.NextKey) == 0 {
@@ -457,6 +498,100 @@ func (db *LDB) GetTakeOver() (uint64, error) {
 	return epoch, nil
 }
 
+// SetEvidence mark double sign evidence reported
+func (db *LDB) SetEvidence(hash common.Hash) error {
+	key := strings.Join([]string{EvidenceKeyPrefix, hash.Hex()}, Separator)
+	return db.Put(key, []byte{1})
+}
+
+// HasEvidence whether double sign evidence reported
+func (db *LDB) HasEvidence(hash common.Hash) (bool, error) {
+	key := strings.Join([]string{EvidenceKeyPrefix, hash.Hex()}, Separator)
+	val, err := db.Get(key)
+	if err != nil {
+		return false, err
+	}
+	return len(val) != 0, nil
+}
+
+// SetCommission set commission rate of candidate
+func (db *LDB) SetCommission(name string, rate uint64) error {
+	key := strings.Join([]string{CommissionKeyPrefix, name}, Separator)
+	if val, err := rlp.EncodeToBytes(rate); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetCommission get commission rate of candidate, MaxCommission if not set
+func (db *LDB) GetCommission(name string) (uint64, error) {
+	key := strings.Join([]string{CommissionKeyPrefix, name}, Separator)
+	rate := MaxCommission
+	if val, err := db.Get(key); err != nil {
+		return rate, err
+	} else if val == nil {
+		return rate, nil
+	} else if err := rlp.DecodeBytes(val, &rate); err != nil {
+		return rate, err
+	}
+	return rate, nil
+}
+
+// SetRewardPool set rewards of candidate shared by voters in epoch
+func (db *LDB) SetRewardPool(epoch uint64, pool *RewardPool) error {
+	key := strings.Join([]string{RewardPoolKeyPrefix, fmt.Sprintf("0x%x_%s", epoch, pool.Candidate)}, Separator)
+	if val, err := rlp.EncodeToBytes(pool); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetRewardPool get rewards of candidate shared by voters in epoch
+func (db *LDB) GetRewardPool(epoch uint64, candidate string) (*RewardPool, error) {
+	key := strings.Join([]string{RewardPoolKeyPrefix, fmt.Sprintf("0x%x_%s", epoch, candidate)}, Separator)
+	pool := &RewardPool{}
+	if val, err := db.Get(key); err != nil {
+		return nil, err
+	} else if val == nil {
+		return nil, nil
+	} else if err := rlp.DecodeBytes(val, pool); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+// SetRewardEpochs set epochs voted by voter and not claimed
+func (db *LDB) SetRewardEpochs(voter string, epochs []uint64) error {
+	key := strings.Join([]string{RewardEpochKeyPrefix, voter}, Separator)
+	if len(epochs) == 0 {
+		return db.Delete(key)
+	}
+	if val, err := rlp.EncodeToBytes(epochs); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetRewardEpochs get epochs voted by voter and not claimed
+func (db *LDB) GetRewardEpochs(voter string) ([]uint64, error) {
+	key := strings.Join([]string{RewardEpochKeyPrefix, voter}, Separator)
+	epochs := []uint64{}
+	if val, err := db.Get(key); err != nil {
+		return nil, err
+	} else if val == nil {
+		return epochs, nil
+	} else if err := rlp.DecodeBytes(val, &epochs); err != nil {
+		return nil, err
+	}
+	return epochs, nil
+}
+
 // SetState set global state info
 func (db *LDB) SetState(gstate *GlobalState) error {
 	key := strings.Join([]string{StateKeyPrefix, hex.EncodeToString(uint64tobytes(gstate.Epoch))}, Separator)
@@ -526,3 +661,124 @@ func uint64tobytes(i uint64) []byte {
 func bytestouint64(buf []byte) uint64 {
 	return binary.BigEndian.Uint64(buf)
 }
+
+// SetProposalCount set proposal count
+func (db *LDB) SetProposalCount(count uint64) error {
+	if val, err := rlp.EncodeToBytes(count); err != nil {
+		return err
+	} else if err := db.Put(ProposalCountKey, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetProposalCount get proposal count
+func (db *LDB) GetProposalCount() (uint64, error) {
+	count := uint64(0)
+	if val, err := db.Get(ProposalCountKey); err != nil {
+		return count, err
+	} else if val == nil {
+		return count, nil
+	} else if err := rlp.DecodeBytes(val, &count); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// SetProposal set proposal info
+func (db *LDB) SetProposal(proposal *Proposal) error {
+	key := strings.Join([]string{ProposalKeyPrefix, fmt.Sprintf("0x%x", proposal.ID)}, Separator)
+	if val, err := rlp.EncodeToBytes(proposal); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetProposal get proposal info
+func (db *LDB) GetProposal(id uint64) (*Proposal, error) {
+	key := strings.Join([]string{ProposalKeyPrefix, fmt.Sprintf("0x%x", id)}, Separator)
+	proposal := &Proposal{}
+	if val, err := db.Get(key); err != nil {
+		return nil, err
+	} else if val == nil {
+		return nil, nil
+	} else if err := rlp.DecodeBytes(val, proposal); err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+// SetProposalVote set vote of proposal
+func (db *LDB) SetProposalVote(vote *ProposalVote) error {
+	key := strings.Join([]string{ProposalVoteKeyPrefix, fmt.Sprintf("0x%x", vote.ID), vote.Voter}, Separator)
+	if val, err := rlp.EncodeToBytes(vote); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetProposalVote get vote of proposal by voter
+func (db *LDB) GetProposalVote(id uint64, voter string) (*ProposalVote, error) {
+	key := strings.Join([]string{ProposalVoteKeyPrefix, fmt.Sprintf("0x%x", id), voter}, Separator)
+	vote := &ProposalVote{}
+	if val, err := db.Get(key); err != nil {
+		return nil, err
+	} else if val == nil {
+		return nil, nil
+	} else if err := rlp.DecodeBytes(val, vote); err != nil {
+		return nil, err
+	}
+	return vote, nil
+}
+
+// SetGovernedIndex set names of the parameters changed by passed proposals
+func (db *LDB) SetGovernedIndex(index *GovernedIndex) error {
+	if val, err := rlp.EncodeToBytes(index); err != nil {
+		return err
+	} else if err := db.Put(GovernedIndexKey, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetGovernedIndex get names of the parameters changed by passed proposals
+func (db *LDB) GetGovernedIndex() (*GovernedIndex, error) {
+	index := &GovernedIndex{}
+	if val, err := db.Get(GovernedIndexKey); err != nil {
+		return nil, err
+	} else if val == nil {
+		return index, nil
+	} else if err := rlp.DecodeBytes(val, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// SetGovernedParam set values of parameter changed by passed proposals
+func (db *LDB) SetGovernedParam(name string, values []*GovernedParam) error {
+	key := strings.Join([]string{GovernedParamKeyPrefix, name}, Separator)
+	if val, err := rlp.EncodeToBytes(values); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetGovernedParam get values of parameter changed by passed proposals, ordered by epoch
+func (db *LDB) GetGovernedParam(name string) ([]*GovernedParam, error) {
+	key := strings.Join([]string{GovernedParamKeyPrefix, name}, Separator)
+	values := []*GovernedParam{}
+	if val, err := db.Get(key); err != nil {
+		return nil, err
+	} else if val == nil {
+		return values, nil
+	} else if err := rlp.DecodeBytes(val, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -67,6 +67,9 @@ func (ldb *levelDB) IncAsset2Acct(string, string, *big.Int, uint64) (*types.Acti
 func (ldb *levelDB) GetSnapshot(string, uint64) ([]byte, error) {
 	return nil, nil
 }
+func (ldb *levelDB) GetSnapshotTime(timestamp uint64) (uint64, error) {
+	return timestamp, nil
+}
 func (ldb *levelDB) GetBalanceByTime(name string, timestamp uint64) (*big.Int, error) {
 	return new(big.Int).Mul(big.NewInt(1000000000), DefaultConfig.decimals()), nil
 }
@@ -75,6 +78,10 @@ func (ldb *levelDB) IsValidSign(name string, pubkey []byte) error {
 	return nil
 }
 
+func (ldb *levelDB) IsValidSignByTime(name string, pubkey []byte, timestamp uint64) error {
+	return nil
+}
+
 func newTestLDB() (*levelDB, func()) {
 	dirname, err := ioutil.TempDir(os.TempDir(), "dpos_test_")
 	if err != nil {
@@ -37,7 +37,8 @@ type RegisterCandidate struct {
 
 // UpdateCandidate candidate info
 type UpdateCandidate struct {
-	Info string
+	Info       string
+	Commission []uint64 `rlp:"tail"` // optional commission rate in percent
 }
 
 // UpdateCandidatePubKey candidate info
@@ -61,6 +62,28 @@ type RemoveKickedCandidate struct {
 	Candidates []string
 }
 
+// ReportDoubleSign double sign evidence
+type ReportDoubleSign struct {
+	Evidence *Evidence
+}
+
+// CreateProposal proposal info
+type CreateProposal struct {
+	Info    string
+	Changes []*ParamChange
+}
+
+// VoteProposal proposal vote info
+type VoteProposal struct {
+	ID      uint64
+	Approve bool
+}
+
+// ExecuteProposal proposal execute info
+type ExecuteProposal struct {
+	ID uint64
+}
+
 // ProcessAction exec action
 func (dpos *Dpos) ProcessAction(fid uint64, number uint64, chainCfg *params.ChainConfig, state *state.StateDB, action *types.Action) ([]*types.InternalAction, error) {
 	snap := state.Snapshot()
@@ -90,10 +113,13 @@ func (dpos *Dpos) processAction(fid uint64, number uint64, chainCfg *params.Chai
 	if err != nil {
 		return nil, err
 	}
+	if sys.config, err = dpos.configAt(state, epoch); err != nil {
+		return nil, err
+	}
 	switch action.Type() {
 	case types.RegCandidate:
 		if fid >= params.ForkID2 {
-			if val := new(big.Int).Mul(dpos.config.CandidateMinQuantity, dpos.config.unitStake()); action.Value().Cmp(val) != 0 {
+			if val := new(big.Int).Mul(sys.config.CandidateMinQuantity, sys.config.unitStake()); action.Value().Cmp(val) != 0 {
 				return nil, fmt.Errorf("value must be %v", val)
 			}
 		}
@@ -117,6 +143,14 @@ func (dpos *Dpos) processAction(fid uint64, number uint64, chainCfg *params.Chai
 		if err := sys.UpdateCandidate(epoch, action.Sender().String(), arg.Info, action.Value(), number, fid); err != nil {
 			return nil, err
 		}
+		if len(arg.Commission) > 0 {
+			if fid < params.ForkID5 {
+				return nil, fmt.Errorf("commission not supported")
+			}
+			if err := sys.SetCommission(epoch, action.Sender().String(), arg.Commission[0]); err != nil {
+				return nil, err
+			}
+		}
 	case types.UpdateCandidatePubKey:
 		if fid >= params.ForkID4 {
 			arg := &UpdateCandidatePubKey{}
@@ -196,6 +230,51 @@ func (dpos *Dpos) processAction(fid uint64, number uint64, chainCfg *params.Chai
 		if err := sys.ExitTakeOver(epoch, number, fid); err != nil {
 			return nil, err
 		}
+	case types.ReportDoubleSign:
+		arg := &ReportDoubleSign{}
+		if err := rlp.DecodeBytes(action.Data(), &arg); err != nil {
+			return nil, err
+		}
+		if arg.Evidence == nil {
+			return nil, errEvidenceMissingHeader
+		}
+		if err := sys.ReportDoubleSign(epoch, arg.Evidence, chainCfg.ChainID, number, fid); err != nil {
+			return nil, err
+		}
+	case types.ClaimReward:
+		if err := sys.ClaimReward(epoch, action.Sender().String(), number, fid); err != nil {
+			return nil, err
+		}
+	case types.CreateProposal:
+		if action.Value().Sign() == 1 {
+			return nil, fmt.Errorf("value must be zero")
+		}
+		arg := &CreateProposal{}
+		if err := rlp.DecodeBytes(action.Data(), &arg); err != nil {
+			return nil, err
+		}
+		if err := sys.CreateProposal(epoch, action.Sender().String(), arg.Info, arg.Changes, number, fid); err != nil {
+			return nil, err
+		}
+	case types.VoteProposal:
+		if action.Value().Sign() == 1 {
+			return nil, fmt.Errorf("value must be zero")
+		}
+		arg := &VoteProposal{}
+		if err := rlp.DecodeBytes(action.Data(), &arg); err != nil {
+			return nil, err
+		}
+		if err := sys.VoteProposal(epoch, action.Sender().String(), arg.ID, arg.Approve, number, fid); err != nil {
+			return nil, err
+		}
+	case types.ExecuteProposal:
+		arg := &ExecuteProposal{}
+		if err := rlp.DecodeBytes(action.Data(), &arg); err != nil {
+			return nil, err
+		}
+		if err := sys.ExecuteProposal(epoch, arg.ID, number, fid); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, accountmanager.ErrUnKnownTxType
 	}
@@ -0,0 +1,258 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/unichainplatform/unichain/consensus"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/types"
+)
+
+const (
+	delaySeenCacheSize  = 1024
+	delayEpochCacheSize = 16
+)
+
+// ProducerRecord performance of candidate in epoch
+type ProducerRecord struct {
+	Candidate   string   `json:"candidate"`
+	Expected    uint64   `json:"expected"`    // slots scheduled
+	Produced    uint64   `json:"produced"`    // blocks produced
+	MissedSlots []uint64 `json:"missedSlots"` // timestamps of slots missed
+	AvgDelay    uint64   `json:"avgDelay"`    // average propagation delay seen locally, nanoseconds
+	Delays      uint64   `json:"delays"`      // blocks the average delay counted
+	GrossReward *big.Int `json:"grossReward"` // rewards of produced blocks before the voters share of the commission
+	Kicked      bool     `json:"kicked"`      // kicked by the system account
+	Replaced    bool     `json:"replaced"`    // replaced by backup candidate
+}
+
+// ProducerReport performance of candidates in epoch
+type ProducerReport struct {
+	Epoch     uint64            `json:"epoch"`
+	Start     uint64            `json:"start"`
+	End       uint64            `json:"end"`
+	Number    uint64            `json:"number"` // blocks in epoch
+	TakeOver  bool              `json:"takeOver"`
+	Producers []*ProducerRecord `json:"producers"`
+}
+
+type delayStat struct {
+	count uint64
+	total uint64
+}
+
+// delayTracker remember the propagation delay of the blocks seen locally
+type delayTracker struct {
+	mu     sync.Mutex
+	seen   *lru.Cache // header hash
+	epochs *lru.Cache // epoch -> candidate -> delay
+}
+
+func newDelayTracker() *delayTracker {
+	seen, _ := lru.New(delaySeenCacheSize)
+	epochs, _ := lru.New(delayEpochCacheSize)
+	return &delayTracker{
+		seen:   seen,
+		epochs: epochs,
+	}
+}
+
+func (tracker *delayTracker) record(epoch uint64, header *types.Header, delay uint64) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if ok, _ := tracker.seen.ContainsOrAdd(header.Hash(), struct{}{}); ok {
+		return
+	}
+	var stats map[string]*delayStat
+	if val, ok := tracker.epochs.Get(epoch); ok {
+		stats = val.(map[string]*delayStat)
+	} else {
+		stats = map[string]*delayStat{}
+		tracker.epochs.Add(epoch, stats)
+	}
+	name := header.Coinbase.String()
+	stat, ok := stats[name]
+	if !ok {
+		stat = &delayStat{}
+		stats[name] = stat
+	}
+	stat.count++
+	stat.total += delay
+}
+
+func (tracker *delayTracker) stat(epoch uint64, name string) delayStat {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if val, ok := tracker.epochs.Get(epoch); ok {
+		if stat, ok := val.(map[string]*delayStat)[name]; ok {
+			return *stat
+		}
+	}
+	return delayStat{}
+}
+
+// recordDelay remember the propagation delay of the header arrived, ignore the old headers when syncing
+func (dpos *Dpos) recordDelay(header *types.Header) {
+	now := uint64(time.Now().UnixNano())
+	delay := uint64(0)
+	if ts := header.Time.Uint64(); now > ts {
+		delay = now - ts
+	}
+	if delay > dpos.config.mepochInterval() {
+		return
+	}
+	dpos.delays.record(dpos.config.epoch(header.Time.Uint64()), header, delay)
+}
+
+// lastHeader return the last header before timestamp
+func lastHeader(chain consensus.IChainReader, timestamp uint64) *types.Header {
+	head := chain.CurrentHeader()
+	if head.Time.Uint64() < timestamp {
+		return head
+	}
+	low, high := uint64(0), head.Number.Uint64()
+	for low < high {
+		mid := (low + high + 1) / 2
+		if header := chain.GetHeaderByNumber(mid); header != nil && header.Time.Uint64() < timestamp {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return chain.GetHeaderByNumber(low)
+}
+
+// ProducerReport report the blocks expected, produced and missed of candidates in epoch
+func (dpos *Dpos) ProducerReport(chain consensus.IChainReader, state *state.StateDB, epoch uint64) (*ProducerReport, error) {
+	head := chain.CurrentHeader()
+	if cur := dpos.config.epoch(head.Time.Uint64()); epoch == 0 {
+		epoch = cur
+	} else if epoch > cur {
+		return nil, fmt.Errorf("epoch %v not reached(current %v)", epoch, cur)
+	}
+
+	cfg, err := dpos.configAt(state, epoch)
+	if err != nil {
+		return nil, err
+	}
+	sys := NewSystem(state, cfg)
+	gstate, err := sys.GetState(epoch)
+	if err != nil {
+		return nil, err
+	}
+	sstate, err := sys.GetState(gstate.PreEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ProducerReport{
+		Epoch:     epoch,
+		Start:     dpos.config.epochTimeStamp(epoch),
+		End:       dpos.config.epochTimeStamp(epoch + 1),
+		TakeOver:  gstate.TakeOver,
+		Producers: []*ProducerRecord{},
+	}
+	records := map[string]*ProducerRecord{}
+	record := func(name string) *ProducerRecord {
+		r, ok := records[name]
+		if !ok {
+			r = &ProducerRecord{Candidate: name, MissedSlots: []uint64{}}
+			records[name] = r
+			report.Producers = append(report.Producers, r)
+		}
+		return r
+	}
+	for offset := uint64(0); offset < dpos.config.CandidateScheduleSize; offset++ {
+		if name := sys.usingCandiate(sstate, offset); name != "" {
+			record(name)
+		}
+	}
+	for _, offset := range sstate.BadCandidateIndexSchedule {
+		if offset < uint64(len(sstate.ActivatedCandidateSchedule)) {
+			record(sstate.ActivatedCandidateSchedule[offset]).Replaced = true
+		}
+	}
+
+	// the headers of epoch in order
+	headers := []*types.Header{}
+	for header := lastHeader(chain, report.End); header != nil && header.Number.Sign() > 0 && header.Time.Uint64() >= report.Start; {
+		headers = append(headers, header)
+		header = chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	for i, j := 0, len(headers)-1; i < j; i, j = i+1, j-1 {
+		headers[i], headers[j] = headers[j], headers[i]
+	}
+	report.Number = uint64(len(headers))
+
+	interval := dpos.config.blockInterval()
+	fid := head.CurForkID()
+	producers := map[uint64]string{} // offset -> the last candidate produced
+	missed := func(from, to uint64) {
+		for ts := from; ts < to; ts += interval {
+			offset := dpos.config.getoffset(ts, fid)
+			name, ok := producers[offset]
+			if !ok {
+				name = sys.usingCandiate(sstate, offset)
+			}
+			if name == "" {
+				continue
+			}
+			r := record(name)
+			r.Expected++
+			r.MissedSlots = append(r.MissedSlots, ts)
+		}
+	}
+	next := report.Start
+	if len(headers) > 0 && headers[0].Number.Uint64() == 1 {
+		// no slots expected before the first block
+		next = headers[0].Time.Uint64()
+	}
+	for _, header := range headers {
+		fid = header.CurForkID()
+		missed(next, header.Time.Uint64())
+		name := header.Coinbase.String()
+		r := record(name)
+		r.Expected++
+		r.Produced++
+		producers[dpos.config.getoffset(header.Time.Uint64(), fid)] = name
+		next = header.Time.Uint64() + interval
+	}
+	if head.Time.Uint64() >= report.End {
+		missed(next, report.End)
+	}
+
+	for _, r := range report.Producers {
+		if stat := dpos.delays.stat(epoch, r.Candidate); stat.count > 0 {
+			r.Delays = stat.count
+			r.AvgDelay = stat.total / stat.count
+		}
+		// finalize pays no extra block reward, its counter is always 0
+		r.GrossReward = new(big.Int).Mul(sys.config.producedReward(0), new(big.Int).SetUint64(r.Produced))
+		candidate, err := sys.GetCandidate(epoch, r.Candidate)
+		if err != nil {
+			return nil, err
+		}
+		r.Kicked = candidate != nil && candidate.Type == Black
+	}
+	return report, nil
+}
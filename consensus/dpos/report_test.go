@@ -0,0 +1,144 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/types"
+)
+
+type testChain struct {
+	consensus.IChainReader
+	headers []*types.Header
+}
+
+func (c *testChain) CurrentHeader() *types.Header {
+	return c.headers[len(c.headers)-1]
+}
+
+func (c *testChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(c.headers)) {
+		return nil
+	}
+	return c.headers[number]
+}
+
+func (c *testChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+
+func (c *testChain) add(coinbase string, timestamp uint64) {
+	header := &types.Header{
+		Coinbase:   common.Name(coinbase),
+		Difficulty: big.NewInt(1),
+		Number:     big.NewInt(int64(len(c.headers))),
+		Time:       new(big.Int).SetUint64(timestamp),
+	}
+	if len(c.headers) > 0 {
+		header.ParentHash = c.CurrentHeader().Hash()
+	}
+	c.headers = append(c.headers, header)
+}
+
+func TestProducerReport(t *testing.T) {
+	// the interval caches of DefaultConfig changed by other tests
+	config := &Config{}
+	if raw, err := json.Marshal(DefaultConfig); err != nil {
+		t.Fatal(err)
+	} else if err := json.Unmarshal(raw, config); err != nil {
+		t.Fatal(err)
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	dpos := New(config, nil)
+	sys := NewSystem(statedb, config)
+
+	pepoch, epoch := uint64(1), uint64(2)
+	if err := sys.SetState(&GlobalState{Epoch: pepoch, PreEpoch: pepoch, ActivatedCandidateSchedule: candidates, ActivatedTotalQuantity: big.NewInt(0), TotalQuantity: big.NewInt(0)}); err != nil {
+		t.Fatalf("SetState --- %v", err)
+	}
+	if err := sys.SetState(&GlobalState{Epoch: epoch, PreEpoch: pepoch, ActivatedTotalQuantity: big.NewInt(0), TotalQuantity: big.NewInt(0)}); err != nil {
+		t.Fatalf("SetState --- %v", err)
+	}
+
+	interval := config.blockInterval()
+	start := config.epochTimeStamp(epoch)
+	owner := func(ts uint64) string {
+		return candidates[config.getoffset(ts, 0)]
+	}
+	chain := &testChain{}
+	chain.add(config.SystemName, start-10*interval)
+	chain.add(owner(start-interval), start-interval)
+	skipped := map[int]bool{7: true, 8: true}
+	for i := 0; i < 18; i++ {
+		if !skipped[i] {
+			chain.add(owner(start+uint64(i)*interval), start+uint64(i)*interval)
+		}
+	}
+
+	if _, err := dpos.ProducerReport(chain, statedb, epoch+1); err == nil {
+		t.Fatal("report of future epoch")
+	}
+	report, err := dpos.ProducerReport(chain, statedb, 0)
+	if err != nil {
+		t.Fatalf("ProducerReport --- %v", err)
+	}
+	if report.Epoch != epoch || report.Number != 16 || len(report.Producers) != len(candidates) {
+		t.Fatalf("report mismatch epoch %v number %v producers %v", report.Epoch, report.Number, len(report.Producers))
+	}
+
+	missed := owner(start + 7*interval)
+	for _, r := range report.Producers {
+		if r.Produced+uint64(len(r.MissedSlots)) != r.Expected {
+			t.Fatalf("%v expected %v, produced %v, missed %v", r.Candidate, r.Expected, r.Produced, r.MissedSlots)
+		}
+		if r.GrossReward.Cmp(new(big.Int).Mul(config.blockReward(), new(big.Int).SetUint64(r.Produced))) != 0 {
+			t.Fatalf("%v reward mismatch %v", r.Candidate, r.GrossReward)
+		}
+		if r.Candidate == missed {
+			if len(r.MissedSlots) != 2 || r.MissedSlots[0] != start+7*interval || r.MissedSlots[1] != start+8*interval {
+				t.Fatalf("%v missed slots mismatch %v", r.Candidate, r.MissedSlots)
+			}
+		} else if len(r.MissedSlots) != 0 {
+			t.Fatalf("%v unexpected missed slots %v", r.Candidate, r.MissedSlots)
+		}
+	}
+}
+
+func TestDelayTracker(t *testing.T) {
+	tracker := newDelayTracker()
+	header := &types.Header{Coinbase: common.Name(candidates[0]), Number: big.NewInt(1), Time: big.NewInt(1)}
+	tracker.record(1, header, 100)
+	tracker.record(1, header, 100)
+	header = &types.Header{Coinbase: common.Name(candidates[0]), Number: big.NewInt(2), Time: big.NewInt(2)}
+	tracker.record(1, header, 300)
+	if stat := tracker.stat(1, candidates[0]); stat.count != 2 || stat.total != 400 {
+		t.Fatalf("delay stat mismatch %v", stat)
+	}
+	if stat := tracker.stat(2, candidates[0]); stat.count != 0 {
+		t.Fatalf("unexpected delay stat %v", stat)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/unichainplatform/unichain/types"
+)
+
+// maxRewardEpochs the most vote epochs settled by one claim, the rest are left for the next claim
+const maxRewardEpochs = 32
+
+// Rewards are shared with the votes that elected the producers: the votes
+// cast during the previous epoch (GlobalState.PreEpoch) decide the schedule
+// of an epoch, so the voters share of a block reward goes to the pool of the
+// candidate in that PreEpoch snapshot. Each voter settles its own pools when
+// claiming, the finalize of a block only adds to the pool of the coinbase.
+
+// SetCommission set the commission rate in percent the candidate keep of its rewards
+func (sys *System) SetCommission(epoch uint64, candidate string, rate uint64) error {
+	if rate > MaxCommission {
+		return fmt.Errorf("invalid commission %v(max %v)", rate, MaxCommission)
+	}
+	prod, err := sys.GetCandidate(epoch, candidate)
+	if err != nil {
+		return err
+	}
+	if prod == nil {
+		return fmt.Errorf("invalid candidate %v(not exist)", candidate)
+	}
+	return sys.IDB.SetCommission(candidate, rate)
+}
+
+// shareReward keep the voters share of reward in the reward pool of the vote snapshot of epoch, return the candidate share
+func (sys *System) shareReward(epoch uint64, candidate string, reward *big.Int, fid uint64) (*big.Int, error) {
+	rate, err := sys.GetCommission(candidate)
+	if err != nil {
+		return nil, err
+	}
+	if rate >= MaxCommission {
+		return reward, nil
+	}
+	gstate, err := sys.GetState(epoch)
+	if err != nil {
+		return nil, err
+	}
+	if gstate == nil {
+		return reward, nil
+	}
+	pool, err := sys.GetRewardPool(gstate.PreEpoch, candidate)
+	if err != nil {
+		return nil, err
+	}
+	if pool == nil {
+		prod, err := sys.GetCandidate(gstate.PreEpoch, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if prod == nil {
+			return reward, nil
+		}
+		pool = &RewardPool{
+			Candidate: candidate,
+			Quantity:  big.NewInt(0),
+			Votes:     new(big.Int).Sub(prod.TotalQuantity, prod.Quantity),
+		}
+	}
+	if pool.Votes.Sign() != 1 {
+		return reward, nil
+	}
+	share := new(big.Int).Div(new(big.Int).Mul(reward, new(big.Int).SetUint64(MaxCommission-rate)), new(big.Int).SetUint64(MaxCommission))
+	if share.Sign() == 0 {
+		return reward, nil
+	}
+	if _, err := sys.IncAsset2Acct(sys.config.SystemName, sys.config.AccountName, share, fid); err != nil {
+		return nil, err
+	}
+	pool.Quantity = new(big.Int).Add(pool.Quantity, share)
+	if err := sys.SetRewardPool(gstate.PreEpoch, pool); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Sub(reward, share), nil
+}
+
+// trackVote remember voter voted in epoch, the pools of epoch are settled when claiming
+func (sys *System) trackVote(epoch uint64, voter string) error {
+	epochs, err := sys.GetRewardEpochs(voter)
+	if err != nil {
+		return err
+	}
+	if len(epochs) > 0 && epochs[len(epochs)-1] == epoch {
+		return nil
+	}
+	return sys.SetRewardEpochs(voter, append(epochs, epoch))
+}
+
+// voteReward the share of voter in the reward pools of the candidates it voted in epoch,
+// the remainder of the integer division stays in the reward account
+func (sys *System) voteReward(epoch uint64, voter string) (*big.Int, error) {
+	votes, err := sys.GetVotersByVoter(epoch, voter)
+	if err != nil {
+		return nil, err
+	}
+	reward := big.NewInt(0)
+	for _, vote := range votes {
+		if vote.Quantity == nil || vote.Quantity.Sign() == 0 {
+			continue
+		}
+		pool, err := sys.GetRewardPool(epoch, vote.Candidate)
+		if err != nil {
+			return nil, err
+		}
+		if pool == nil || pool.Votes.Sign() != 1 {
+			continue
+		}
+		share := new(big.Int).Div(new(big.Int).Mul(pool.Quantity, vote.Quantity), pool.Votes)
+		reward = new(big.Int).Add(reward, share)
+	}
+	return reward, nil
+}
+
+// settleRewards sum the rewards of at most limit vote epochs of voter whose pools are final in epoch,
+// limit 0 means no limit, return the epochs left unsettled
+func (sys *System) settleRewards(epoch uint64, voter string, limit int) (*big.Int, []uint64, error) {
+	gstate, err := sys.GetState(epoch)
+	if err != nil {
+		return nil, nil, err
+	}
+	epochs, err := sys.GetRewardEpochs(voter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if gstate == nil {
+		return big.NewInt(0), epochs, nil
+	}
+	settled := big.NewInt(0)
+	remain := []uint64{}
+	count := 0
+	for _, e := range epochs {
+		// the votes of PreEpoch still earn rewards in epoch
+		if e >= gstate.PreEpoch || (limit > 0 && count == limit) {
+			remain = append(remain, e)
+			continue
+		}
+		count++
+		reward, err := sys.voteReward(e, voter)
+		if err != nil {
+			return nil, nil, err
+		}
+		settled = new(big.Int).Add(settled, reward)
+	}
+	return settled, remain, nil
+}
+
+// ClaimReward transfer the settled rewards to voter
+func (sys *System) ClaimReward(epoch uint64, voter string, number uint64, fid uint64) error {
+	epochs, err := sys.GetRewardEpochs(voter)
+	if err != nil {
+		return err
+	}
+	settled, remain, err := sys.settleRewards(epoch, voter, maxRewardEpochs)
+	if err != nil {
+		return err
+	}
+	if len(remain) == len(epochs) {
+		return fmt.Errorf("no rewards to claim %v", voter)
+	}
+	if err := sys.SetRewardEpochs(voter, remain); err != nil {
+		return err
+	}
+	if settled.Sign() == 0 {
+		return nil
+	}
+	action, err := sys.Undelegate(voter, settled)
+	if err != nil {
+		return fmt.Errorf("undelegate %v failed(%v)", settled, err)
+	}
+	sys.internalActions = append(sys.internalActions, &types.InternalAction{
+		Action: action.NewRPCAction(0),
+	})
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+type undelegateDB struct {
+	*levelDB
+	undelegated *big.Int
+}
+
+func (db *undelegateDB) Undelegate(to string, amount *big.Int) (*types.Action, error) {
+	db.undelegated = new(big.Int).Add(db.undelegated, amount)
+	return types.NewAction(types.Transfer, common.Name(DefaultConfig.AccountName), common.Name(to), 0, DefaultConfig.AssetID, 0, amount, nil, nil), nil
+}
+
+func TestUpdateCandidateCommission(t *testing.T) {
+	old, err := rlp.EncodeToBytes(&struct{ Info string }{"www.candidate.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg := &UpdateCandidate{}
+	if err := rlp.DecodeBytes(old, &arg); err != nil {
+		t.Fatalf("decode payload without commission --- %v", err)
+	} else if arg.Info != "www.candidate.com" || len(arg.Commission) != 0 {
+		t.Fatalf("decode payload without commission mismatch %v", arg)
+	}
+
+	raw, err := rlp.EncodeToBytes(&UpdateCandidate{Info: "www.candidate.com", Commission: []uint64{20}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	arg = &UpdateCandidate{}
+	if err := rlp.DecodeBytes(raw, &arg); err != nil {
+		t.Fatalf("decode payload with commission --- %v", err)
+	} else if len(arg.Commission) != 1 || arg.Commission[0] != 20 {
+		t.Fatalf("decode payload with commission mismatch %v", arg)
+	}
+}
+
+func TestShareReward(t *testing.T) {
+	ldb, function := newTestLDB()
+	defer function()
+	sdb := &undelegateDB{levelDB: ldb, undelegated: big.NewInt(0)}
+	db, _ := NewLDB(sdb)
+	sys := &System{
+		config: DefaultConfig,
+		IDB:    db,
+	}
+
+	// the votes cast in pepoch elected the producers of epoch and share its rewards
+	pepoch, epoch, candidate := uint64(1), uint64(2), candidates[0]
+	if err := db.SetState(&GlobalState{Epoch: epoch, PreEpoch: pepoch, TotalQuantity: big.NewInt(0)}); err != nil {
+		t.Fatalf("SetState --- %v", err)
+	}
+	if err := db.SetCandidate(&CandidateInfo{Epoch: pepoch, Name: candidate, Quantity: big.NewInt(10), TotalQuantity: big.NewInt(14)}); err != nil {
+		t.Fatalf("SetCandidate --- %v", err)
+	}
+	if err := db.SetCandidate(&CandidateInfo{Epoch: epoch, Name: candidate, Quantity: big.NewInt(10), TotalQuantity: big.NewInt(10)}); err != nil {
+		t.Fatalf("SetCandidate --- %v", err)
+	}
+	for index, quantity := range []int64{1, 3} {
+		if err := db.SetVoter(&VoterInfo{Epoch: pepoch, Name: voters[index], Candidate: candidate, Quantity: big.NewInt(quantity)}); err != nil {
+			t.Fatalf("SetVoter --- %v", err)
+		}
+		if err := sys.trackVote(pepoch, voters[index]); err != nil {
+			t.Fatalf("trackVote --- %v", err)
+		}
+	}
+	// a vote of the current epoch only shares the rewards of the next epoch
+	if err := db.SetVoter(&VoterInfo{Epoch: epoch, Name: voters[0], Candidate: candidate, Quantity: big.NewInt(5)}); err != nil {
+		t.Fatalf("SetVoter --- %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := sys.trackVote(epoch, voters[0]); err != nil {
+			t.Fatalf("trackVote --- %v", err)
+		}
+	}
+	if epochs, _ := sys.GetRewardEpochs(voters[0]); len(epochs) != 2 {
+		t.Fatalf("reward epochs mismatch %v", epochs)
+	}
+
+	// candidate keep all rewards by default
+	reward := big.NewInt(1001)
+	if share, err := sys.shareReward(epoch, candidate, reward, 0); err != nil || share.Cmp(reward) != 0 {
+		t.Fatalf("shareReward without commission %v %v", share, err)
+	}
+	if pool, _ := sys.GetRewardPool(pepoch, candidate); pool != nil {
+		t.Fatal("unexpected reward pool")
+	}
+
+	if err := sys.SetCommission(epoch, candidate, MaxCommission+1); err == nil {
+		t.Fatal("invalid commission accepted")
+	}
+	if err := sys.SetCommission(epoch, candidates[1], 10); err == nil {
+		t.Fatal("commission of unknown candidate accepted")
+	}
+	if err := sys.SetCommission(epoch, candidate, 20); err != nil {
+		t.Fatalf("SetCommission --- %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if share, err := sys.shareReward(epoch, candidate, reward, 0); err != nil || share.Cmp(big.NewInt(201)) != 0 {
+			t.Fatalf("shareReward with commission %v %v", share, err)
+		}
+	}
+	if pool, _ := sys.GetRewardPool(pepoch, candidate); pool == nil || pool.Quantity.Cmp(big.NewInt(1600)) != 0 || pool.Votes.Cmp(big.NewInt(4)) != 0 {
+		t.Fatalf("reward pool mismatch %v", pool)
+	}
+
+	// the pool of pepoch is still growing in epoch
+	if err := sys.ClaimReward(epoch, voters[0], 10, 0); err == nil {
+		t.Fatal("claim of unsettled rewards accepted")
+	}
+
+	nepoch := epoch + 1
+	if err := db.SetState(&GlobalState{Epoch: nepoch, PreEpoch: epoch, TotalQuantity: big.NewInt(0)}); err != nil {
+		t.Fatalf("SetState --- %v", err)
+	}
+	if settled, remain, err := sys.settleRewards(nepoch, voters[0], 0); err != nil || settled.Cmp(big.NewInt(400)) != 0 || len(remain) != 1 || remain[0] != epoch {
+		t.Fatalf("settleRewards mismatch %v %v %v", settled, remain, err)
+	}
+	for index, want := range []int64{400, 1200} {
+		sdb.undelegated = big.NewInt(0)
+		if err := sys.ClaimReward(nepoch, voters[index], 11, 0); err != nil {
+			t.Fatalf("ClaimReward --- %v", err)
+		}
+		if sdb.undelegated.Cmp(big.NewInt(want)) != 0 {
+			t.Fatalf("claimed reward of %v mismatch %v, want %v", voters[index], sdb.undelegated, want)
+		}
+	}
+	if len(sys.internalActions) != 2 {
+		t.Fatalf("internal actions mismatch %v", len(sys.internalActions))
+	}
+	if err := sys.ClaimReward(nepoch, voters[0], 12, 0); err == nil {
+		t.Fatal("claim without settled reward accepted")
+	}
+	if epochs, _ := sys.GetRewardEpochs(voters[1]); len(epochs) != 0 {
+		t.Fatalf("reward epochs not cleared %v", epochs)
+	}
+}
@@ -422,6 +422,11 @@ func (sys *System) VoteCandidate(epoch uint64, voter string, candidate string, s
 	if err := sys.SetVoter(voterInfo); err != nil {
 		return err
 	}
+	if fid >= params.ForkID5 {
+		if err := sys.trackVote(epoch, voter); err != nil {
+			return err
+		}
+	}
 
 	prod.TotalQuantity = new(big.Int).Add(prod.TotalQuantity, q)
 
@@ -499,6 +504,79 @@ func (sys *System) KickedCandidate(epoch uint64, candidate string, number uint64
 	return sys.SetCandidate(prod)
 }
 
+// ReportDoubleSign slash the candidate who signed the conflicting headers of evidence for the chain of chainID
+func (sys *System) ReportDoubleSign(epoch uint64, ev *Evidence, chainID *big.Int, number uint64, fid uint64) error {
+	pubkey, err := ev.Verify(chainID)
+	if err != nil {
+		return err
+	}
+	// headers of the current and the previous epoch only
+	timestamp := ev.HeaderA.Time.Uint64()
+	if hepoch := sys.config.epoch(timestamp); hepoch > epoch || hepoch+1 < epoch {
+		return errEvidenceExpired
+	}
+	candidate := ev.Candidate()
+	if strings.Compare(candidate, sys.config.SystemName) == 0 {
+		return fmt.Errorf("no permission for slashing %v", candidate)
+	}
+	if err := sys.CanMineAt(candidate, pubkey, timestamp); err != nil {
+		return fmt.Errorf("invalid evidence signer(%v)", err)
+	}
+	hash := ev.Hash()
+	if reported, err := sys.HasEvidence(hash); err != nil {
+		return err
+	} else if reported {
+		return fmt.Errorf("evidence %v already reported", hash.Hex())
+	}
+
+	// name validity
+	prod, err := sys.GetCandidate(epoch, candidate)
+	if err != nil {
+		return err
+	}
+	if prod == nil {
+		return fmt.Errorf("invalid candidate %v(not exist)", candidate)
+	}
+
+	// db
+	q := new(big.Int).Div(new(big.Int).Mul(prod.Quantity, new(big.Int).SetUint64(sys.config.slashRate())), big.NewInt(100))
+	if q.Sign() == 1 {
+		stake := new(big.Int).Mul(q, sys.config.unitStake())
+		action, err := sys.Undelegate(sys.config.SystemName, stake)
+		if err != nil {
+			return fmt.Errorf("undelegate %v failed(%v)", stake, err)
+		}
+		sys.internalActions = append(sys.internalActions, &types.InternalAction{
+			Action: action.NewRPCAction(0),
+		})
+
+		valid := !prod.invalid()
+		prod.Quantity = new(big.Int).Sub(prod.Quantity, q)
+		prod.TotalQuantity = new(big.Int).Sub(prod.TotalQuantity, q)
+		if valid {
+			gstate, err := sys.GetState(epoch)
+			if err != nil {
+				return err
+			}
+			gstate.TotalQuantity = new(big.Int).Sub(gstate.TotalQuantity, q)
+			if fid >= params.ForkID2 {
+				if err := sys.updateState(gstate, prod); err != nil {
+					return err
+				}
+			}
+			if err := sys.SetState(gstate); err != nil {
+				return err
+			}
+		}
+	}
+
+	prod.Number = number
+	if err := sys.SetCandidate(prod); err != nil {
+		return err
+	}
+	return sys.SetEvidence(hash)
+}
+
 // RemoveKickedCandidate remove
 func (sys *System) RemoveKickedCandidate(epoch uint64, candidate string, number uint64, fid uint64) error {
 	// name validity
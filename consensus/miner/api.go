@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"github.com/unichainplatform/unichain/common"
 	"github.com/unichainplatform/unichain/consensus"
 	"github.com/unichainplatform/unichain/rpc"
 )
@@ -52,6 +53,16 @@ func (api *API) SetCoinbase(name string, privKeys []string) error {
 	return api.miner.SetCoinbase(name, privKeys)
 }
 
+// SetCoinbaseFromKeystore bind miner name & unlocked keystore keys of node
+func (api *API) SetCoinbaseFromKeystore(name string, pubKeys []common.PubKey) error {
+	return api.miner.SetCoinbaseFromKeystore(name, pubKeys)
+}
+
+// SetRemoteSigner bind miner name & the keys of the remote signer at url
+func (api *API) SetRemoteSigner(name string, url string) error {
+	return api.miner.SetRemoteSigner(name, url)
+}
+
 // SetDelay delay broacast block when mint block
 func (api *API) SetDelay(delayDuration uint64) error {
 	return api.miner.SetDelayDuration(delayDuration)
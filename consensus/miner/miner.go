@@ -23,14 +23,18 @@ import (
 	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/unichainplatform/unichain/common"
 	"github.com/unichainplatform/unichain/consensus"
 	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/keystore"
 	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/signer"
 )
 
 // Miner creates blocks and searches for proof values.
 type Miner struct {
-	worker *Worker
+	worker     *Worker
+	keyManager *keystore.Manager
 
 	mining      int32 // 0: stoped; 1: starting; 2: started; 3: stopping
 	canStart    int32 // can start indicates whether we can start the mining operation
@@ -134,6 +138,46 @@ func (miner *Miner) SetCoinbase(name string, privKeys []string) error {
 	return nil
 }
 
+// SetKeyManager sets the key manager the coinbase keys are taken from by
+// SetCoinbaseFromKeystore.
+func (miner *Miner) SetKeyManager(km *keystore.Manager) {
+	miner.keyManager = km
+}
+
+// SetCoinbaseFromKeystore coinbase name & the unlocked keystore keys of pubKeys
+func (miner *Miner) SetCoinbaseFromKeystore(name string, pubKeys []common.PubKey) error {
+	if miner.keyManager == nil {
+		return fmt.Errorf("no key manager")
+	}
+	privs := make([]*ecdsa.PrivateKey, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		priv, err := miner.keyManager.UnlockedKey(pubKey)
+		if err != nil {
+			return fmt.Errorf("%v: %v", pubKey.String(), err)
+		}
+		privs = append(privs, priv)
+	}
+
+	miner.worker.setCoinbase(name, privs)
+	return nil
+}
+
+// SetRemoteSigner coinbase name & the keys of the remote signer at url
+func (miner *Miner) SetRemoteSigner(name string, url string) error {
+	remote, err := signer.Dial(url)
+	if err != nil {
+		return err
+	}
+	pubKeys, err := remote.PubKeys()
+	if err != nil {
+		remote.Close()
+		return err
+	}
+
+	miner.worker.setRemoteCoinbase(name, remote, pubKeys)
+	return nil
+}
+
 // SetDelayDuration delay broacast block when mint block (unit:ms)
 func (miner *Miner) SetDelayDuration(delayDuration uint64) error {
 	return miner.worker.setDelayDuration(delayDuration)
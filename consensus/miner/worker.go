@@ -37,6 +37,7 @@ import (
 	"github.com/unichainplatform/unichain/params"
 	"github.com/unichainplatform/unichain/processor"
 	"github.com/unichainplatform/unichain/processor/vm"
+	"github.com/unichainplatform/unichain/signer"
 	"github.com/unichainplatform/unichain/state"
 	"github.com/unichainplatform/unichain/types"
 )
@@ -57,6 +58,7 @@ type Worker struct {
 	coinbase      string
 	privKeys      []*ecdsa.PrivateKey
 	pubKeys       [][]byte
+	remote        *signer.Client // signs with pubKeys instead of privKeys if set
 	extra         []byte
 
 	wg        sync.WaitGroup
@@ -124,11 +126,14 @@ func (worker *Worker) mintLoop() {
 	if !ok {
 		panic("only support dpos engine")
 	}
-	cdpos.SetSignFn(func(content []byte, state *state.StateDB) ([]byte, error) {
+	cdpos.SetSignFn(func(content []byte, header *types.Header, state *state.StateDB) ([]byte, error) {
 		sys := dpos.NewSystem(state, cdpos.Config())
-		for index, privKey := range worker.privKeys {
-			if err := sys.CanMine(worker.coinbase, worker.pubKeys[index]); err == nil {
-				return crypto.Sign(content, privKey)
+		for index, pubKey := range worker.pubKeys {
+			if err := sys.CanMine(worker.coinbase, pubKey); err == nil {
+				if worker.remote != nil {
+					return worker.remote.SignHeader(header, worker.Config().ChainID, common.BytesToPubKey(pubKey))
+				}
+				return crypto.Sign(content, worker.privKeys[index])
 			}
 		}
 		return nil, fmt.Errorf("not found match private key for sign")
@@ -236,16 +241,37 @@ func (worker *Worker) setDelayDuration(delay uint64) error {
 }
 
 func (worker *Worker) setCoinbase(name string, privKeys []*ecdsa.PrivateKey) {
+	pubKeys := make([][]byte, 0, len(privKeys))
+	for _, privkey := range privKeys {
+		pubKeys = append(pubKeys, crypto.FromECDSAPub(&privkey.PublicKey))
+	}
+	worker.setSigningKeys(name, pubKeys, privKeys, nil)
+}
+
+// setRemoteCoinbase sets the coinbase whose blocks are signed by the remote
+// signer with pubKeys.
+func (worker *Worker) setRemoteCoinbase(name string, remote *signer.Client, pubKeys []common.PubKey) {
+	keys := make([][]byte, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		keys = append(keys, pubKey.Bytes())
+	}
+	worker.setSigningKeys(name, keys, nil, remote)
+}
+
+func (worker *Worker) setSigningKeys(name string, pubKeys [][]byte, privKeys []*ecdsa.PrivateKey, remote *signer.Client) {
 	state, _ := worker.StateAt(worker.CurrentHeader().Root)
 	cdpos := worker.Engine().(*dpos.Dpos)
 	sys := dpos.NewSystem(state, cdpos.Config())
 	worker.mu.Lock()
 	defer worker.mu.Unlock()
+	if worker.remote != nil && worker.remote != remote {
+		worker.remote.Close()
+	}
 	worker.coinbase = name
 	worker.privKeys = privKeys
+	worker.remote = remote
 	worker.pubKeys = nil
-	for index, privkey := range privKeys {
-		pubkey := crypto.FromECDSAPub(&privkey.PublicKey)
+	for index, pubkey := range pubKeys {
 		if err := sys.CanMine(name, pubkey); err == nil {
 			log.Info("setCoinbase[valid]", "coinbase", name, fmt.Sprintf("pubKey_%03d", index), common.BytesToPubKey(pubkey).String())
 		} else {
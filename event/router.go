@@ -90,6 +90,11 @@ const (
 	P2PBlockHashMsg                  // 10 BlockHash response
 	P2PNewBlockHashesMsg             // 11 NewBlockHash notify
 	P2PTxMsg                         // 12 TxMsg notify
+	P2PGetNodeDataMsg                // 13 State trie nodes request
+	P2PNodeDataMsg                   // 14 State trie nodes response
+	P2PGetReceiptsMsg                // 15 Block receipts request
+	P2PReceiptsMsg                   // 16 Block receipts response
+	P2PEvidenceMsg                   // 17 Double sign evidence notify
 	P2PEndSize
 	ChainHeadEv         = 1023 + iota - P2PEndSize // 1024 when blockchain insert or miner mined new block
 	NewPeerNotify                                  // 1025 emit when remote peer incoming but needed to check chainID and genesis block
@@ -99,6 +104,7 @@ const (
 	OneMinuteLimited                               // 1029 add peer to blacklist
 	NewMinedEv                                     // 1030 emit when new block was mined
 	NewTxs                                         // 1031 emit when new transactions needed to broadcast
+	NewEvidenceEv                                  // 1032 emit when double sign evidence was detected
 	EndSize
 )
 
@@ -111,6 +117,9 @@ var typeLimit = [P2PEndSize]int{
 	P2PGetBlockHeadersMsg: 64,
 	P2PGetBlockBodiesMsg:  64,
 	P2PNewBlockHashesMsg:  3,
+	P2PGetNodeDataMsg:     32,
+	P2PGetReceiptsMsg:     32,
+	P2PEvidenceMsg:        8,
 }
 
 // ReplyEvent is equivalent to `SendTo(e.To, e.From, typecode, data)`
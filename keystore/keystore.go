@@ -0,0 +1,223 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+)
+
+var (
+	// ErrNoMatch is returned when no key file of the public key is in the keystore.
+	ErrNoMatch = errors.New("no key for given public key")
+	// ErrDecrypt is returned when the passphrase of a key file is wrong.
+	ErrDecrypt = errors.New("could not decrypt key with given passphrase")
+	// ErrExists is returned when the key is already in the keystore.
+	ErrExists = errors.New("key already exists")
+)
+
+// KeyStore manages the encrypted key files of a directory.
+type KeyStore struct {
+	dir     string
+	scryptN int
+	scryptP int
+}
+
+// NewKeyStore returns a keystore of dir, keys are encrypted with the scrypt
+// parameters N and P.
+func NewKeyStore(dir string, scryptN, scryptP int) *KeyStore {
+	return &KeyStore{dir: dir, scryptN: scryptN, scryptP: scryptP}
+}
+
+// Dir returns the directory of the keystore.
+func (ks *KeyStore) Dir() string { return ks.dir }
+
+// NewKey generates a new key and stores it encrypted with passphrase.
+func (ks *KeyStore) NewKey(passphrase string) (*Key, error) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return ks.ImportECDSA(priv, passphrase)
+}
+
+// ImportECDSA stores priv encrypted with passphrase.
+func (ks *KeyStore) ImportECDSA(priv *ecdsa.PrivateKey, passphrase string) (*Key, error) {
+	key := NewKey(priv)
+	if _, err := ks.find(key.PubKey); err == nil {
+		return nil, ErrExists
+	}
+	if err := ks.storeKey(key, passphrase); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Keys returns the public keys of all key files, sorted by file name.
+func (ks *KeyStore) Keys() ([]common.PubKey, error) {
+	files, err := ks.keyFiles()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]common.PubKey, 0, len(files))
+	for _, file := range files {
+		keys = append(keys, file.pubKey)
+	}
+	return keys, nil
+}
+
+// HasKey reports whether the key file of pubKey is in the keystore.
+func (ks *KeyStore) HasKey(pubKey common.PubKey) bool {
+	_, err := ks.find(pubKey)
+	return err == nil
+}
+
+// GetKey decrypts the key of pubKey with passphrase.
+func (ks *KeyStore) GetKey(pubKey common.PubKey, passphrase string) (*Key, error) {
+	path, err := ks.find(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	keyjson, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKey(keyjson, passphrase)
+}
+
+// Update changes the passphrase of the key of pubKey.
+func (ks *KeyStore) Update(pubKey common.PubKey, passphrase, newPassphrase string) error {
+	key, err := ks.GetKey(pubKey, passphrase)
+	if err != nil {
+		return err
+	}
+	path, err := ks.find(pubKey)
+	if err != nil {
+		return err
+	}
+	keyjson, err := EncryptKey(key, newPassphrase, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(path, keyjson)
+}
+
+// Delete removes the key file of pubKey if passphrase decrypts it.
+func (ks *KeyStore) Delete(pubKey common.PubKey, passphrase string) error {
+	if _, err := ks.GetKey(pubKey, passphrase); err != nil {
+		return err
+	}
+	path, err := ks.find(pubKey)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (ks *KeyStore) storeKey(key *Key, passphrase string) error {
+	keyjson, err := EncryptKey(key, passphrase, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(filepath.Join(ks.dir, keyFileName(key.PubKey)), keyjson)
+}
+
+type keyFile struct {
+	path   string
+	pubKey common.PubKey
+}
+
+func (ks *KeyStore) keyFiles() ([]keyFile, error) {
+	fis, err := ioutil.ReadDir(ks.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []keyFile
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		path := filepath.Join(ks.dir, name)
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var k struct {
+			PubKey string `json:"pubkey"`
+		}
+		if err := json.Unmarshal(content, &k); err != nil || !common.IsHexPubKey(k.PubKey) {
+			continue
+		}
+		files = append(files, keyFile{path: path, pubKey: common.HexToPubKey(k.PubKey)})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+func (ks *KeyStore) find(pubKey common.PubKey) (string, error) {
+	files, err := ks.keyFiles()
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if file.pubKey == pubKey {
+			return file.path, nil
+		}
+	}
+	return "", ErrNoMatch
+}
+
+// keyFileName returns the file name of the key of pubKey, prefixed with the
+// creation time so that key files sort by age.
+func keyFileName(pubKey common.PubKey) string {
+	ts := time.Now().UTC()
+	return fmt.Sprintf("UTC--%s--%x", ts.Format("2006-01-02T15-04-05.000000000Z"), pubKey[:])
+}
+
+// writeKeyFile writes content through a temporary file, so that a key file is
+// never left half written.
+func writeKeyFile(file string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	f.Close()
+	return os.Rename(f.Name(), file)
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/unichainplatform/unichain/crypto"
+)
+
+func TestEncryptDecryptKey(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := NewKey(priv)
+	keyjson, err := EncryptKey(key, "foo", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptKey(keyjson, "bar"); err != ErrDecrypt {
+		t.Fatalf("decrypt with wrong passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	decrypted, err := DecryptKey(keyjson, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.PubKey != key.PubKey || decrypted.PrivateKey.D.Cmp(priv.D) != 0 {
+		t.Fatal("decrypted key mismatch")
+	}
+}
+
+func TestKeyStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ks := NewKeyStore(dir, LightScryptN, LightScryptP)
+
+	if keys, err := ks.Keys(); err != nil || len(keys) != 0 {
+		t.Fatalf("empty keystore: have %v %v", keys, err)
+	}
+	key, err := ks.NewKey("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.ImportECDSA(key.PrivateKey, "bar"); err != ErrExists {
+		t.Fatalf("import existing key: have %v, want %v", err, ErrExists)
+	}
+	keys, err := ks.Keys()
+	if err != nil || len(keys) != 1 || keys[0] != key.PubKey {
+		t.Fatalf("keys mismatch: have %v %v", keys, err)
+	}
+
+	if err := ks.Update(key.PubKey, "foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.GetKey(key.PubKey, "foo"); err != ErrDecrypt {
+		t.Fatalf("get with old passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	if _, err := ks.GetKey(key.PubKey, "bar"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ks.Delete(key.PubKey, "foo"); err != ErrDecrypt {
+		t.Fatalf("delete with wrong passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	if err := ks.Delete(key.PubKey, "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if ks.HasKey(key.PubKey) {
+		t.Fatal("key not deleted")
+	}
+	if _, err := ks.GetKey(key.PubKey, "bar"); err != ErrNoMatch {
+		t.Fatalf("get deleted key: have %v, want %v", err, ErrNoMatch)
+	}
+}
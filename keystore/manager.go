@@ -0,0 +1,133 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+)
+
+// ErrLocked is returned when the key is not unlocked.
+var ErrLocked = errors.New("key is locked")
+
+type unlocked struct {
+	*Key
+	abort chan struct{}
+}
+
+// Manager is a keystore keeping unlocked keys in memory for the node, keys
+// can be unlocked until locked or for a limited time.
+type Manager struct {
+	*KeyStore
+	mu       sync.RWMutex
+	unlocked map[common.PubKey]*unlocked
+}
+
+// NewManager returns a key manager of ks.
+func NewManager(ks *KeyStore) *Manager {
+	return &Manager{
+		KeyStore: ks,
+		unlocked: make(map[common.PubKey]*unlocked),
+	}
+}
+
+// Unlock unlocks the key of pubKey until Lock is called.
+func (m *Manager) Unlock(pubKey common.PubKey, passphrase string) error {
+	return m.TimedUnlock(pubKey, passphrase, 0)
+}
+
+// TimedUnlock unlocks the key of pubKey, it is locked again after timeout. A
+// timeout of 0 unlocks the key until Lock is called. Unlocking an unlocked key
+// replaces its timeout.
+func (m *Manager) TimedUnlock(pubKey common.PubKey, passphrase string, timeout time.Duration) error {
+	key, err := m.GetKey(pubKey, passphrase)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if u, ok := m.unlocked[pubKey]; ok && u.abort != nil {
+		close(u.abort)
+	}
+	u := &unlocked{Key: key}
+	if timeout > 0 {
+		u.abort = make(chan struct{})
+		go m.expire(pubKey, u, timeout)
+	}
+	m.unlocked[pubKey] = u
+	return nil
+}
+
+func (m *Manager) expire(pubKey common.PubKey, u *unlocked, timeout time.Duration) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-u.abort:
+	case <-t.C:
+		m.mu.Lock()
+		if m.unlocked[pubKey] == u {
+			delete(m.unlocked, pubKey)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// Lock removes the unlocked key of pubKey from memory.
+func (m *Manager) Lock(pubKey common.PubKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if u, ok := m.unlocked[pubKey]; ok {
+		if u.abort != nil {
+			close(u.abort)
+		}
+		delete(m.unlocked, pubKey)
+	}
+}
+
+// IsUnlocked reports whether the key of pubKey is unlocked.
+func (m *Manager) IsUnlocked(pubKey common.PubKey) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.unlocked[pubKey]
+	return ok
+}
+
+// UnlockedKey returns the private key of pubKey if it is unlocked.
+func (m *Manager) UnlockedKey(pubKey common.PubKey) (*ecdsa.PrivateKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	u, ok := m.unlocked[pubKey]
+	if !ok {
+		return nil, ErrLocked
+	}
+	return u.PrivateKey, nil
+}
+
+// SignHash signs hash with the unlocked key of pubKey.
+func (m *Manager) SignHash(pubKey common.PubKey, hash []byte) ([]byte, error) {
+	priv, err := m.UnlockedKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(hash, priv)
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/unichainplatform/unichain/crypto"
+)
+
+func TestManagerUnlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore-manager-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	m := NewManager(NewKeyStore(dir, LightScryptN, LightScryptP))
+
+	key, err := m.NewKey("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := crypto.Keccak256([]byte("content"))
+	if _, err := m.SignHash(key.PubKey, hash); err != ErrLocked {
+		t.Fatalf("sign with locked key: have %v, want %v", err, ErrLocked)
+	}
+	if err := m.Unlock(key.PubKey, "bar"); err != ErrDecrypt {
+		t.Fatalf("unlock with wrong passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+
+	if err := m.Unlock(key.PubKey, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := m.SignHash(key.PubKey, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub, err := crypto.Ecrecover(hash, sig); err != nil || string(pub) != string(key.PubKey.Bytes()) {
+		t.Fatalf("signature of wrong key: %v", err)
+	}
+	m.Lock(key.PubKey)
+	if m.IsUnlocked(key.PubKey) {
+		t.Fatal("key unlocked after lock")
+	}
+
+	if err := m.TimedUnlock(key.PubKey, "foo", 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if !m.IsUnlocked(key.PubKey) {
+		t.Fatal("key locked before timeout")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if m.IsUnlocked(key.PubKey) {
+		t.Fatal("key unlocked after timeout")
+	}
+
+	// unlocking without timeout cancels a pending timeout
+	if err := m.TimedUnlock(key.PubKey, "foo", 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Unlock(key.PubKey, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if !m.IsUnlocked(key.PubKey) {
+		t.Fatal("key locked by replaced timeout")
+	}
+}
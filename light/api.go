@@ -0,0 +1,92 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"context"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/rpcapi"
+	"github.com/unichainplatform/unichain/types"
+)
+
+// PublicLightAPI provides an API to access the light chain.
+type PublicLightAPI struct {
+	b *APIBackend
+}
+
+// NewPublicLightAPI creates a new light chain API.
+func NewPublicLightAPI(b *APIBackend) *PublicLightAPI {
+	return &PublicLightAPI{b}
+}
+
+// GetCurrentBlock returns the current head header.
+func (s *PublicLightAPI) GetCurrentBlock(ctx context.Context) (map[string]interface{}, error) {
+	return s.rpcOutputBlock(s.b.ls.lightchain.CurrentHeader(), false)
+}
+
+// GetBlockByHash returns the requested block. When fullTx is true the body is
+// retrieved from peers and all transactions are returned in full detail,
+// otherwise only the header fields are returned.
+func (s *PublicLightAPI) GetBlockByHash(ctx context.Context, blockHash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	header := s.b.HeaderByHash(ctx, blockHash)
+	if header == nil {
+		return nil, nil
+	}
+	return s.rpcOutputBlock(header, fullTx)
+}
+
+// GetBlockByNumber returns the requested block. When blockNr is -1 the chain head is returned.
+func (s *PublicLightAPI) GetBlockByNumber(ctx context.Context, blockNr rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	header := s.b.HeaderByNumber(ctx, blockNr)
+	if header == nil {
+		return nil, nil
+	}
+	return s.rpcOutputBlock(header, fullTx)
+}
+
+// GetBlockReceipts returns the receipts of all transactions in the block, they
+// are retrieved from peers and verified against the header.
+func (s *PublicLightAPI) GetBlockReceipts(ctx context.Context, blockHash common.Hash) ([]*types.RPCReceipt, error) {
+	block, err := s.b.ls.lightchain.RetrieveBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := s.b.ls.lightchain.RetrieveReceipts(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	rpcReceipts := make([]*types.RPCReceipt, len(receipts))
+	for i, receipt := range receipts {
+		rpcReceipts[i] = receipt.NewRPCReceipt(blockHash, block.NumberU64(), uint64(i), block.Transactions()[i])
+	}
+	return rpcReceipts, nil
+}
+
+func (s *PublicLightAPI) rpcOutputBlock(header *types.Header, fullTx bool) (map[string]interface{}, error) {
+	block := types.NewBlockWithHeader(header)
+	if fullTx {
+		var err error
+		if block, err = s.b.ls.lightchain.RetrieveBlock(header.Hash()); err != nil {
+			return nil, err
+		}
+	}
+	fields := rpcapi.RPCMarshalBlock(s.b.ChainConfig().ChainID, block, fullTx, fullTx)
+	fields["totalDifficulty"] = s.b.GetTd(header.Hash())
+	return fields, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/unichainplatform/unichain/accountmanager"
+	"github.com/unichainplatform/unichain/blockchain"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/types"
+)
+
+// APIBackend implements the account and bc api backends for light clients.
+type APIBackend struct {
+	ls *LightService
+}
+
+// ChainConfig returns the active chain configuration.
+func (b *APIBackend) ChainConfig() *params.ChainConfig {
+	return b.ls.chainConfig
+}
+
+func (b *APIBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) *types.Header {
+	if blockNr == rpc.LatestBlockNumber {
+		return b.ls.lightchain.CurrentHeader()
+	}
+	return b.ls.lightchain.GetHeaderByNumber(uint64(blockNr))
+}
+
+func (b *APIBackend) HeaderByHash(ctx context.Context, hash common.Hash) *types.Header {
+	return b.ls.lightchain.GetHeaderByHash(hash)
+}
+
+// StateAndHeaderByNumber returns the state of the header, its trie nodes are
+// retrieved from peers on demand.
+func (b *APIBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header := b.HeaderByNumber(ctx, blockNr)
+	if header == nil {
+		return nil, nil, nil
+	}
+	stateDb, err := b.ls.lightchain.StateAt(header.Root)
+	return stateDb, header, err
+}
+
+func (b *APIBackend) GetAccountManager() (*accountmanager.AccountManager, error) {
+	sdb, err := b.ls.lightchain.State()
+	if err != nil {
+		return nil, err
+	}
+	return accountmanager.NewAccountManager(sdb)
+}
+
+func (b *APIBackend) ForkStatus(statedb *state.StateDB) (*blockchain.ForkConfig, blockchain.ForkInfo, error) {
+	return b.ls.lightchain.ForkStatus(statedb)
+}
+
+// SetStatePruning does nothing, the light client keeps no state to prune.
+func (b *APIBackend) SetStatePruning(enable bool) (bool, uint64) {
+	return false, b.ls.lightchain.CurrentHeader().Number.Uint64()
+}
+
+func (b *APIBackend) GetTd(hash common.Hash) *big.Int {
+	if header := b.ls.lightchain.GetHeaderByHash(hash); header != nil {
+		return b.ls.lightchain.GetTd(hash, header.Number.Uint64())
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package light implements the header only light client service.
+package light
+
+import (
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/unichainplatform/unichain/blockchain"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	router "github.com/unichainplatform/unichain/event"
+	"github.com/unichainplatform/unichain/node"
+	"github.com/unichainplatform/unichain/p2p"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/rpcapi"
+	"github.com/unichainplatform/unichain/txpool"
+	"github.com/unichainplatform/unichain/uniservice"
+	"github.com/unichainplatform/unichain/utils/fdb"
+)
+
+// LightService implements the light client service. It syncs headers only and
+// retrieves state and receipts from full peers on demand.
+type LightService struct {
+	config      *uniservice.Config
+	chainConfig *params.ChainConfig
+	chainDb     fdb.Database // Header chain database
+	lightchain  *blockchain.LightChain
+	engine      *dpos.Dpos
+	txCh        chan *router.Event
+	txSub       router.Subscription
+	quit        chan struct{}
+	APIBackend  *APIBackend
+}
+
+// New creates a new light service.
+func New(ctx *node.ServiceContext, config *uniservice.Config) (*LightService, error) {
+	chainDb, err := ctx.OpenDatabase("lightchaindata", config.DatabaseCache, config.DatabaseHandles)
+	if err != nil {
+		return nil, err
+	}
+
+	chainCfg, dposCfg, _, err := blockchain.SetupGenesisBlock(chainDb, config.Genesis, false)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.AppendBootNodes(chainCfg.BootNodes)
+
+	lightchain, err := blockchain.NewLightChain(chainDb, chainCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ls := &LightService{
+		config:      config,
+		chainConfig: chainCfg,
+		chainDb:     chainDb,
+		lightchain:  lightchain,
+		engine:      dpos.New(dposCfg, lightchain),
+		txCh:        make(chan *router.Event),
+		quit:        make(chan struct{}),
+	}
+	lightchain.SetEngine(ls.engine)
+
+	// Full peers broadcast transactions to every peer, they are dropped here
+	// as a light client keeps no pool.
+	ls.txSub = router.Subscribe(nil, ls.txCh, router.P2PTxMsg, []*txpool.TransactionWithPath{})
+	go ls.dropTxs()
+
+	ls.APIBackend = &APIBackend{ls: ls}
+	return ls, nil
+}
+
+func (ls *LightService) dropTxs() {
+	for {
+		select {
+		case <-ls.quit:
+			return
+		case <-ls.txCh:
+		}
+	}
+}
+
+// APIs return the collection of RPC services the light service offers.
+func (ls *LightService) APIs() []rpc.API {
+	apis := []rpc.API{
+		{
+			Namespace: "uni",
+			Version:   "1.0",
+			Service:   NewPublicLightAPI(ls.APIBackend),
+			Public:    true,
+		},
+		{
+			Namespace: "account",
+			Version:   "1.0",
+			Service:   rpcapi.NewAccountAPI(ls.APIBackend),
+			Public:    true,
+		},
+		{
+			Namespace: "bc",
+			Version:   "1.0",
+			Service:   rpcapi.NewPrivateBlockChainAPI(ls.APIBackend),
+		},
+	}
+	return append(apis, ls.engine.APIs(ls.lightchain)...)
+}
+
+// Start implements node.Service, starting all internal goroutines.
+func (ls *LightService) Start() error {
+	log.Info("start unichain light service...")
+	return nil
+}
+
+// Stop implements node.Service, terminating all internal goroutine
+func (ls *LightService) Stop() error {
+	ls.txSub.Unsubscribe()
+	close(ls.quit)
+	ls.lightchain.Stop()
+	ls.chainDb.Close()
+	log.Info("light service stopped")
+	return nil
+}
+
+func (ls *LightService) LightChain() *blockchain.LightChain { return ls.lightchain }
+func (ls *LightService) Engine() *dpos.Dpos                 { return ls.engine }
+func (ls *LightService) ChainDb() fdb.Database              { return ls.chainDb }
+func (ls *LightService) Protocols() []p2p.Protocol          { return nil }
@@ -79,6 +79,7 @@ type DposConfig struct {
 	FreezeEpochSize               uint64   `json:"freezeEpochSize"`
 	ExtraBlockReward              *big.Int `json:"extraBlockReward"`
 	BlockReward                   *big.Int `json:"blockReward"`
+	SlashRate                     uint64   `json:"slashRate,omitempty"` // percent of stake slashed for double signing
 }
 
 var DefaultChainconfig = &ChainConfig{
@@ -154,7 +155,9 @@ const (
 	ForkID3 = uint64(3)
 	//ForkID4 miner pubkey separate
 	ForkID4 = uint64(4)
+	//ForkID5 extcodehash, chainid, selfbalance, storage gas repricing, asset allowances, create2, voter rewards, governance, locked transfers and double sign slashing
+	ForkID5 = uint64(5)
 
 	// NextForkID is the id of next fork
-	NextForkID uint64 = ForkID4
+	NextForkID uint64 = ForkID5
 )
@@ -28,6 +28,7 @@ type GasTable struct {
 
 	ExtcodeSize          uint64
 	ExtcodeCopy          uint64
+	ExtcodeHash          uint64
 	Balance              uint64
 	SLoad                uint64
 	Calls                uint64
@@ -56,6 +57,8 @@ type GasTable struct {
 	GetCandidateNum uint64
 	GetCandidate    uint64
 	GetVoterStake   uint64
+	ApproveAsset    uint64
+	GetAllowance    uint64
 
 	Sha3Gas        uint64
 	Sha3WordGas    uint64
@@ -81,6 +84,7 @@ var (
 
 		ExtcodeSize: 700,
 		ExtcodeCopy: 700,
+		ExtcodeHash: 400,
 		Balance:     400,
 		SLoad:       200,
 		Calls:       700,
@@ -104,6 +108,8 @@ var (
 		GetCandidateNum: 200,
 		GetCandidate:    200,
 		GetVoterStake:   200,
+		ApproveAsset:    5000,
+		GetAllowance:    200,
 
 		TxDataNonZeroGas: 68,
 		TxDataZeroGas:    4,
@@ -125,4 +131,16 @@ var (
 		CreateGas:      32000,
 		MemoryGas:      3,
 	}
+
+	// GasTableForkID5 contain the gas re-prices of the state access opcodes
+	// since ForkID5
+	GasTableForkID5 = gasTableForkID5()
 )
+
+func gasTableForkID5() GasTable {
+	gt := GasTableInstance
+	gt.ExtcodeHash = 700
+	gt.Balance = 700
+	gt.SLoad = 800
+	return gt
+}
@@ -36,6 +36,9 @@ type Validator interface {
 	// ValidateState validates the given statedb and optionally the receipts and
 	// gas used.
 	ValidateState(block, parent *types.Block, state *state.StateDB, receipts []*types.Receipt, usedGas uint64) error
+
+	// WithChain returns a validator of the same engine validating against chain.
+	WithChain(chain ChainContext) Validator
 }
 
 // Processor is an interface for processing blocks using a given initial state.
@@ -135,6 +135,9 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	switch {
 	case actionType == types.CreateContract:
 		ret, st.gas, vmerr = evm.Create(sender, st.action, st.gas)
+	case actionType == types.Create2Contract && evm.ForkID >= params.ForkID5:
+		// before ForkID5 the account manager refuses it as an undefined action
+		ret, st.gas, vmerr = evm.Create2(sender, st.action, st.gas)
 	case actionType == types.CallContract:
 		ret, st.gas, vmerr = evm.Call(sender, st.action, st.gas)
 	case actionType == types.Transfer:
@@ -178,6 +181,16 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		fallthrough
 	case actionType == types.UpdateCandidatePubKey:
 		fallthrough
+	case actionType == types.ReportDoubleSign:
+		fallthrough
+	case actionType == types.ClaimReward:
+		fallthrough
+	case actionType == types.CreateProposal:
+		fallthrough
+	case actionType == types.VoteProposal:
+		fallthrough
+	case actionType == types.ExecuteProposal:
+		fallthrough
 	case actionType == types.UnregCandidate:
 		fallthrough
 	case actionType == types.VoteCandidate:
@@ -285,6 +298,8 @@ func (st *StateTransition) distributeGas(intrinsicGas uint64) {
 
 	case types.CreateContract:
 		fallthrough
+	case types.Create2Contract:
+		fallthrough
 	case types.CallContract:
 		st.distributeToContract(st.action.Recipient(), intrinsicGas)
 		return
@@ -295,6 +310,18 @@ func (st *StateTransition) distributeGas(intrinsicGas uint64) {
 	case types.DeleteAccount:
 		fallthrough
 	case types.UpdateAccountAuthor:
+		fallthrough
+	case types.LockedTransfer:
+		fallthrough
+	case types.ClaimLocked:
+		fallthrough
+	case types.SetAccountRecovery:
+		fallthrough
+	case types.ApproveAccountRecovery:
+		fallthrough
+	case types.ExecuteAccountRecovery:
+		fallthrough
+	case types.CancelAccountRecovery:
 		st.distributeToSystemAccount(common.Name(st.chainConfig.AccountName))
 		return
 	case types.IncreaseAsset:
@@ -307,6 +334,18 @@ func (st *StateTransition) distributeGas(intrinsicGas uint64) {
 		fallthrough
 	case types.UpdateAssetContract:
 		fallthrough
+	case types.FreezeAccountAsset:
+		fallthrough
+	case types.UnfreezeAccountAsset:
+		fallthrough
+	case types.PauseAsset:
+		fallthrough
+	case types.UnpauseAsset:
+		fallthrough
+	case types.ApproveAsset:
+		fallthrough
+	case types.TransferFromAsset:
+		fallthrough
 	case types.UpdateAsset:
 		st.distributeToSystemAccount(common.Name(st.chainConfig.AssetName))
 		return
@@ -316,6 +355,16 @@ func (st *StateTransition) distributeGas(intrinsicGas uint64) {
 		fallthrough
 	case types.UpdateCandidatePubKey:
 		fallthrough
+	case types.ReportDoubleSign:
+		fallthrough
+	case types.ClaimReward:
+		fallthrough
+	case types.CreateProposal:
+		fallthrough
+	case types.VoteProposal:
+		fallthrough
+	case types.ExecuteProposal:
+		fallthrough
 	case types.UnregCandidate:
 		fallthrough
 	case types.VoteCandidate:
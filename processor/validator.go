@@ -47,6 +47,12 @@ func NewBlockValidator(blockchain ChainContext, engine consensus.IValidator) *Bl
 	return validator
 }
 
+// WithChain returns a validator of the same engine validating headers against
+// chain, whose states may be retrieved differently.
+func (v *BlockValidator) WithChain(chain ChainContext) Validator {
+	return NewBlockValidator(chain, v.engine)
+}
+
 // ValidateHeader checks whether a header conforms to the consensus rules of the
 // stock engine.
 func (v *BlockValidator) ValidateHeader(header *types.Header, seal bool) error {
@@ -669,6 +669,27 @@ func opExtCodeSize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, sta
 	return nil, nil
 }
 
+func opExtCodeHash(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	slot := stack.peek()
+	userID := slot.Uint64()
+	acct, err := evm.AccountDB.GetAccountById(userID)
+	if err != nil || acct == nil {
+		slot.SetUint64(0)
+		return nil, nil
+	}
+	if acct.IsSuicided() {
+		slot.SetBytes(emptyCodeHash.Bytes())
+		return nil, nil
+	}
+	// an existing account without code has the hash of the empty code
+	codeHash, err := acct.GetCodeHash()
+	if err != nil || codeHash == (common.Hash{}) {
+		codeHash = emptyCodeHash
+	}
+	slot.SetBytes(codeHash.Bytes())
+	return nil, nil
+}
+
 func opCodeSize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
 	l := evm.interpreter.intPool.get().SetInt64(int64(len(contract.Code)))
 	stack.push(l)
@@ -766,6 +787,22 @@ func opCallAssetId(pc *uint64, evm *EVM, contract *Contract, memory *Memory, sta
 	return nil, nil
 }
 
+// opSelfBalance pushes the balance of the contract in the gas asset.
+func opSelfBalance(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	balance, err := evm.AccountDB.GetAccountBalanceByID(contract.Name(), evm.AssetID, 0)
+	if err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+	stack.push(evm.interpreter.intPool.get().Set(balance))
+	return nil, nil
+}
+
+func opChainID(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	stack.push(evm.interpreter.intPool.get().Set(evm.chainConfig.ChainID))
+	return nil, nil
+}
+
 func opPop(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
 	evm.interpreter.intPool.put(stack.pop())
 	return nil, nil
@@ -898,6 +935,50 @@ func opCreate(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *S
 	return nil, nil
 }
 
+func opCreate2(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	var (
+		value        = math.U256(stack.pop())
+		offset, size = stack.pop(), stack.pop()
+		salt         = stack.pop()
+		code         = memory.Get(offset.Int64(), size.Int64())
+		gas          = contract.Gas
+	)
+	gas -= gas / 64
+	contract.UseGas(gas)
+
+	payload := &accountmanager.Create2ContractAction{Salt: common.BigToHash(salt), Code: code}
+	b, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	contractName := accountmanager.Create2ContractName(contract.Name(), payload.Salt, code)
+	action := types.NewAction(types.Create2Contract, contract.Name(), contractName, 0, evm.AssetID, gas, value, b, nil)
+	res, returnGas, suberr := evm.Create2(contract, action, gas)
+	contract.Gas += returnGas
+
+	if evm.vmConfig.ContractLogFlag {
+		errmsg := ""
+		if suberr != nil {
+			errmsg = suberr.Error()
+		}
+		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "create2", GasUsed: gas - returnGas, GasLimit: gas, Depth: uint64(evm.depth), Error: errmsg}
+		evm.addInternalActions(internalAction)
+	}
+
+	acct, err := evm.AccountDB.GetAccountByName(contractName)
+	if suberr != nil || err != nil || acct == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		stack.push(evm.interpreter.intPool.get().SetUint64(acct.GetAccountID()))
+	}
+	evm.interpreter.intPool.put(value, offset, size, salt)
+
+	if suberr == errExecutionReverted {
+		return res, nil
+	}
+	return nil, nil
+}
+
 func opCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
 	// Pop gas. The actual gas in in evm.callGasTemp.
 	evm.interpreter.intPool.put(stack.pop())
@@ -939,7 +1020,7 @@ func opCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Sta
 				errmsg = err.Error()
 			}
 			internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "call", GasUsed: gas - returnGas, GasLimit: gas, Depth: uint64(evm.depth), Error: errmsg}
-			evm.InternalTxs = append(evm.InternalTxs, internalAction)
+			evm.addInternalActions(internalAction)
 		}
 	}
 
@@ -998,7 +1079,7 @@ func opCallWithPay(pc *uint64, evm *EVM, contract *Contract, memory *Memory, sta
 				errmsg = err.Error()
 			}
 			internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "callwithpay", GasUsed: gas - returnGas, GasLimit: gas, Depth: uint64(evm.depth), Error: errmsg}
-			evm.InternalTxs = append(evm.InternalTxs, internalAction)
+			evm.addInternalActions(internalAction)
 		}
 	}
 
@@ -1062,7 +1143,7 @@ func opCallCode(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "callcode", GasUsed: gas - returnGas, GasLimit: gas, Depth: uint64(evm.depth), Error: errmsg}
-		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		evm.addInternalActions(internalAction)
 	}
 	return ret, nil
 }
@@ -1243,12 +1324,12 @@ func execAddAsset(evm *EVM, contract *Contract, assetID uint64, toName common.Na
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "addasset", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
-		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		evm.addInternalActions(internalAction)
 		if len(internalActions) > 0 {
 			for _, iLog := range internalActions {
 				iLog.Depth = uint64(evm.depth)
 			}
-			evm.InternalTxs = append(evm.InternalTxs, internalActions...)
+			evm.addInternalActions(internalActions...)
 		}
 	}
 	return err
@@ -1272,12 +1353,12 @@ func opDestroyAsset(pc *uint64, evm *EVM, contract *Contract, memory *Memory, st
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "destroyasset", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
-		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		evm.addInternalActions(internalAction)
 		if len(internalActions) > 0 {
 			for _, iLog := range internalActions {
 				iLog.Depth = uint64(evm.depth)
 			}
-			evm.InternalTxs = append(evm.InternalTxs, internalActions...)
+			evm.addInternalActions(internalActions...)
 		}
 	}
 
@@ -1480,12 +1561,12 @@ func executeIssuseAsset(evm *EVM, contract *Contract, desc string) (uint64, erro
 					errmsg = err.Error()
 				}
 				internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "issueasset", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
-				evm.InternalTxs = append(evm.InternalTxs, internalAction)
+				evm.addInternalActions(internalAction)
 				if len(internalActions) > 0 {
 					for _, iLog := range internalActions {
 						iLog.Depth = uint64(evm.depth)
 					}
-					evm.InternalTxs = append(evm.InternalTxs, internalActions...)
+					evm.addInternalActions(internalActions...)
 				}
 			}
 			return assetInfo.AssetID, nil
@@ -1536,12 +1617,12 @@ func execSetAssetOwner(evm *EVM, contract *Contract, assetID uint64, owner commo
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "setassetowner", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
-		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		evm.addInternalActions(internalAction)
 		if len(internalActions) > 0 {
 			for _, iLog := range internalActions {
 				iLog.Depth = uint64(evm.depth)
 			}
-			evm.InternalTxs = append(evm.InternalTxs, internalActions...)
+			evm.addInternalActions(internalActions...)
 		}
 	}
 	return err
@@ -1602,13 +1683,108 @@ func execWithdrawFee(evm *EVM, contract *Contract, withdrawTo common.Name, objec
 		for _, assetInfo := range withdrawInfo.AssetInfo {
 			action := types.NewAction(types.Transfer, common.Name(evm.chainConfig.FeeName), withdrawInfo.Founder, 0, assetInfo.AssetID, 0, assetInfo.Amount, paload, nil)
 			internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "transfer", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth)}
-			evm.InternalTxs = append(evm.InternalTxs, internalAction)
+			evm.addInternalActions(internalAction)
 		}
 
 	}
 	return err
 }
 
+//approve an account to transfer asset of the contract
+func opApproveAsset(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	amount, spender, assetId := stack.pop(), stack.pop(), stack.pop()
+	amount = math.U256(amount)
+	acct, err := evm.AccountDB.GetAccountById(spender.Uint64())
+	if err != nil || acct == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+
+	approve := &accountmanager.ApproveAssetAction{Spender: acct.GetName(), AssetID: assetId.Uint64(), Amount: new(big.Int).Set(amount)}
+	err = execAllowanceAction(evm, contract, types.ApproveAsset, approve, "approveasset")
+	if err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		stack.push(evm.interpreter.intPool.get().SetUint64(1))
+	}
+	evm.interpreter.intPool.put(amount, spender, assetId)
+	return nil, nil
+}
+
+//transfer asset of another account out of the allowance of the contract
+func opTransferFromAsset(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	amount, to, from, assetId := stack.pop(), stack.pop(), stack.pop(), stack.pop()
+	amount = math.U256(amount)
+	fromAcct, err := evm.AccountDB.GetAccountById(from.Uint64())
+	if err != nil || fromAcct == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+	toAcct, err := evm.AccountDB.GetAccountById(to.Uint64())
+	if err != nil || toAcct == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+
+	transfer := &accountmanager.TransferFromAction{From: fromAcct.GetName(), To: toAcct.GetName(), AssetID: assetId.Uint64(), Amount: new(big.Int).Set(amount)}
+	err = execAllowanceAction(evm, contract, types.TransferFromAsset, transfer, "transferfromasset")
+	if err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		stack.push(evm.interpreter.intPool.get().SetUint64(1))
+	}
+	evm.interpreter.intPool.put(amount, to, from, assetId)
+	return nil, nil
+}
+
+func execAllowanceAction(evm *EVM, contract *Contract, actionType types.ActionType, payload interface{}, name string) error {
+	b, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return err
+	}
+
+	action := types.NewAction(actionType, contract.Name(), common.Name(evm.chainConfig.AssetName), 0, evm.chainConfig.SysTokenID, 0, big.NewInt(0), b, nil)
+	internalActions, err := evm.AccountDB.Process(&types.AccountManagerContext{
+		Action:      action,
+		Number:      evm.Context.BlockNumber.Uint64(),
+		CurForkID:   evm.Context.ForkID,
+		ChainConfig: evm.chainConfig,
+	})
+	if evm.vmConfig.ContractLogFlag {
+		errmsg := ""
+		if err != nil {
+			errmsg = err.Error()
+		}
+		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: name, GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
+		evm.addInternalActions(internalAction)
+		if len(internalActions) > 0 {
+			for _, iLog := range internalActions {
+				iLog.Depth = uint64(evm.depth)
+			}
+			evm.addInternalActions(internalActions...)
+		}
+	}
+	return err
+}
+
+//get the amount of asset spender may transfer out of owner
+func opAllowance(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	spender, owner, assetId := stack.pop(), stack.pop(), stack.pop()
+	amount := evm.interpreter.intPool.getZero()
+	ownerAcct, err := evm.AccountDB.GetAccountById(owner.Uint64())
+	if err == nil && ownerAcct != nil {
+		spenderAcct, err := evm.AccountDB.GetAccountById(spender.Uint64())
+		if err == nil && spenderAcct != nil {
+			if allowance, err := evm.AccountDB.GetAllowance(ownerAcct.GetName(), spenderAcct.GetName(), assetId.Uint64()); err == nil {
+				amount.Set(allowance)
+			}
+		}
+	}
+	stack.push(amount)
+	evm.interpreter.intPool.put(spender, owner, assetId)
+	return nil, nil
+}
+
 func opCallEx(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
 	evm.interpreter.intPool.put(stack.pop())
 	name, assetId, value, inOffset, inSize, retOffset, retSize := stack.pop(), stack.pop(), stack.pop(), stack.pop(), stack.pop(), stack.pop(), stack.pop()
@@ -1668,7 +1844,7 @@ func opCallEx(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *S
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "transferex", GasUsed: 0, GasLimit: 0, Depth: uint64(evm.depth), Error: errmsg}
-		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		evm.addInternalActions(internalAction)
 	}
 	return nil, nil
 }
@@ -1710,7 +1886,7 @@ func opStaticCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stac
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{ActionType: "staticcall", GasUsed: gas - returnGas, GasLimit: gas, Depth: uint64(evm.depth), Error: errmsg}
-		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		evm.addInternalActions(internalAction)
 	}
 	return ret, nil
 }
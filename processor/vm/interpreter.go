@@ -45,8 +45,13 @@ type Config struct {
 	// JumpTable contains the EVM instruction table. This
 	// may be left uninitialised and will be set to the default
 	// table.
-	JumpTable       [256]operation
-	ContractLogFlag bool
+	JumpTable         [256]operation
+	ContractLogFlag   bool
+	AccountIndexFlag  bool
+	InternalIndexFlag bool
+	// SkipSignCheck applies transactions without verifying their
+	// signatures, only for simulation on a throwaway state.
+	SkipSignCheck bool
 	//
 	EndTime time.Time
 }
@@ -72,6 +77,8 @@ func NewInterpreter(evm *EVM, cfg Config) *Interpreter {
 	// we'll set the default jump table.
 	if !cfg.JumpTable[STOP].valid {
 		switch {
+		case evm.ForkID >= params.ForkID5:
+			cfg.JumpTable = istanbulInstructionSet
 		//case evm.ChainConfig().IsConstantinople(evm.BlockNumber):
 		//	cfg.JumpTable = constantinopleInstructionSet
 		//case evm.ChainConfig().IsByzantium(evm.BlockNumber):
@@ -83,10 +90,15 @@ func NewInterpreter(evm *EVM, cfg Config) *Interpreter {
 		}
 	}
 
+	gasTable := params.GasTableInstance
+	if evm.ForkID >= params.ForkID5 {
+		gasTable = params.GasTableForkID5
+	}
+
 	return &Interpreter{
 		evm:      evm,
 		cfg:      cfg,
-		gasTable: params.GasTableInstance,
+		gasTable: gasTable,
 		intPool:  newIntPool(),
 	}
 }
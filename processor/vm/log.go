@@ -86,6 +86,14 @@ type Tracer interface {
 	CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error
 }
 
+// InternalTracer is implemented by tracers which also follow the calls and
+// native asset operations executed by contracts. CaptureInternal is called once
+// such an operation completed, after the operations it caused in turn. Internal
+// actions are only recorded with the contract log enabled.
+type InternalTracer interface {
+	CaptureInternal(env *EVM, action *types.InternalAction) error
+}
+
 // StructLogger is an EVM state logger and implements Tracer.
 //
 // StructLogger can capture state based on the given Log configuration and also keeps
@@ -100,6 +108,8 @@ type StructLogger struct {
 	err           error
 }
 
+var _ Tracer = (*StructLogger)(nil)
+
 // NewStructLogger returns a new logger
 func NewStructLogger(cfg *LogConfig) *StructLogger {
 	logger := &StructLogger{
@@ -111,7 +121,7 @@ func NewStructLogger(cfg *LogConfig) *StructLogger {
 	return logger
 }
 
-func (l *StructLogger) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+func (l *StructLogger) CaptureStart(from common.Name, to common.Name, call bool, input []byte, gas uint64, value *big.Int) error {
 	return nil
 }
 
@@ -0,0 +1,56 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/unichainplatform/unichain/common"
+)
+
+func TestStoreCapture(t *testing.T) {
+	var (
+		logger   = NewStructLogger(nil)
+		mem      = NewMemory()
+		stack    = newstack()
+		contract = NewContract(AccountRef("fromtest"), AccountRef("tototest"), new(big.Int), 0, 0)
+	)
+	stack.push(big.NewInt(1))
+	stack.push(big.NewInt(0))
+
+	var tracer Tracer = logger
+	tracer.CaptureStart(common.Name("fromtest"), common.Name("tototest"), true, nil, 0, new(big.Int))
+	tracer.CaptureState(nil, 0, SSTORE, 0, 0, mem, stack, contract, 1, nil)
+	tracer.CaptureEnd([]byte{1}, 0, time.Duration(0), errors.New("test error"))
+
+	if len(logger.changedValues[contract.Name()]) == 0 {
+		t.Fatalf("expected exactly 1 changed value on address %x, got %d", contract.Name(), len(logger.changedValues[contract.Name()]))
+	}
+	exp := common.BigToHash(big.NewInt(1))
+	if logger.changedValues[contract.Name()][common.Hash{}] != exp {
+		t.Errorf("expected %x, got %x", exp, logger.changedValues[contract.Name()][common.Hash{}])
+	}
+	if len(logger.StructLogs()) != 1 || logger.StructLogs()[0].Op != SSTORE {
+		t.Errorf("expected 1 SSTORE log, got %v", logger.StructLogs())
+	}
+	if logger.Error() == nil || len(logger.Output()) != 1 {
+		t.Errorf("output or error not captured: %x %v", logger.Output(), logger.Error())
+	}
+}
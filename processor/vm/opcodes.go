@@ -87,6 +87,7 @@ const (
 	EXTCODECOPY
 	RETURNDATASIZE
 	RETURNDATACOPY
+	EXTCODEHASH
 )
 
 const (
@@ -97,7 +98,10 @@ const (
 	NUMBER
 	DIFFICULTY
 	GASLIMIT
+	// CALLASSETID is the 0x46 solc emits for chainid() and block.chainid,
+	// the chain id is read with CHAINID at 0xd9.
 	CALLASSETID
+	SELFBALANCE
 )
 
 const (
@@ -230,6 +234,12 @@ const (
 	RECIPIENT              = 0xd4
 
 	CALLWITHPAY = 0xd5
+
+	APPROVEASSET      = 0xd6
+	TRANSFERFROMASSET = 0xd7
+	ALLOWANCE         = 0xd8
+
+	CHAINID = 0xd9
 )
 
 const (
@@ -239,6 +249,7 @@ const (
 	CALLCODE
 	RETURN
 	DELEGATECALL
+	CREATE2
 	STATICCALL = 0xfa
 
 	REVERT = 0xfd
@@ -298,6 +309,7 @@ var opCodeToString = map[OpCode]string{
 	EXTCODECOPY:    "EXTCODECOPY",
 	RETURNDATASIZE: "RETURNDATASIZE",
 	RETURNDATACOPY: "RETURNDATACOPY",
+	EXTCODEHASH:    "EXTCODEHASH",
 
 	// 0x40 range - block operations
 	BLOCKHASH:   "BLOCKHASH",
@@ -307,6 +319,7 @@ var opCodeToString = map[OpCode]string{
 	DIFFICULTY:  "DIFFICULTY",
 	GASLIMIT:    "GASLIMIT",
 	CALLASSETID: "CALLASSETID",
+	SELFBALANCE: "SELFBALANCE",
 
 	// 0x50 range - 'storage' and execution
 	POP: "POP",
@@ -425,12 +438,18 @@ var opCodeToString = map[OpCode]string{
 	RECIPIENT:       "RECIPIENT",
 	CALLWITHPAY:     "CALLWITHPAY",
 
+	APPROVEASSET:      "APPROVEASSET",
+	TRANSFERFROMASSET: "TRANSFERFROMASSET",
+	ALLOWANCE:         "ALLOWANCE",
+	CHAINID:           "CHAINID",
+
 	// 0xf0 range
 	CREATE:       "CREATE",
 	CALL:         "CALL",
 	RETURN:       "RETURN",
 	CALLCODE:     "CALLCODE",
 	DELEGATECALL: "DELEGATECALL",
+	CREATE2:      "CREATE2",
 
 	//add end
 	STATICCALL:   "STATICCALL",
@@ -488,6 +507,7 @@ var stringToOp = map[string]OpCode{
 	"CALLDATASIZE": CALLDATASIZE,
 	"CALLDATACOPY": CALLDATACOPY,
 	"DELEGATECALL": DELEGATECALL,
+	"CREATE2":      CREATE2,
 
 	"STATICCALL":     STATICCALL,
 	"CODESIZE":       CODESIZE,
@@ -497,6 +517,7 @@ var stringToOp = map[string]OpCode{
 	"EXTCODECOPY":    EXTCODECOPY,
 	"RETURNDATASIZE": RETURNDATASIZE,
 	"RETURNDATACOPY": RETURNDATACOPY,
+	"EXTCODEHASH":    EXTCODEHASH,
 	"BLOCKHASH":      BLOCKHASH,
 	"COINBASE":       COINBASE,
 	"TIMESTAMP":      TIMESTAMP,
@@ -504,6 +525,7 @@ var stringToOp = map[string]OpCode{
 	"DIFFICULTY":     DIFFICULTY,
 	"GASLIMIT":       GASLIMIT,
 	"CALLASSETID":    CALLASSETID,
+	"SELFBALANCE":    SELFBALANCE,
 	"POP":            POP,
 	"MLOAD":          MLOAD,
 	"MSTORE":         MSTORE,
@@ -614,6 +636,11 @@ var stringToOp = map[string]OpCode{
 	"RECIPIENT":       RECIPIENT,
 	"CALLWITHPAY":     CALLWITHPAY,
 
+	"APPROVEASSET":      APPROVEASSET,
+	"TRANSFERFROMASSET": TRANSFERFROMASSET,
+	"ALLOWANCE":         ALLOWANCE,
+	"CHAINID":           CHAINID,
+
 	//"CREATE":   CREATE,
 	"CALL":     CALL,
 	"RETURN":   RETURN,
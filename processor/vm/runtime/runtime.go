@@ -40,6 +40,7 @@ type Config struct {
 	FromPubkey  common.PubKey
 	Coinbase    common.Name
 	BlockNumber *big.Int
+	ForkID      uint64
 	Time        *big.Int
 	GasLimit    uint64
 	AssetID     uint64
@@ -125,6 +126,7 @@ func NewEnv(cfg *Config) *vm.EVM {
 		From:        cfg.Origin,
 		Coinbase:    cfg.Coinbase,
 		BlockNumber: cfg.BlockNumber,
+		ForkID:      cfg.ForkID,
 		Time:        cfg.Time,
 		AssetID:     cfg.AssetID,
 		Difficulty:  cfg.Difficulty,
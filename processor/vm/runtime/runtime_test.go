@@ -18,6 +18,7 @@ package runtime
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -28,7 +29,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/unichainplatform/unichain/accountmanager"
 	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
 	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/processor/vm"
 	"github.com/unichainplatform/unichain/rawdb"
 	"github.com/unichainplatform/unichain/state"
 	"github.com/unichainplatform/unichain/types"
@@ -454,3 +457,199 @@ func TestVEN(t *testing.T) {
 	num := new(big.Int).SetBytes(ret)
 	assert.Equal(t, num, new(big.Int).Mul(big.NewInt(3500000000), big.NewInt(100000000000)))
 }
+
+func TestCreate2(t *testing.T) {
+	state, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	account, _ := accountmanager.NewAccountManager(state)
+	deployer, factory := common.Name("jacobwolf12345"), common.Name("denverfolk12345")
+	for _, name := range []string{deployer.String(), factory.String(), "unichain.asset"} {
+		if err := createAccount(account, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := account.Process(&types.AccountManagerContext{
+		Action:      issueAssetAction(deployer, deployer),
+		ChainConfig: params.DefaultChainconfig,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := account.TransferAsset(deployer, factory, 0, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	runtimeConfig := Config{
+		Origin:   deployer,
+		State:    state,
+		Account:  account,
+		GasLimit: 10000000000,
+	}
+	setDefaults(&runtimeConfig)
+
+	// init code returning the runtime code which returns 42
+	initCode := common.Hex2Bytes("69602a60005260206000f3600052600a6016f3")
+	salt := common.HexToHash("0x07")
+	payload, err := rlp.EncodeToBytes(&accountmanager.Create2ContractAction{Salt: salt, Code: initCode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractName := accountmanager.Create2ContractName(deployer, salt, initCode)
+
+	action := types.NewAction(types.Create2Contract, deployer, common.Name("jacobwolf12345.other"), 0, 0, runtimeConfig.GasLimit, big.NewInt(0), payload, nil)
+	if _, _, err := NewEnv(&runtimeConfig).Create2(vm.AccountRef(deployer), action, runtimeConfig.GasLimit); err == nil {
+		t.Fatal("create2 to mismatched name accepted")
+	}
+	action = types.NewAction(types.Create2Contract, deployer, contractName, 0, 0, runtimeConfig.GasLimit, big.NewInt(0), payload, nil)
+	if _, _, err := NewEnv(&runtimeConfig).Create2(vm.AccountRef(deployer), action, runtimeConfig.GasLimit); err != nil {
+		t.Fatalf("create2 err %v", err)
+	}
+	if code, err := account.GetCode(contractName); err != nil || !bytes.Equal(code, common.Hex2Bytes("602a60005260206000f3")) {
+		t.Fatalf("contract code mismatch %x %v", code, err)
+	}
+	if acct, _ := account.GetAccountByName(contractName); acct == nil || acct.GetFounder() != deployer {
+		t.Fatalf("contract founder mismatch")
+	}
+	if _, _, err := NewEnv(&runtimeConfig).Create2(vm.AccountRef(deployer), action, runtimeConfig.GasLimit); err == nil {
+		t.Fatal("create2 twice accepted")
+	}
+
+	// factory deploying the init code with CREATE2 and returning the account id
+	factoryCode := append(append([]byte{0x72}, initCode...), common.Hex2Bytes("60005260076013600d6000f560005260206000f3")...)
+	if _, err := account.SetCode(factory, factoryCode); err != nil {
+		t.Fatal(err)
+	}
+	runtimeConfig.Origin = factory
+	if _, _, err := Call(types.NewAction(types.CallContract, factory, factory, 0, 0, runtimeConfig.GasLimit, big.NewInt(0), nil, nil), &runtimeConfig); err == nil {
+		t.Fatal("create2 opcode valid before ForkID5")
+	}
+	runtimeConfig.ForkID = params.ForkID5
+	ret, _, err := Call(types.NewAction(types.CallContract, factory, factory, 0, 0, runtimeConfig.GasLimit, big.NewInt(0), nil, nil), &runtimeConfig)
+	if err != nil {
+		t.Fatalf("call factory err %v", err)
+	}
+	created, _ := account.GetAccountByName(accountmanager.Create2ContractName(factory, salt, initCode))
+	if created == nil || new(big.Int).SetBytes(ret).Uint64() != created.GetAccountID() {
+		t.Fatalf("factory created account mismatch %x", ret)
+	}
+}
+
+func TestIstanbulInstructions(t *testing.T) {
+	state, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	account, _ := accountmanager.NewAccountManager(state)
+	owner, contract := common.Name("jacobwolf12345"), common.Name("denverfolk12345")
+	for _, name := range []string{owner.String(), contract.String(), "unichain.asset"} {
+		if err := createAccount(account, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := account.Process(&types.AccountManagerContext{
+		Action:      issueAssetAction(owner, owner),
+		ChainConfig: params.DefaultChainconfig,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := account.TransferAsset(owner, contract, 0, big.NewInt(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	// returns CHAINID, SELFBALANCE and the EXTCODEHASH of itself
+	code := common.Hex2Bytes("d960005247602052303f60405260606000f3")
+	if _, err := account.SetCode(contract, code); err != nil {
+		t.Fatal(err)
+	}
+	runtimeConfig := Config{
+		Origin:   owner,
+		State:    state,
+		Account:  account,
+		GasLimit: 10000000000,
+		ForkID:   params.ForkID4,
+	}
+	setDefaults(&runtimeConfig)
+
+	action := types.NewAction(types.CallContract, owner, contract, 0, 0, runtimeConfig.GasLimit, big.NewInt(0), nil, nil)
+	if _, _, err := Call(action, &runtimeConfig); err == nil {
+		t.Fatal("istanbul instructions accepted before fork")
+	}
+
+	runtimeConfig.ForkID = params.ForkID5
+	ret, _, err := Call(action, &runtimeConfig)
+	if err != nil {
+		t.Fatalf("call err %v", err)
+	}
+	assert.Equal(t, params.DefaultChainconfig.ChainID, new(big.Int).SetBytes(ret[:32]))
+	assert.Equal(t, big.NewInt(3), new(big.Int).SetBytes(ret[32:64]))
+	assert.Equal(t, crypto.Keccak256Hash(code), common.BytesToHash(ret[64:]))
+
+	// returns the EXTCODEHASH of the owner without code
+	acct, _ := account.GetAccountByName(owner)
+	id := make([]byte, 8)
+	binary.BigEndian.PutUint64(id, acct.GetAccountID())
+	code = append(append([]byte{0x67}, id...), common.Hex2Bytes("3f60005260206000f3")...)
+	if _, err := account.SetCode(contract, code); err != nil {
+		t.Fatal(err)
+	}
+	ret, _, err = Call(action, &runtimeConfig)
+	if err != nil {
+		t.Fatalf("call err %v", err)
+	}
+	assert.Equal(t, crypto.Keccak256Hash(nil), common.BytesToHash(ret))
+}
+
+func TestChainIDOpcode(t *testing.T) {
+	state, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	account, _ := accountmanager.NewAccountManager(state)
+	owner, contract := common.Name("jacobwolf12345"), common.Name("denverfolk12345")
+	for _, name := range []string{owner.String(), contract.String(), "unichain.asset"} {
+		if err := createAccount(account, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := account.Process(&types.AccountManagerContext{
+		Action:      issueAssetAction(owner, owner),
+		ChainConfig: params.DefaultChainconfig,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	runtimeConfig := Config{
+		Origin:   owner,
+		State:    state,
+		Account:  account,
+		GasLimit: 10000000000,
+		ForkID:   params.ForkID4,
+	}
+	setDefaults(&runtimeConfig)
+	call := func(code string) (*big.Int, error) {
+		if _, err := account.SetCode(contract, common.Hex2Bytes(code)); err != nil {
+			t.Fatal(err)
+		}
+		action := types.NewAction(types.CallContract, owner, contract, 0, 0, runtimeConfig.GasLimit, big.NewInt(0), nil, nil)
+		ret, _, err := Call(action, &runtimeConfig)
+		return new(big.Int).SetBytes(ret), err
+	}
+
+	// solc output of assembly { mstore(0, chainid()) return(0, 32) }, which
+	// reads the call asset id
+	callAssetID := "4660005260206000f3"
+	// the same returning CHAINID and the BASEFEE of ethereum
+	chainID := "d960005260206000f3"
+	baseFee := "4860005260206000f3"
+
+	if ret, err := call(callAssetID); err != nil || ret.Sign() != 0 {
+		t.Fatalf("0x46 before ForkID5: have %v %v, want the asset id", ret, err)
+	}
+	if _, err := call(chainID); err == nil {
+		t.Fatal("0xd9 valid before ForkID5")
+	}
+
+	runtimeConfig.ForkID = params.ForkID5
+	if ret, err := call(callAssetID); err != nil || ret.Sign() != 0 {
+		t.Fatalf("0x46 at ForkID5: have %v %v, want the asset id", ret, err)
+	}
+	if ret, err := call(chainID); err != nil || ret.Cmp(params.DefaultChainconfig.ChainID) != 0 {
+		t.Fatalf("0xd9 at ForkID5: have %v %v, want %v", ret, err, params.DefaultChainconfig.ChainID)
+	}
+	if _, err := call(baseFee); err == nil {
+		t.Fatal("0x48 valid at ForkID5")
+	}
+	if vm.OpCode(0x46).String() != "CALLASSETID" || vm.OpCode(0xd9).String() != "CHAINID" {
+		t.Fatalf("opcode names: have %v and %v", vm.OpCode(0x46), vm.OpCode(0xd9))
+	}
+}
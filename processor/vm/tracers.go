@@ -0,0 +1,248 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/types"
+)
+
+// NewTracer returns the built in tracer with the given name.
+func NewTracer(name string) (Tracer, error) {
+	switch name {
+	case "callTracer":
+		return NewCallTracer(), nil
+	case "prestateTracer":
+		return NewPrestateTracer(), nil
+	}
+	return nil, fmt.Errorf("tracer %v not found", name)
+}
+
+// CallFrame is a call or native asset operation, together with the
+// operations it caused in turn.
+type CallFrame struct {
+	Type    string        `json:"type"`
+	From    common.Name   `json:"from"`
+	To      common.Name   `json:"to"`
+	AssetID uint64        `json:"assetID"`
+	Value   *big.Int      `json:"value"`
+	Gas     uint64        `json:"gas"`
+	GasUsed uint64        `json:"gasUsed"`
+	Input   hexutil.Bytes `json:"input,omitempty"`
+	Output  hexutil.Bytes `json:"output,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Calls   []*CallFrame  `json:"calls,omitempty"`
+}
+
+func newInternalFrame(action *types.InternalAction) *CallFrame {
+	frame := &CallFrame{
+		Type:    action.ActionType,
+		Gas:     action.GasLimit,
+		GasUsed: action.GasUsed,
+		Error:   action.Error,
+	}
+	if action.Action != nil {
+		frame.From = action.Action.From
+		frame.To = action.Action.To
+		frame.AssetID = action.Action.AssetID
+		frame.Value = action.Action.Amount
+		frame.Input = action.Action.Payload
+	}
+	return frame
+}
+
+// CallTracer is a Tracer which builds a frame tree of every top level call
+// and the calls and native asset operations it executed.
+type CallTracer struct {
+	frames  []*CallFrame
+	root    *CallFrame
+	pending map[uint64][]*CallFrame // completed frames waiting for their parent, by depth
+}
+
+var _ InternalTracer = (*CallTracer)(nil)
+
+// NewCallTracer returns a new call tracer.
+func NewCallTracer() *CallTracer {
+	return &CallTracer{pending: make(map[uint64][]*CallFrame)}
+}
+
+// BuildCallTree nests recorded internal actions by depth.
+func BuildCallTree(internalActions []*types.InternalAction) []*CallFrame {
+	tracer := NewCallTracer()
+	for _, internalAction := range internalActions {
+		tracer.CaptureInternal(nil, internalAction)
+	}
+	return tracer.flush()
+}
+
+func (t *CallTracer) CaptureStart(from common.Name, to common.Name, call bool, input []byte, gas uint64, value *big.Int) error {
+	typ := "call"
+	if !call {
+		typ = "create"
+	}
+	t.root = &CallFrame{
+		Type:  typ,
+		From:  from,
+		To:    to,
+		Value: value,
+		Gas:   gas,
+		Input: common.CopyBytes(input),
+	}
+	return nil
+}
+
+func (t *CallTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	if t.root != nil && depth == 1 {
+		t.root.AssetID = contract.AssetID
+	}
+	return nil
+}
+
+func (t *CallTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureInternal adds a completed internal action. Internal actions are
+// recorded after the operations they caused, so the frames pending one level
+// deeper are its children.
+func (t *CallTracer) CaptureInternal(env *EVM, action *types.InternalAction) error {
+	frame := newInternalFrame(action)
+	frame.Calls = t.pending[action.Depth+1]
+	delete(t.pending, action.Depth+1)
+	t.pending[action.Depth] = append(t.pending[action.Depth], frame)
+	return nil
+}
+
+func (t *CallTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	if t.root == nil {
+		return nil
+	}
+	t.root.Output = common.CopyBytes(output)
+	t.root.GasUsed = gasUsed
+	if err != nil {
+		t.root.Error = err.Error()
+	}
+	t.root.Calls = t.flush()
+	t.frames = append(t.frames, t.root)
+	t.root = nil
+	return nil
+}
+
+// flush returns the pending frames, shallowest first.
+func (t *CallTracer) flush() []*CallFrame {
+	depths := make([]uint64, 0, len(t.pending))
+	for depth := range t.pending {
+		depths = append(depths, depth)
+	}
+	sort.Slice(depths, func(i, j int) bool { return depths[i] < depths[j] })
+
+	frames := make([]*CallFrame, 0)
+	for _, depth := range depths {
+		frames = append(frames, t.pending[depth]...)
+	}
+	t.pending = make(map[uint64][]*CallFrame)
+	return frames
+}
+
+// Frames returns one frame for every top level call traced.
+func (t *CallTracer) Frames() []*CallFrame { return t.frames }
+
+// StateAccess is a state key touched during a trace.
+type StateAccess struct {
+	Value   hexutil.Bytes `json:"value"` // value before it was first touched
+	Written bool          `json:"written"`
+}
+
+// StateChange is a state key changed during a trace.
+type StateChange struct {
+	Before hexutil.Bytes `json:"before"`
+	After  hexutil.Bytes `json:"after"`
+}
+
+// PrestateTracer is a Tracer which records every state key read or written,
+// together with its value before it was first touched.
+type PrestateTracer struct {
+	prestate  map[string]*StateAccess
+	poststate map[string][]byte // last value written, by key
+}
+
+var _ state.AccessTracer = (*PrestateTracer)(nil)
+
+// NewPrestateTracer returns a new prestate tracer.
+func NewPrestateTracer() *PrestateTracer {
+	return &PrestateTracer{
+		prestate:  make(map[string]*StateAccess),
+		poststate: make(map[string][]byte),
+	}
+}
+
+func (t *PrestateTracer) CaptureStart(from common.Name, to common.Name, call bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+func (t *PrestateTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *PrestateTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *PrestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+func (t *PrestateTracer) CaptureGet(key string, value []byte) {
+	if _, ok := t.prestate[key]; !ok {
+		t.prestate[key] = &StateAccess{Value: common.CopyBytes(value)}
+	}
+}
+
+func (t *PrestateTracer) CapturePut(key string, prevalue, value []byte) {
+	access, ok := t.prestate[key]
+	if !ok {
+		access = &StateAccess{Value: common.CopyBytes(prevalue)}
+		t.prestate[key] = access
+	}
+	access.Written = true
+	t.poststate[key] = common.CopyBytes(value)
+}
+
+// Prestate returns the state keys touched, by key.
+func (t *PrestateTracer) Prestate() map[string]*StateAccess { return t.prestate }
+
+// Diff returns the state keys written with a value different from their
+// value before the trace, by key.
+func (t *PrestateTracer) Diff() map[string]*StateChange {
+	diff := make(map[string]*StateChange)
+	for key, value := range t.poststate {
+		before := t.prestate[key].Value
+		if bytes.Equal(before, value) {
+			continue
+		}
+		diff[key] = &StateChange{Before: before, After: value}
+	}
+	return diff
+}
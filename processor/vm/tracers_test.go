@@ -0,0 +1,123 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/types"
+)
+
+func newInternalAction(typ string, from, to string, depth uint64) *types.InternalAction {
+	action := types.NewAction(types.CallContract, common.Name(from), common.Name(to), 0, 1, 0, big.NewInt(10), nil, nil)
+	return &types.InternalAction{Action: action.NewRPCAction(0), ActionType: typ, Depth: depth}
+}
+
+func TestCallTracer(t *testing.T) {
+	tracer := NewCallTracer()
+	tracer.CaptureStart(common.Name("fromtest"), common.Name("contracta"), true, []byte{1}, 1000, big.NewInt(0))
+	// contracta calls contractb, which issues an asset, then transfers
+	tracer.CaptureInternal(nil, newInternalAction("issueasset", "contractb", "assetname", 2))
+	tracer.CaptureInternal(nil, newInternalAction("call", "contracta", "contractb", 1))
+	tracer.CaptureInternal(nil, newInternalAction("transferex", "contracta", "tototest", 1))
+	tracer.CaptureEnd([]byte{2}, 500, time.Duration(0), nil)
+
+	frames := tracer.Frames()
+	if len(frames) != 1 {
+		t.Fatalf("frames mismatch: have %d, want 1", len(frames))
+	}
+	root := frames[0]
+	if root.Type != "call" || root.To != "contracta" || root.GasUsed != 500 || len(root.Calls) != 2 {
+		t.Fatalf("root frame mismatch: %+v", root)
+	}
+	if call := root.Calls[0]; call.Type != "call" || call.To != "contractb" || call.AssetID != 1 || len(call.Calls) != 1 {
+		t.Fatalf("call frame mismatch: %+v", call)
+	}
+	if issue := root.Calls[0].Calls[0]; issue.Type != "issueasset" || issue.From != "contractb" {
+		t.Fatalf("issue frame mismatch: %+v", issue)
+	}
+	if transfer := root.Calls[1]; transfer.Type != "transferex" || len(transfer.Calls) != 0 {
+		t.Fatalf("transfer frame mismatch: %+v", transfer)
+	}
+}
+
+func TestPrestateTracer(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	statedb.Put("fromtest", "balance", []byte{1})
+
+	tracer := NewPrestateTracer()
+	statedb.SetAccessTracer(tracer)
+	statedb.Get("fromtest", "balance")
+	statedb.Put("fromtest", "balance", []byte{2})
+	statedb.Put("tototest", "balance", []byte{3})
+	statedb.SetAccessTracer(nil)
+	statedb.Get("othertest", "balance")
+
+	prestate := tracer.Prestate()
+	if len(prestate) != 2 {
+		t.Fatalf("prestate mismatch: have %d keys, want 2", len(prestate))
+	}
+	for key, access := range prestate {
+		switch {
+		case len(access.Value) == 1 && access.Value[0] == 1:
+		case len(access.Value) == 0:
+		default:
+			t.Fatalf("key %v: unexpected prestate %x", key, access.Value)
+		}
+		if !access.Written {
+			t.Fatalf("key %v: write not recorded", key)
+		}
+	}
+}
+
+func TestPrestateTracerDiff(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	statedb.Put("fromtest", "balance", []byte{1})
+
+	tracer := NewPrestateTracer()
+	statedb.SetAccessTracer(tracer)
+	// restored before the end of the trace, not a change
+	statedb.Put("fromtest", "balance", []byte{2})
+	statedb.Put("fromtest", "balance", []byte{1})
+	statedb.Put("tototest", "balance", []byte{3})
+
+	diff := tracer.Diff()
+	if len(diff) != 1 {
+		t.Fatalf("diff mismatch: have %d keys, want 1", len(diff))
+	}
+	for key, change := range diff {
+		if len(change.Before) != 0 || len(change.After) != 1 || change.After[0] != 3 {
+			t.Fatalf("key %v: unexpected change %x -> %x", key, change.Before, change.After)
+		}
+	}
+}
+
+func TestNewTracer(t *testing.T) {
+	for _, name := range []string{"callTracer", "prestateTracer"} {
+		if _, err := NewTracer(name); err != nil {
+			t.Fatalf("tracer %v: %v", name, err)
+		}
+	}
+	if _, err := NewTracer("jsTracer"); err == nil {
+		t.Fatalf("unknown tracer returned")
+	}
+}
@@ -18,6 +18,7 @@
 package vm
 
 import (
+	"fmt"
 	"math/big"
 	"sync/atomic"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"github.com/unichainplatform/unichain/params"
 	"github.com/unichainplatform/unichain/state"
 	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
 )
 
 var contractAssetTransferable = common.Hex2Bytes("92ff0d31")
@@ -152,6 +154,11 @@ func NewEVM(ctx Context, accountdb *accountmanager.AccountManager, statedb *stat
 	}
 	evm.interpreter = NewInterpreter(evm, vmConfig)
 	evm.FounderGasMap = map[DistributeKey]DistributeGas{}
+	if statedb != nil && vmConfig.Debug {
+		if tracer, ok := vmConfig.Tracer.(state.AccessTracer); ok {
+			statedb.SetAccessTracer(tracer)
+		}
+	}
 	return evm
 }
 
@@ -288,6 +295,12 @@ func (evm *EVM) Call(caller ContractRef, action *types.Action, gas uint64) (ret
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	if evm.vmConfig.Debug && evm.depth == 0 {
+		evm.vmConfig.Tracer.CaptureStart(caller.Name(), action.Recipient(), true, action.Data(), gas, action.Value())
+		defer func(start time.Time) {
+			evm.vmConfig.Tracer.CaptureEnd(ret, gas-leftOverGas, time.Since(start), err)
+		}(time.Now())
+	}
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
@@ -564,8 +577,27 @@ func (evm *EVM) StaticCall(caller ContractRef, name common.Name, input []byte, g
 	return ret, contract.Gas, err
 }
 
+// addInternalActions records internal actions and hands them to the tracer,
+// if it follows internal actions.
+func (evm *EVM) addInternalActions(internalActions ...*types.InternalAction) {
+	evm.InternalTxs = append(evm.InternalTxs, internalActions...)
+	if evm.vmConfig.Debug {
+		if tracer, ok := evm.vmConfig.Tracer.(InternalTracer); ok {
+			for _, internalAction := range internalActions {
+				tracer.CaptureInternal(evm, internalAction)
+			}
+		}
+	}
+}
+
 // Create creates a new contract using code as deployment code.
 func (evm *EVM) Create(caller ContractRef, action *types.Action, gas uint64) (ret []byte, leftOverGas uint64, err error) {
+	if evm.vmConfig.Debug && evm.depth == 0 {
+		evm.vmConfig.Tracer.CaptureStart(caller.Name(), action.Recipient(), false, action.Data(), gas, action.Value())
+		defer func(start time.Time) {
+			evm.vmConfig.Tracer.CaptureEnd(ret, gas-leftOverGas, time.Since(start), err)
+		}(time.Now())
+	}
 
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
@@ -608,11 +640,6 @@ func (evm *EVM) Create(caller ContractRef, action *types.Action, gas uint64) (re
 		return nil, gas, nil
 	}
 
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureStart(caller.Name(), contractName, true, action.Data(), gas, action.Value())
-	}
-	start := time.Now()
-
 	ret, err = run(evm, contract, nil)
 
 	// check whether the max code size has been exceeded
@@ -645,14 +672,43 @@ func (evm *EVM) Create(caller ContractRef, action *types.Action, gas uint64) (re
 	if maxCodeSizeExceeded && err == nil {
 		err = errMaxCodeSizeExceeded
 	}
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
-	}
-
 	evm.distributeContractGas(gas-contract.Gas, contractName, contractName)
 	return ret, contract.Gas, err
 }
 
+// Create2 creates the sub account of caller named from the salt and the code
+// hash of the Create2ContractAction payload, and deploys the code to it.
+func (evm *EVM) Create2(caller ContractRef, action *types.Action, gas uint64) (ret []byte, leftOverGas uint64, err error) {
+	var payload accountmanager.Create2ContractAction
+	if err := rlp.DecodeBytes(action.Data(), &payload); err != nil {
+		return nil, gas, err
+	}
+	contractName := accountmanager.Create2ContractName(caller.Name(), payload.Salt, payload.Code)
+	if contractName != action.Recipient() {
+		return nil, gas, fmt.Errorf("contract name should is %v", contractName)
+	}
+	if evm.depth > int(params.CallCreateDepth) {
+		return nil, gas, ErrDepth
+	}
+	if exist, err := evm.AccountDB.AccountIsExist(contractName); err != nil {
+		return nil, gas, err
+	} else if exist {
+		return nil, gas, ErrContractAddressCollision
+	}
+
+	// the account is only controlled by the code, owned by the deployer
+	snapshot := evm.StateDB.Snapshot()
+	if err := evm.AccountDB.CreateAccount(caller.Name(), contractName, caller.Name(), evm.Context.BlockNumber.Uint64(), evm.ForkID, common.PubKey{}, ""); err != nil {
+		return nil, gas, err
+	}
+	create := types.NewAction(types.CreateContract, caller.Name(), contractName, 0, action.AssetID(), gas, action.Value(), payload.Code, nil)
+	ret, leftOverGas, err = evm.Create(caller, create, gas)
+	if err != nil {
+		evm.StateDB.RevertToSnapshot(snapshot)
+	}
+	return ret, leftOverGas, err
+}
+
 func (evm *EVM) CanTransferContractAsset(caller ContractRef, gas uint64, assetID uint64, assetContract common.Name) (uint64, bool) {
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
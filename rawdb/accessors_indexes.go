@@ -105,3 +105,158 @@ func WriteBloomBits(db DatabaseWriter, bit uint, section uint64, head common.Has
 		log.Crit("Failed to store bloom bits", "err", err)
 	}
 }
+
+// ReadAccountTxIndexHead retrieves the number of the last block covered by the
+// account transaction index, the second return value is false if the index
+// has never been built.
+func ReadAccountTxIndexHead(db DatabaseReader) (uint64, bool) {
+	data, _ := db.Get(accountTxIndexHead)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return decodeBlockNumber(data), true
+}
+
+// WriteAccountTxIndexHead stores the number of the last block covered by the
+// account transaction index.
+func WriteAccountTxIndexHead(db DatabaseWriter, number uint64) {
+	if err := db.Put(accountTxIndexHead, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store account tx index head", "err", err)
+	}
+}
+
+// DeleteAccountTxIndexHead removes the account transaction index head, marking
+// the index as incomplete.
+func DeleteAccountTxIndexHead(db DatabaseDeleter) {
+	if err := db.Delete(accountTxIndexHead); err != nil {
+		log.Crit("Failed to delete account tx index head", "err", err)
+	}
+}
+
+// ReadAccountTxCount retrieves the number of index entries of an account.
+func ReadAccountTxCount(db DatabaseReader, name common.Name) uint64 {
+	data, _ := db.Get(accountTxCountKey(name))
+	if len(data) != 8 {
+		return 0
+	}
+	return decodeBlockNumber(data)
+}
+
+// WriteAccountTxCount stores the number of index entries of an account.
+func WriteAccountTxCount(db DatabaseWriter, name common.Name, count uint64) {
+	if err := db.Put(accountTxCountKey(name), encodeBlockNumber(count)); err != nil {
+		log.Crit("Failed to store account tx count", "err", err)
+	}
+}
+
+// ReadAccountTxIndexEntry retrieves the seq-th index entry of an account.
+func ReadAccountTxIndexEntry(db DatabaseReader, name common.Name, seq uint64) *AccountTxIndexEntry {
+	data, _ := db.Get(accountTxIndexKey(name, seq))
+	if len(data) == 0 {
+		return nil
+	}
+	entry := new(AccountTxIndexEntry)
+	if err := rlp.DecodeBytes(data, entry); err != nil {
+		log.Crit("Invalid account tx index entry RLP", "name", name, "seq", seq, "err", err)
+		return nil
+	}
+	return entry
+}
+
+// WriteAccountTxIndexEntry stores the seq-th index entry of an account.
+func WriteAccountTxIndexEntry(db DatabaseWriter, name common.Name, seq uint64, entry *AccountTxIndexEntry) {
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		log.Crit("Failed to encode account tx index entry", "err", err)
+	}
+	if err := db.Put(accountTxIndexKey(name, seq), data); err != nil {
+		log.Crit("Failed to store account tx index entry", "err", err)
+	}
+}
+
+// DeleteAccountTxIndexEntry removes the seq-th index entry of an account.
+func DeleteAccountTxIndexEntry(db DatabaseDeleter, name common.Name, seq uint64) {
+	if err := db.Delete(accountTxIndexKey(name, seq)); err != nil {
+		log.Crit("Failed to delete account tx index entry", "err", err)
+	}
+}
+
+// ReadInternalActionIndexHead retrieves the number of the last block covered
+// by the internal action index, the second return value is false if the index
+// has never been built.
+func ReadInternalActionIndexHead(db DatabaseReader) (uint64, bool) {
+	data, _ := db.Get(internalActionIndexHead)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return decodeBlockNumber(data), true
+}
+
+// WriteInternalActionIndexHead stores the number of the last block covered by
+// the internal action index.
+func WriteInternalActionIndexHead(db DatabaseWriter, number uint64) {
+	if err := db.Put(internalActionIndexHead, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store internal action index head", "err", err)
+	}
+}
+
+// DeleteInternalActionIndexHead removes the internal action index head,
+// marking the index as incomplete.
+func DeleteInternalActionIndexHead(db DatabaseDeleter) {
+	if err := db.Delete(internalActionIndexHead); err != nil {
+		log.Crit("Failed to delete internal action index head", "err", err)
+	}
+}
+
+// ReadInternalActionCount retrieves the number of index entries of an account
+// and asset.
+func ReadInternalActionCount(db DatabaseReader, name common.Name, assetID uint64) uint64 {
+	data, _ := db.Get(internalActionCountKey(name, assetID))
+	if len(data) != 8 {
+		return 0
+	}
+	return decodeBlockNumber(data)
+}
+
+// WriteInternalActionCount stores the number of index entries of an account
+// and asset.
+func WriteInternalActionCount(db DatabaseWriter, name common.Name, assetID uint64, count uint64) {
+	if err := db.Put(internalActionCountKey(name, assetID), encodeBlockNumber(count)); err != nil {
+		log.Crit("Failed to store internal action count", "err", err)
+	}
+}
+
+// ReadInternalActionIndexEntry retrieves the seq-th index entry of an account
+// and asset.
+func ReadInternalActionIndexEntry(db DatabaseReader, name common.Name, assetID uint64, seq uint64) *InternalActionIndexEntry {
+	data, _ := db.Get(internalActionIndexKey(name, assetID, seq))
+	if len(data) == 0 {
+		return nil
+	}
+	entry := new(InternalActionIndexEntry)
+	if err := rlp.DecodeBytes(data, entry); err != nil {
+		log.Crit("Invalid internal action index entry RLP", "name", name, "assetID", assetID, "seq", seq, "err", err)
+		return nil
+	}
+	return entry
+}
+
+// WriteInternalActionIndexEntry stores the seq-th index entry of an account
+// and asset.
+func WriteInternalActionIndexEntry(db DatabaseWriter, name common.Name, assetID uint64, seq uint64, entry *InternalActionIndexEntry) {
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		log.Crit("Failed to encode internal action index entry", "err", err)
+	}
+	if err := db.Put(internalActionIndexKey(name, assetID, seq), data); err != nil {
+		log.Crit("Failed to store internal action index entry", "err", err)
+	}
+}
+
+// DeleteInternalActionIndexEntry removes the seq-th index entry of an account
+// and asset.
+func DeleteInternalActionIndexEntry(db DatabaseDeleter, name common.Name, assetID uint64, seq uint64) {
+	if err := db.Delete(internalActionIndexKey(name, assetID, seq)); err != nil {
+		log.Crit("Failed to delete internal action index entry", "err", err)
+	}
+}
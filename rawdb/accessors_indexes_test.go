@@ -73,3 +73,76 @@ func TestLookupStorage(t *testing.T) {
 		}
 	}
 }
+
+// Tests that account tx index entries can be stored, retrieved and deleted.
+func TestAccountTxIndexStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+	name := common.Name("fromtest")
+
+	if _, ok := ReadAccountTxIndexHead(db); ok {
+		t.Fatalf("non existent index head returned")
+	}
+	WriteAccountTxIndexHead(db, 314)
+	if head, ok := ReadAccountTxIndexHead(db); !ok || head != 314 {
+		t.Fatalf("index head mismatch: have %v/%v, want 314", head, ok)
+	}
+	DeleteAccountTxIndexHead(db)
+	if _, ok := ReadAccountTxIndexHead(db); ok {
+		t.Fatalf("deleted index head returned")
+	}
+
+	entry := &AccountTxIndexEntry{TxHash: common.HexToHash("0x01"), BlockNumber: 314, TxIndex: 2, ActionIndex: 1}
+	WriteAccountTxIndexEntry(db, name, 7, entry)
+	WriteAccountTxCount(db, name, 8)
+	if count := ReadAccountTxCount(db, name); count != 8 {
+		t.Fatalf("count mismatch: have %v, want 8", count)
+	}
+	if have := ReadAccountTxIndexEntry(db, name, 7); have == nil || *have != *entry {
+		t.Fatalf("entry mismatch: have %v, want %v", have, entry)
+	}
+	if have := ReadAccountTxIndexEntry(db, common.Name("tototest"), 7); have != nil {
+		t.Fatalf("non existent entry returned: %v", have)
+	}
+	DeleteAccountTxIndexEntry(db, name, 7)
+	if have := ReadAccountTxIndexEntry(db, name, 7); have != nil {
+		t.Fatalf("deleted entry returned: %v", have)
+	}
+}
+
+// Tests that internal action index entries can be stored, retrieved and deleted.
+func TestInternalActionIndexStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+	name := common.Name("fromtest")
+
+	if _, ok := ReadInternalActionIndexHead(db); ok {
+		t.Fatalf("non existent index head returned")
+	}
+	WriteInternalActionIndexHead(db, 314)
+	if head, ok := ReadInternalActionIndexHead(db); !ok || head != 314 {
+		t.Fatalf("index head mismatch: have %v/%v, want 314", head, ok)
+	}
+	DeleteInternalActionIndexHead(db)
+	if _, ok := ReadInternalActionIndexHead(db); ok {
+		t.Fatalf("deleted index head returned")
+	}
+
+	entry := &InternalActionIndexEntry{TxHash: common.HexToHash("0x01"), BlockHash: common.HexToHash("0x02"), BlockNumber: 314, TxIndex: 2, ActionIndex: 1, InternalIndex: 3}
+	WriteInternalActionIndexEntry(db, name, 1, 7, entry)
+	WriteInternalActionCount(db, name, 1, 8)
+	if count := ReadInternalActionCount(db, name, 1); count != 8 {
+		t.Fatalf("count mismatch: have %v, want 8", count)
+	}
+	if count := ReadInternalActionCount(db, name, 2); count != 0 {
+		t.Fatalf("count mismatch: have %v, want 0", count)
+	}
+	if have := ReadInternalActionIndexEntry(db, name, 1, 7); have == nil || *have != *entry {
+		t.Fatalf("entry mismatch: have %v, want %v", have, entry)
+	}
+	if have := ReadInternalActionIndexEntry(db, name, 2, 7); have != nil {
+		t.Fatalf("non existent entry returned: %v", have)
+	}
+	DeleteInternalActionIndexEntry(db, name, 1, 7)
+	if have := ReadInternalActionIndexEntry(db, name, 1, 7); have != nil {
+		t.Fatalf("deleted entry returned: %v", have)
+	}
+}
@@ -46,6 +46,14 @@ var (
 	txLookupPrefix  = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 
+	accountTxIndexPrefix = []byte("A") // accountTxIndexPrefix + name + seq (uint64 big endian) -> account transaction index entry
+	accountTxCountPrefix = []byte("c") // accountTxCountPrefix + name -> number of account transaction index entries
+	accountTxIndexHead   = []byte("LastAccountTxIndex")
+
+	internalActionIndexPrefix = []byte("I") // internalActionIndexPrefix + name + assetID (uint64 big endian) + seq (uint64 big endian) -> internal action index entry
+	internalActionCountPrefix = []byte("C") // internalActionCountPrefix + name + assetID (uint64 big endian) -> number of internal action index entries
+	internalActionIndexHead   = []byte("LastInternalActionIndex")
+
 	preimagePrefix = []byte("secure-key-") // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("uni-config-")  // config prefix for the db
 
@@ -67,6 +75,26 @@ type TxLookupEntry struct {
 	Index      uint64
 }
 
+// AccountTxIndexEntry is a positional metadata to help looking up an action
+// sent from or received by an account.
+type AccountTxIndexEntry struct {
+	TxHash      common.Hash
+	BlockNumber uint64
+	TxIndex     uint64
+	ActionIndex uint64
+}
+
+// InternalActionIndexEntry is a positional metadata to help looking up an
+// internal action sent from or received by an account.
+type InternalActionIndexEntry struct {
+	TxHash        common.Hash
+	BlockHash     common.Hash
+	BlockNumber   uint64
+	TxIndex       uint64
+	ActionIndex   uint64
+	InternalIndex uint64
+}
+
 // encodeBlockNumber encodes a block number as big endian uint64
 func encodeBlockNumber(number uint64) []byte {
 	enc := make([]byte, 8)
@@ -127,6 +155,26 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// accountTxIndexKey = accountTxIndexPrefix + name + seq (uint64 big endian)
+func accountTxIndexKey(name common.Name, seq uint64) []byte {
+	return append(append(accountTxIndexPrefix, []byte(name)...), encodeBlockNumber(seq)...)
+}
+
+// accountTxCountKey = accountTxCountPrefix + name
+func accountTxCountKey(name common.Name) []byte {
+	return append(accountTxCountPrefix, []byte(name)...)
+}
+
+// internalActionIndexKey = internalActionIndexPrefix + name + assetID (uint64 big endian) + seq (uint64 big endian)
+func internalActionIndexKey(name common.Name, assetID uint64, seq uint64) []byte {
+	return append(append(append(internalActionIndexPrefix, []byte(name)...), encodeBlockNumber(assetID)...), encodeBlockNumber(seq)...)
+}
+
+// internalActionCountKey = internalActionCountPrefix + name + assetID (uint64 big endian)
+func internalActionCountKey(name common.Name, assetID uint64) []byte {
+	return append(append(internalActionCountPrefix, []byte(name)...), encodeBlockNumber(assetID)...)
+}
+
 // bloomBitsKey = bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash
 func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	key := append(append(bloomBitsPrefix, make([]byte, 10)...), hash.Bytes()...)
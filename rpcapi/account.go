@@ -24,25 +24,28 @@ import (
 	"github.com/unichainplatform/unichain/accountmanager"
 	"github.com/unichainplatform/unichain/asset"
 	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/state"
 )
 
 type RPCAccount struct {
-	AcctName              common.Name                    `json:"accountName"`
-	Founder               common.Name                    `json:"founder"`
-	AccountID             uint64                         `json:"accountID"`
-	Number                uint64                         `json:"number"`
-	Nonce                 uint64                         `json:"nonce"`
-	Code                  hexutil.Bytes                  `json:"code"`
-	CodeHash              common.Hash                    `json:"codeHash"`
-	CodeSize              uint64                         `json:"codeSize"`
-	Threshold             uint64                         `json:"threshold"`
-	UpdateAuthorThreshold uint64                         `json:"updateAuthorThreshold"`
-	AuthorVersion         common.Hash                    `json:"authorVersion"`
-	Balances              []*accountmanager.AssetBalance `json:"balances"`
-	Authors               []*common.Author               `json:"authors"`
-	Suicide               bool                           `json:"suicide"`
-	Destroy               bool                           `json:"destroy"`
-	Description           string                         `json:"description"`
+	AcctName              common.Name                     `json:"accountName"`
+	Founder               common.Name                     `json:"founder"`
+	AccountID             uint64                          `json:"accountID"`
+	Number                uint64                          `json:"number"`
+	Nonce                 uint64                          `json:"nonce"`
+	Code                  hexutil.Bytes                   `json:"code"`
+	CodeHash              common.Hash                     `json:"codeHash"`
+	CodeSize              uint64                          `json:"codeSize"`
+	Threshold             uint64                          `json:"threshold"`
+	UpdateAuthorThreshold uint64                          `json:"updateAuthorThreshold"`
+	AuthorVersion         common.Hash                     `json:"authorVersion"`
+	Balances              []*accountmanager.AssetBalance  `json:"balances"`
+	Authors               []*common.Author                `json:"authors"`
+	Suicide               bool                            `json:"suicide"`
+	Destroy               bool                            `json:"destroy"`
+	Description           string                          `json:"description"`
+	LockedBalances        []*accountmanager.LockedBalance `json:"lockedBalances"`
 }
 
 func NewRPCAccount(account *accountmanager.Account) *RPCAccount {
@@ -63,15 +66,16 @@ func NewRPCAccount(account *accountmanager.Account) *RPCAccount {
 		Suicide:               account.Suicide,
 		Destroy:               account.Destroy,
 		Description:           account.Description,
+		LockedBalances:        account.LockedBalances,
 	}
 	return &acctObject
 }
 
 type AccountAPI struct {
-	b Backend
+	b AccountBackend
 }
 
-func NewAccountAPI(b Backend) *AccountAPI {
+func NewAccountAPI(b AccountBackend) *AccountAPI {
 	return &AccountAPI{b}
 }
 
@@ -187,6 +191,101 @@ func (api *AccountAPI) GetAccountBalanceByID(accountName common.Name, assetID ui
 	return am.GetAccountBalanceByID(accountName, assetID, typeID)
 }
 
+//GetAccountRecovery get the guardians of an account and the recovery they approved
+func (api *AccountAPI) GetAccountRecovery(accountName common.Name) (*accountmanager.AccountRecovery, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAccountRecovery(accountName)
+}
+
+//GetAllowance get the amount of asset spender may still transfer out of owner
+func (api *AccountAPI) GetAllowance(owner common.Name, spender common.Name, assetID uint64) (*big.Int, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAllowance(owner, spender, assetID)
+}
+
+//StorageProof proof of a contract storage slot
+type StorageProof struct {
+	Key   common.Hash     `json:"key"`
+	Value common.Hash     `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+//AccountProof proofs of an account against the state root of a block. The
+//balances are proven by the account object.
+type AccountProof struct {
+	AccountName  common.Name                    `json:"accountName"`
+	AccountID    uint64                         `json:"accountID"`
+	Root         common.Hash                    `json:"root"`
+	IDProof      []hexutil.Bytes                `json:"idProof"`
+	AccountProof []hexutil.Bytes                `json:"accountProof"`
+	Balances     []*accountmanager.AssetBalance `json:"balances"`
+	StorageProof []*StorageProof                `json:"storageProof"`
+}
+
+func toProofBytes(proof [][]byte) []hexutil.Bytes {
+	nodes := make([]hexutil.Bytes, len(proof))
+	for i, node := range proof {
+		nodes[i] = hexutil.Bytes(node)
+	}
+	return nodes
+}
+
+//GetProof get the merkle proofs of the account object, its balances and the
+//contract storage slots keys at block blockNr
+func (api *AccountAPI) GetProof(ctx context.Context, accountName common.Name, keys []common.Hash, blockNr rpc.BlockNumber) (*AccountProof, error) {
+	statedb, header, err := api.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		return nil, err
+	}
+	acctManager := common.Name(api.b.ChainConfig().AccountName)
+
+	result := &AccountProof{AccountName: accountName, Root: header.Root}
+	idProof, err := statedb.GetProof(accountmanager.AccountNameIDKey(acctManager, accountName))
+	if err != nil {
+		return nil, err
+	}
+	result.IDProof = toProofBytes(idProof)
+
+	acct, err := am.GetAccountByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		// the id proof shows the account does not exist
+		return result, nil
+	}
+	result.AccountID = acct.GetAccountID()
+	result.Balances = acct.Balances
+	acctProof, err := statedb.GetProof(accountmanager.AccountInfoKey(acctManager, acct.GetAccountID()))
+	if err != nil {
+		return nil, err
+	}
+	result.AccountProof = toProofBytes(acctProof)
+
+	for _, key := range keys {
+		proof, err := statedb.GetProof(state.StorageKey(accountName.String(), key))
+		if err != nil {
+			return nil, err
+		}
+		result.StorageProof = append(result.StorageProof, &StorageProof{
+			Key:   key,
+			Value: statedb.GetState(accountName.String(), key),
+			Proof: toProofBytes(proof),
+		})
+	}
+	return result, nil
+}
+
 //GetCode
 func (api *AccountAPI) GetCode(accountName common.Name) (hexutil.Bytes, error) {
 	acct, err := api.b.GetAccountManager()
@@ -202,6 +301,11 @@ func (api *AccountAPI) GetCode(accountName common.Name) (hexutil.Bytes, error) {
 
 }
 
+//GetCreate2ContractName returns the account the code is deployed to by deployer with salt
+func (api *AccountAPI) GetCreate2ContractName(deployer common.Name, salt common.Hash, code hexutil.Bytes) common.Name {
+	return accountmanager.Create2ContractName(deployer, salt, code)
+}
+
 //GetNonce
 func (api *AccountAPI) GetNonce(accountName common.Name) (uint64, error) {
 	acct, err := api.b.GetAccountManager()
@@ -211,22 +315,53 @@ func (api *AccountAPI) GetNonce(accountName common.Name) (uint64, error) {
 	return acct.GetNonce(accountName)
 }
 
+//IsAccountFrozen
+func (api *AccountAPI) IsAccountFrozen(accountName common.Name, assetID uint64) (bool, error) {
+	acct, err := api.b.GetAccountManager()
+	if err != nil {
+		return false, err
+	}
+	return acct.IsAccountFrozen(assetID, accountName)
+}
+
+//RPCAsset asset object with the transfer restrictions set by its owner
+type RPCAsset struct {
+	*asset.AssetObject
+	*asset.AssetControl
+}
+
+func newRPCAsset(am *accountmanager.AccountManager, assetObj *asset.AssetObject) (*RPCAsset, error) {
+	control, err := am.GetAssetControl(assetObj.GetAssetID())
+	if err != nil {
+		return nil, err
+	}
+	return &RPCAsset{AssetObject: assetObj, AssetControl: control}, nil
+}
+
 //GetAssetInfoByName
-func (api *AccountAPI) GetAssetInfoByName(ctx context.Context, assetName string) (*asset.AssetObject, error) {
+func (api *AccountAPI) GetAssetInfoByName(ctx context.Context, assetName string) (*RPCAsset, error) {
 	acct, err := api.b.GetAccountManager()
 	if err != nil {
 		return nil, err
 	}
-	return acct.GetAssetInfoByName(assetName)
+	assetObj, err := acct.GetAssetInfoByName(assetName)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCAsset(acct, assetObj)
 }
 
 //GetAssetInfoByID
-func (api *AccountAPI) GetAssetInfoByID(assetID uint64) (*asset.AssetObject, error) {
+func (api *AccountAPI) GetAssetInfoByID(assetID uint64) (*RPCAsset, error) {
 	acct, err := api.b.GetAccountManager()
 	if err != nil {
 		return nil, err
 	}
-	return acct.GetAssetInfoByID(assetID)
+	assetObj, err := acct.GetAssetInfoByID(assetID)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCAsset(acct, assetObj)
 }
 
 //GetAssetAmountByTime
@@ -28,6 +28,7 @@ import (
 	"github.com/unichainplatform/unichain/consensus"
 	"github.com/unichainplatform/unichain/debug"
 	"github.com/unichainplatform/unichain/feemanager"
+	"github.com/unichainplatform/unichain/keystore"
 	"github.com/unichainplatform/unichain/params"
 	"github.com/unichainplatform/unichain/processor/vm"
 	"github.com/unichainplatform/unichain/rpc"
@@ -59,7 +60,11 @@ type Backend interface {
 	GetEVM(ctx context.Context, account *accountmanager.AccountManager, state *state.StateDB, from common.Name, to common.Name, assetID uint64, gasPrice *big.Int, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error)
 	GetDetailTxByFilter(ctx context.Context, filterFn func(common.Name) bool, blockNr, lookbackNum uint64) []*types.DetailTx
 	GetTxsByFilter(ctx context.Context, filterFn func(common.Name) bool, blockNr, lookbackNum uint64) *types.AccountTxs
+	GetTxsByAccountIndex(ctx context.Context, name common.Name, cursor, limit uint64, desc bool) (*types.AccountTxsPage, error)
+	GetInternalActionsByAccountIndex(ctx context.Context, name common.Name, assetID, cursor, limit uint64) (*types.InternalActionsPage, error)
 	GetBadBlocks(ctx context.Context) ([]*types.Block, error)
+	ReplayBlock(ctx context.Context, block *types.Block, end int, vmCfg func(index int) vm.Config) ([]*types.Receipt, error)
+	ApplyTransaction(ctx context.Context, tx *types.Transaction, statedb *state.StateDB, header *types.Header, vmCfg vm.Config) (*types.Receipt, error)
 	ForkStatus(statedb *state.StateDB) (*blockchain.ForkConfig, blockchain.ForkInfo, error)
 	SetStatePruning(enable bool) (bool, uint64)
 
@@ -72,6 +77,9 @@ type Backend interface {
 	//Account API
 	GetAccountManager() (*accountmanager.AccountManager, error)
 
+	// Key manager
+	KeyManager() *keystore.Manager
+
 	//fee manager
 	GetFeeManager() (*feemanager.FeeManager, error)
 	GetFeeManagerByTime(time uint64) (*feemanager.FeeManager, error)
@@ -97,6 +105,22 @@ type Backend interface {
 	GetLogs(ctx context.Context, blockHash common.Hash) ([][]*types.Log, error)
 }
 
+// AccountBackend provides the account API with the chain state, it is
+// implemented by both full and light clients.
+type AccountBackend interface {
+	ChainConfig() *params.ChainConfig
+	StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error)
+	GetAccountManager() (*accountmanager.AccountManager, error)
+}
+
+// PrivateBlockChainBackend provides the private blockchain API with the chain
+// state, it is implemented by both full and light clients.
+type PrivateBlockChainBackend interface {
+	StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error)
+	ForkStatus(statedb *state.StateDB) (*blockchain.ForkConfig, blockchain.ForkInfo, error)
+	SetStatePruning(enable bool) (bool, uint64)
+}
+
 func GetAPIs(apiBackend Backend) []rpc.API {
 	apis := []rpc.API{
 		{
@@ -136,6 +160,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Service:   NewFeeAPI(apiBackend),
 			Public:    true,
 		},
+		{
+			Namespace: "personal",
+			Version:   "1.0",
+			Service:   NewPrivatePersonalAPI(apiBackend),
+		},
 		{
 			Namespace: "p2p",
 			Version:   "1.0",
@@ -146,6 +175,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   debug.Handler,
 		},
+		{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   NewPrivateDebugAPI(apiBackend),
+		},
 	}
 	return append(apis, apiBackend.APIs()...)
 }
@@ -32,9 +32,15 @@ import (
 	"github.com/unichainplatform/unichain/processor/vm"
 	"github.com/unichainplatform/unichain/rawdb"
 	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/state"
 	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
 )
 
+// maxAccountTxsPageSize is the maximum number of entries returned by
+// GetTxsByAccountPaged and GetInternalActionsByAccount.
+const maxAccountTxsPageSize = 1024
+
 // PublicBlockChainAPI provides an API to access the blockchain.
 // It offers only methods that operate on public data that is freely available to anyone.
 type PublicBlockChainAPI struct {
@@ -72,6 +78,11 @@ func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, blockNr rpc.
 	return nil
 }
 
+// GetHeaderByNumber returns the header of the requested block. When blockNr is -1 the chain head is returned.
+func (s *PublicBlockChainAPI) GetHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) *types.Header {
+	return s.b.HeaderByNumber(ctx, blockNr)
+}
+
 // rpcOutputBlock uses the generalized output filler, then adds the total difficulty field, which requires
 // a `PublicBlockchainAPI`.
 func (s *PublicBlockChainAPI) rpcOutputBlock(chainID *big.Int, b *types.Block, inclTx bool, fullTx bool) map[string]interface{} {
@@ -205,6 +216,27 @@ func (s *PublicBlockChainAPI) GetTxsByAccount(ctx context.Context, acctName comm
 	return s.b.GetTxsByFilter(ctx, filterFn, ui64BlockNr, lookforwardNum), nil
 }
 
+// GetTxsByAccountPaged return txs sent from or received by a specific account,
+// read from the account tx index one page at a time.
+// direction is "asc" (default) or "desc", the returned nextCursor continues the
+// walk in the same direction and is null once the walk is done. It fails while
+// the index is not in step with the chain.
+func (s *PublicBlockChainAPI) GetTxsByAccountPaged(ctx context.Context, acctName common.Name, cursor uint64, limit uint64, direction string) (*types.AccountTxsPage, error) {
+	var desc bool
+	switch direction {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return nil, fmt.Errorf("not support direction %v", direction)
+	}
+
+	if limit == 0 || limit > maxAccountTxsPageSize {
+		limit = maxAccountTxsPageSize
+	}
+	return s.b.GetTxsByAccountIndex(ctx, acctName, cursor, limit, desc)
+}
+
 // GetTxsByBloom return all txs, filtered by a bloomByte
 // bloomByte is constructed by some quantities of account names
 // the range is indicate by blockNr and lookbackNum,
@@ -243,6 +275,17 @@ func (s *PublicBlockChainAPI) GetInternalTxByAccount(ctx context.Context, acctNa
 	return s.b.GetDetailTxByFilter(ctx, filterFn, ui64BlockNr, lookbackNum), nil
 }
 
+// GetInternalActionsByAccount return internal actions of an asset, sent from or
+// received by a specific account, read from the internal action index one page
+// at a time. The returned nextCursor continues the walk and is null once the
+// walk is done.
+func (s *PublicBlockChainAPI) GetInternalActionsByAccount(ctx context.Context, acctName common.Name, assetID uint64, cursor uint64, limit uint64) (*types.InternalActionsPage, error) {
+	if limit == 0 || limit > maxAccountTxsPageSize {
+		limit = maxAccountTxsPageSize
+	}
+	return s.b.GetInternalActionsByAccountIndex(ctx, acctName, assetID, cursor, limit)
+}
+
 // GetInternalTxByBloom return all logs of internal txs, filtered by a bloomByte
 // bloomByte is constructed by some quantities of account names
 // the range is indicate by blockNr and lookbackNum,
@@ -305,17 +348,105 @@ type CallArgs struct {
 	Remark     hexutil.Bytes    `json:"remark"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
+// AccountOverride replaces parts of the state of an account during a call.
+// Balances are keyed by asset id, State by storage slot.
+type AccountOverride struct {
+	Nonce    *uint64                     `json:"nonce"`
+	Code     *hexutil.Bytes              `json:"code"`
+	Balances map[uint64]*big.Int         `json:"balances"`
+	State    map[common.Hash]common.Hash `json:"state"`
+}
+
+// StateOverride is the set of account overrides applied before a call.
+type StateOverride map[common.Name]AccountOverride
+
+// Apply overrides the state of the accounts, which must exist.
+func (diff *StateOverride) Apply(account *accountmanager.AccountManager, statedb *state.StateDB) error {
+	if diff == nil {
+		return nil
+	}
+	for name, override := range *diff {
+		acct, err := account.GetAccountByName(name)
+		if err != nil {
+			return err
+		}
+		if acct == nil {
+			return fmt.Errorf("override account %v not exist", name)
+		}
+		if override.Nonce != nil {
+			acct.SetNonce(*override.Nonce)
+		}
+		if override.Code != nil {
+			if err := acct.SetCode(*override.Code); err != nil {
+				return err
+			}
+		}
+		for assetID, balance := range override.Balances {
+			if balance == nil || balance.Sign() < 0 {
+				return fmt.Errorf("override account %v invalid balance of asset %v", name, assetID)
+			}
+			if _, err := acct.AddBalanceByID(assetID, big.NewInt(0)); err != nil {
+				return err
+			}
+			if err := acct.SetBalance(assetID, balance); err != nil {
+				return err
+			}
+		}
+		if err := account.SetAccount(acct); err != nil {
+			return err
+		}
+		for key, value := range override.State {
+			statedb.SetState(name.String(), key, value)
+		}
+	}
+	return nil
+}
+
+// BlockOverrides replaces fields of the block context of a call.
+type BlockOverrides struct {
+	Number   *big.Int     `json:"number"`
+	Time     *big.Int     `json:"timestamp"`
+	Coinbase *common.Name `json:"coinbase"`
+}
+
+// Apply overrides the fields of the header.
+func (diff *BlockOverrides) Apply(header *types.Header) {
+	if diff == nil {
+		return
+	}
+	if diff.Number != nil {
+		header.Number = new(big.Int).Set(diff.Number)
+	}
+	if diff.Time != nil {
+		header.Time = new(big.Int).Set(diff.Time)
+	}
+	if diff.Coinbase != nil {
+		header.Coinbase = *diff.Coinbase
+	}
+}
+
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, blockOverrides *BlockOverrides, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
 		return nil, 0, false, err
 	}
+	if overrides != nil {
+		// never touch a state shared with the caller
+		state = state.Copy()
+	}
 	account, err := accountmanager.NewAccountManager(state)
 	if err != nil {
 		return nil, 0, false, err
 	}
+	if err := overrides.Apply(account, state); err != nil {
+		return nil, 0, false, err
+	}
+	if blockOverrides != nil {
+		header = types.CopyHeader(header)
+		blockOverrides.Apply(header)
+	}
 
 	gasPrice := args.GasPrice
 	value := args.Value
@@ -357,14 +488,99 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{}, 5*time.Second)
-	return (hexutil.Bytes)(result), err
+// The optional overrides replace account state and block context fields first.
+// With a trace config the call is traced, by the struct logger unless config
+// names a built in tracer, and the trace is returned with the return value.
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, blockOverrides *BlockOverrides, config *TraceConfig) (interface{}, error) {
+	if config == nil {
+		result, _, _, err := s.doCall(ctx, args, blockNr, overrides, blockOverrides, vm.Config{}, 5*time.Second)
+		return (hexutil.Bytes)(result), err
+	}
+
+	tracer, err := newTracer(config)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := traceTimeout(config)
+	if err != nil {
+		return nil, err
+	}
+	res, gas, failed, err := s.doCall(ctx, args, blockNr, overrides, blockOverrides, vm.Config{Debug: true, Tracer: tracer, ContractLogFlag: true}, timeout)
+	if err != nil {
+		return nil, err
+	}
+	result := &CallTraceResult{
+		ReturnValue: res,
+		GasUsed:     gas,
+		Failed:      failed,
+		Result:      tracerResult(tracer),
+	}
+	if failed {
+		result.RevertReason = unpackRevertReason(res)
+	}
+	if logger, ok := tracer.(*actionLogger); ok {
+		result.StructLogs = formatLogs(logger.StructLogs())
+	}
+	return result, nil
+}
+
+// SimulationResult is the outcome of a simulated transaction.
+type SimulationResult struct {
+	Receipt   *types.RPCReceiptWithPayer `json:"receipt"`
+	DetailTx  *types.DetailTx            `json:"detailTx"`
+	StateDiff map[string]*vm.StateChange `json:"stateDiff"`
+}
+
+// SimulateTransaction executes a signed or unsigned transaction with all its
+// actions on a copy of the state for the given block number, without checking
+// its signatures. It returns the receipt, with the result and gas distribution
+// of every action and the logs, the internal actions and every state key
+// changed. The optional overrides replace account state first.
+func (s *PublicBlockChainAPI) SimulateTransaction(ctx context.Context, encodedTx hexutil.Bytes, blockNr rpc.BlockNumber, overrides *StateOverride) (*SimulationResult, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return nil, err
+	}
+	if len(tx.GetActions()) == 0 {
+		return nil, fmt.Errorf("transaction has no action")
+	}
+	statedb, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	// never touch a state shared with the caller
+	statedb = statedb.Copy()
+	account, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(account, statedb); err != nil {
+		return nil, err
+	}
+
+	tracer := vm.NewPrestateTracer()
+	statedb.SetAccessTracer(tracer)
+	receipt, err := s.b.ApplyTransaction(ctx, tx, statedb, header, vm.Config{
+		Debug:           true,
+		Tracer:          tracer,
+		ContractLogFlag: true,
+		SkipSignCheck:   true,
+		EndTime:         time.Now().Add(5 * time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SimulationResult{
+		Receipt:   receipt.NewRPCReceiptWithPayer(header.Hash(), header.Number.Uint64(), 0, tx),
+		DetailTx:  receipt.GetInternalTxsLog(),
+		StateDiff: tracer.Diff(),
+	}, nil
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
-func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (uint64, error) {
+// given transaction against the current pending block, after applying the
+// optional overrides.
+func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs, overrides *StateOverride, blockOverrides *BlockOverrides) (uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
 		lo  uint64 = params.GasTableInstance.ActionGas - 1
@@ -383,7 +599,7 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (u
 	// Create a helper to check if a gas allowance results in an executable transaction
 	executable := func(gas uint64) bool {
 		args.Gas = gas
-		_, _, failed, err := s.doCall(ctx, args, rpc.LatestBlockNumber, vm.Config{}, 0)
+		_, _, failed, err := s.doCall(ctx, args, rpc.LatestBlockNumber, overrides, blockOverrides, vm.Config{}, 0)
 		if err != nil || failed {
 			return false
 		}
@@ -407,20 +623,29 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (u
 	return hi, nil
 }
 
-// GetChainConfig returns chain config.
-func (s *PublicBlockChainAPI) GetChainConfig(ctx context.Context) *params.ChainConfig {
+// GetChainConfig returns chain config with the parameters in effect at block number, the latest if not set.
+func (s *PublicBlockChainAPI) GetChainConfig(ctx context.Context, blockNr *rpc.BlockNumber) (*params.ChainConfig, error) {
 	g := s.b.BlockByNumber(ctx, 0)
-	return rawdb.ReadChainConfig(s.b.ChainDb(), g.Hash())
+	cfg := rawdb.ReadChainConfig(s.b.ChainDb(), g.Hash())
+	number := rpc.LatestBlockNumber
+	if blockNr != nil {
+		number = *blockNr
+	}
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, number)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	return s.b.Engine().ChainConfigAt(cfg, state, header.Time.Uint64())
 }
 
 // PrivateBlockChainAPI provides an API to access the blockchain.
 // It offers only methods that operate on private data that is freely available to anyone.
 type PrivateBlockChainAPI struct {
-	b Backend
+	b PrivateBlockChainBackend
 }
 
 // NewPrivateBlockChainAPI creates a new blockchain API.
-func NewPrivateBlockChainAPI(b Backend) *PrivateBlockChainAPI {
+func NewPrivateBlockChainAPI(b PrivateBlockChainBackend) *PrivateBlockChainAPI {
 	return &PrivateBlockChainAPI{b}
 }
 
@@ -0,0 +1,279 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/unichainplatform/unichain/accountmanager"
+	"github.com/unichainplatform/unichain/blockchain"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/processor"
+	"github.com/unichainplatform/unichain/processor/vm"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/txpool"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+var (
+	testCaller   = common.Name("a123456789call")
+	testContract = common.Name("a123456789cntr")
+)
+
+// testBackend serves the blockchain api from a genesis chain, every state
+// request returns the same state so tests can check it is left untouched.
+type testBackend struct {
+	Backend
+	chain  *blockchain.BlockChain
+	engine *dpos.Dpos
+	state  *state.StateDB
+	header *types.Header
+}
+
+func newTestBackend(t *testing.T) *testBackend {
+	db := rawdb.NewMemoryDatabase()
+	chainCfg, dposCfg, _, err := blockchain.SetupGenesisBlock(db, blockchain.DefaultGenesis(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := blockchain.NewBlockChain(db, false, vm.Config{}, chainCfg, nil, 0, txpool.SenderCacher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := dpos.New(dposCfg, chain)
+	bc := struct {
+		*blockchain.BlockChain
+		consensus.IEngine
+	}{chain, engine}
+	chain.SetProcessor(processor.NewStateProcessor(&bc, engine))
+
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []common.Name{testCaller, testContract} {
+		if err := account.CreateAccount(common.Name(chainCfg.SysName), name, "", 0, 0, common.HexToPubKey(""), ""); err != nil {
+			t.Fatalf("create account %v: %v", name, err)
+		}
+	}
+	return &testBackend{chain: chain, engine: engine, state: statedb, header: chain.CurrentBlock().Header()}
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
+
+func (b *testBackend) Engine() consensus.IEngine { return b.engine }
+
+func (b *testBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) *types.Block {
+	return b.chain.CurrentBlock()
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	return b.state, b.header, nil
+}
+
+func (b *testBackend) GetEVM(ctx context.Context, account *accountmanager.AccountManager, state *state.StateDB, from common.Name, to common.Name, assetID uint64, gasPrice *big.Int, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+	account.AddAccountBalanceByID(from, assetID, math.MaxBig256)
+	evmContext := &processor.EvmContext{
+		ChainContext:  b.chain,
+		EngineContext: b.engine,
+	}
+	context := processor.NewEVMContext(from, to, assetID, gasPrice, header, evmContext, nil)
+	return vm.NewEVM(context, account, state, b.ChainConfig(), vmCfg), func() error { return nil }, nil
+}
+
+func (b *testBackend) ApplyTransaction(ctx context.Context, tx *types.Transaction, statedb *state.StateDB, header *types.Header, vmCfg vm.Config) (*types.Receipt, error) {
+	gp := new(common.GasPool).AddGas(header.GasLimit)
+	statedb.Prepare(tx.Hash(), header.Hash(), 0)
+	receipt, _, err := b.chain.Processor().ApplyTransaction(nil, gp, statedb, header, tx, new(uint64), vmCfg)
+	return receipt, err
+}
+
+func TestStateOverrideApply(t *testing.T) {
+	b := newTestBackend(t)
+	statedb := b.state.Copy()
+	account, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, code := uint64(7), []byte{0x60, 0x01}
+	codeBytes := hexutil.Bytes(code)
+	slot, value := common.BytesToHash([]byte{1}), common.BytesToHash([]byte{2})
+	overrides := &StateOverride{testContract: AccountOverride{
+		Nonce:    &nonce,
+		Code:     &codeBytes,
+		Balances: map[uint64]*big.Int{0: big.NewInt(100)},
+		State:    map[common.Hash]common.Hash{slot: value},
+	}}
+	if err := overrides.Apply(account, statedb); err != nil {
+		t.Fatal(err)
+	}
+	if have, _ := account.GetNonce(testContract); have != nonce {
+		t.Fatalf("nonce: have %v, want %v", have, nonce)
+	}
+	if have, _ := account.GetCode(testContract); !bytes.Equal(have, code) {
+		t.Fatalf("code: have %x, want %x", have, code)
+	}
+	if have, _ := account.GetAccountBalanceByID(testContract, 0, 0); have.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("balance: have %v, want 100", have)
+	}
+	if have := statedb.GetState(testContract.String(), slot); have != value {
+		t.Fatalf("storage: have %x, want %x", have, value)
+	}
+
+	missing := &StateOverride{common.Name("a123456789miss"): AccountOverride{Nonce: &nonce}}
+	if err := missing.Apply(account, statedb); err == nil {
+		t.Fatal("override of a missing account accepted")
+	}
+	negative := &StateOverride{testContract: AccountOverride{Balances: map[uint64]*big.Int{0: big.NewInt(-1)}}}
+	if err := negative.Apply(account, statedb); err == nil {
+		t.Fatal("negative balance override accepted")
+	}
+}
+
+func TestCallOverrides(t *testing.T) {
+	b := newTestBackend(t)
+	api := NewPublicBlockChainAPI(b)
+	root := b.state.IntermediateRoot()
+	header := types.CopyHeader(b.header)
+
+	call := func(code string, blockOverrides *BlockOverrides, state map[common.Hash]common.Hash) *big.Int {
+		codeBytes := hexutil.Bytes(common.Hex2Bytes(code))
+		overrides := &StateOverride{testContract: AccountOverride{Code: &codeBytes, State: state}}
+		args := CallArgs{
+			ActionType: types.CallContract,
+			From:       testCaller,
+			To:         testContract,
+			Gas:        1000000,
+			GasPrice:   big.NewInt(1),
+			Value:      big.NewInt(0),
+		}
+		ret, err := api.Call(context.Background(), args, rpc.LatestBlockNumber, overrides, blockOverrides, nil)
+		if err != nil {
+			t.Fatalf("call %v: %v", code, err)
+		}
+		return new(big.Int).SetBytes(ret.(hexutil.Bytes))
+	}
+
+	// returns SLOAD(0)
+	slot := common.Hash{}
+	if have := call("60005460005260206000f3", nil, map[common.Hash]common.Hash{slot: common.BigToHash(big.NewInt(42))}); have.Int64() != 42 {
+		t.Fatalf("storage override: have %v, want 42", have)
+	}
+	// returns NUMBER, TIMESTAMP and the account id of COINBASE
+	number, timestamp := big.NewInt(1000), big.NewInt(2000)
+	if have := call("4360005260206000f3", &BlockOverrides{Number: number}, nil); have.Cmp(number) != 0 {
+		t.Fatalf("number override: have %v, want %v", have, number)
+	}
+	if have := call("4260005260206000f3", &BlockOverrides{Time: timestamp}, nil); have.Cmp(timestamp) != 0 {
+		t.Fatalf("timestamp override: have %v, want %v", have, timestamp)
+	}
+	coinbase := testCaller
+	account, _ := accountmanager.NewAccountManager(b.state)
+	caller, _ := account.GetAccountByName(testCaller)
+	if have := call("4160005260206000f3", &BlockOverrides{Coinbase: &coinbase}, nil); have.Uint64() != caller.GetAccountID() {
+		t.Fatalf("coinbase override: have %v, want %v", have, caller.GetAccountID())
+	}
+
+	if have := b.state.IntermediateRoot(); have != root {
+		t.Fatalf("state root changed by overrides: have %x, want %x", have, root)
+	}
+	if code, _ := account.GetCode(testContract); len(code) != 0 {
+		t.Fatalf("code override leaked: %x", code)
+	}
+	if b.header.Number.Cmp(header.Number) != 0 || b.header.Time.Cmp(header.Time) != 0 || b.header.Coinbase != header.Coinbase {
+		t.Fatalf("header changed by overrides: have %v, want %v", b.header, header)
+	}
+}
+
+func TestSimulateTransaction(t *testing.T) {
+	b := newTestBackend(t)
+	api := NewPublicBlockChainAPI(b)
+	root := b.state.IntermediateRoot()
+
+	// stores 1 at slot 0 and emits an empty LOG0
+	code := hexutil.Bytes(common.Hex2Bytes("600160005560006000a000"))
+	overrides := &StateOverride{
+		testCaller:   AccountOverride{Balances: map[uint64]*big.Int{0: big.NewInt(1e18)}},
+		testContract: AccountOverride{Code: &code},
+	}
+	gasPrice := big.NewInt(1)
+	tx := types.NewTransaction(0, gasPrice,
+		types.NewAction(types.Transfer, testCaller, testContract, 0, 0, 1000000, big.NewInt(10), nil, nil),
+		types.NewAction(types.CallContract, testCaller, testContract, 1, 0, 1000000, big.NewInt(0), nil, nil),
+	)
+	encoded, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := api.SimulateTransaction(context.Background(), encoded, rpc.LatestBlockNumber, nil); err == nil {
+		t.Fatal("simulation without balance succeeded")
+	}
+	result, err := api.SimulateTransaction(context.Background(), encoded, rpc.LatestBlockNumber, overrides)
+	if err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+
+	if result.Receipt.Hash != tx.Hash() {
+		t.Fatalf("receipt hash: have %x, want %x", result.Receipt.Hash, tx.Hash())
+	}
+	if len(result.Receipt.ActionResults) != 2 {
+		t.Fatalf("action results: have %v, want 2", len(result.Receipt.ActionResults))
+	}
+	for i, res := range result.Receipt.ActionResults {
+		if res.Status != types.ReceiptStatusSuccessful || res.GasUsed == 0 {
+			t.Fatalf("action %v: status %v gas %v error %v", i, res.Status, res.GasUsed, res.Error)
+		}
+	}
+	if len(result.Receipt.Logs) != 1 || result.Receipt.Logs[0].Name != testContract {
+		t.Fatalf("logs: have %v, want one log of %v", result.Receipt.Logs, testContract)
+	}
+	if result.DetailTx == nil || len(result.DetailTx.Actions) != 2 {
+		t.Fatalf("detail tx: have %v, want the internal actions of 2 actions", result.DetailTx)
+	}
+
+	slot := common.Hash{}.String()
+	var stored bool
+	for key, change := range result.StateDiff {
+		if strings.Contains(key, testContract.String()) && strings.HasSuffix(key, slot) {
+			stored = bytes.Equal(change.After, common.BigToHash(big.NewInt(1)).Bytes())
+		}
+	}
+	if !stored {
+		t.Fatalf("state diff misses the stored slot: %v", result.StateDiff)
+	}
+
+	if have := b.state.IntermediateRoot(); have != root {
+		t.Fatalf("state root changed by simulation: have %x, want %x", have, root)
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/processor/vm"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/abi"
+)
+
+// defaultTraceTimeout is the amount of time a transaction or block replay may
+// take before it is aborted, the same as a call.
+const defaultTraceTimeout = 5 * time.Second
+
+// revertSelector is the selector of Error(string), used by solidity to return
+// the reason of a revert.
+var revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+// TraceConfig holds extra parameters to trace functions. Tracer names a
+// built in tracer to run instead of the struct logger.
+type TraceConfig struct {
+	*vm.LogConfig
+	Tracer  *string `json:"tracer"`
+	Timeout *string `json:"timeout"`
+}
+
+// StructLogRes stores a structured log emitted by the EVM while replaying a
+// transaction in debug mode.
+type StructLogRes struct {
+	Pc      uint64             `json:"pc"`
+	Op      string             `json:"op"`
+	Gas     uint64             `json:"gas"`
+	GasCost uint64             `json:"gasCost"`
+	Depth   int                `json:"depth"`
+	Error   string             `json:"error,omitempty"`
+	Stack   *[]string          `json:"stack,omitempty"`
+	Memory  *[]string          `json:"memory,omitempty"`
+	Storage *map[string]string `json:"storage,omitempty"`
+}
+
+// ActionTrace is the result of replaying a single action of a transaction.
+type ActionTrace struct {
+	*types.RPCActionResult
+	From         common.Name     `json:"from"`
+	To           common.Name     `json:"to"`
+	ReturnValue  hexutil.Bytes   `json:"returnValue"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	Calls        []*vm.CallFrame `json:"calls"`
+	StructLogs   []StructLogRes  `json:"structLogs"`
+}
+
+// TxTraceResult is the result of replaying a transaction. Result holds the
+// output of a named tracer.
+type TxTraceResult struct {
+	TxHash  common.Hash    `json:"txHash"`
+	GasUsed uint64         `json:"gasUsed"`
+	Failed  bool           `json:"failed"`
+	Actions []*ActionTrace `json:"actions"`
+	Result  interface{}    `json:"result,omitempty"`
+}
+
+// CallTraceResult is the result of tracing a call.
+type CallTraceResult struct {
+	ReturnValue  hexutil.Bytes  `json:"returnValue"`
+	GasUsed      uint64         `json:"gasUsed"`
+	Failed       bool           `json:"failed"`
+	RevertReason string         `json:"revertReason,omitempty"`
+	StructLogs   []StructLogRes `json:"structLogs,omitempty"`
+	Result       interface{}    `json:"result,omitempty"`
+}
+
+// actionLogger is a StructLogger which also records where the struct logs,
+// return value and error of every top level call of a transaction start.
+type actionLogger struct {
+	*vm.StructLogger
+	frames []*actionFrame
+}
+
+type actionFrame struct {
+	start, end int
+	output     []byte
+	err        error
+}
+
+func (l *actionLogger) CaptureStart(from common.Name, to common.Name, call bool, input []byte, gas uint64, value *big.Int) error {
+	l.frames = append(l.frames, &actionFrame{start: len(l.StructLogs())})
+	return l.StructLogger.CaptureStart(from, to, call, input, gas, value)
+}
+
+func (l *actionLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	frame := l.frames[len(l.frames)-1]
+	frame.end = len(l.StructLogs())
+	frame.output = common.CopyBytes(output)
+	frame.err = err
+	return l.StructLogger.CaptureEnd(output, gasUsed, t, err)
+}
+
+// newTracer returns the tracer named by config, or a struct logger.
+func newTracer(config *TraceConfig) (vm.Tracer, error) {
+	if config != nil && config.Tracer != nil && *config.Tracer != "" {
+		return vm.NewTracer(*config.Tracer)
+	}
+	var logConfig *vm.LogConfig
+	if config != nil {
+		logConfig = config.LogConfig
+	}
+	return &actionLogger{StructLogger: vm.NewStructLogger(logConfig)}, nil
+}
+
+// tracerResult returns the output of a named tracer.
+func tracerResult(tracer vm.Tracer) interface{} {
+	switch tracer := tracer.(type) {
+	case *vm.CallTracer:
+		return tracer.Frames()
+	case *vm.PrestateTracer:
+		return tracer.Prestate()
+	}
+	return nil
+}
+
+// traceTimeout returns the replay timeout of config.
+func traceTimeout(config *TraceConfig) (time.Duration, error) {
+	if config == nil || config.Timeout == nil {
+		return defaultTraceTimeout, nil
+	}
+	return time.ParseDuration(*config.Timeout)
+}
+
+// PrivateDebugAPI replays transactions of the chain with the EVM in debug mode.
+type PrivateDebugAPI struct {
+	b Backend
+}
+
+// NewPrivateDebugAPI creates a new debug API.
+func NewPrivateDebugAPI(b Backend) *PrivateDebugAPI {
+	return &PrivateDebugAPI{b}
+}
+
+// TraceTransaction replays a transaction at the state of the parent of its
+// block and returns the struct logs, call tree, gas used and revert reason of
+// each of its actions.
+func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Hash, config *TraceConfig) (*TxTraceResult, error) {
+	tx, blockHash, _, index := rawdb.ReadTransaction(api.b.ChainDb(), hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %x not found", hash)
+	}
+	block, err := api.b.GetBlock(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %x not found", blockHash)
+	}
+	results, err := api.traceBlock(ctx, block, int(index), config)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("transaction %x not found in block %x", hash, blockHash)
+	}
+	return results[0], nil
+}
+
+// TraceBlock replays all transactions of the block with the given number.
+func (api *PrivateDebugAPI) TraceBlock(ctx context.Context, blockNr rpc.BlockNumber, config *TraceConfig) ([]*TxTraceResult, error) {
+	block := api.b.BlockByNumber(ctx, blockNr)
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNr)
+	}
+	return api.traceBlock(ctx, block, -1, config)
+}
+
+// TraceBlockByHash replays all transactions of the block with the given hash.
+func (api *PrivateDebugAPI) TraceBlockByHash(ctx context.Context, hash common.Hash, config *TraceConfig) ([]*TxTraceResult, error) {
+	block, err := api.b.GetBlock(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %x not found", hash)
+	}
+	return api.traceBlock(ctx, block, -1, config)
+}
+
+// traceBlock replays the transactions of a block and traces the one at index,
+// or all of them if index is negative.
+func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block, index int, config *TraceConfig) ([]*TxTraceResult, error) {
+	timeout, err := traceTimeout(config)
+	if err != nil {
+		return nil, err
+	}
+	// make sure the tracer exists before replaying anything
+	if _, err := newTracer(config); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	end := index
+	if index < 0 {
+		end = len(block.Transactions()) - 1
+	}
+	tracers := make(map[int]vm.Tracer)
+	receipts, err := api.b.ReplayBlock(ctx, block, end, func(i int) vm.Config {
+		cfg := vm.Config{ContractLogFlag: true, EndTime: deadline}
+		if index < 0 || i == index {
+			tracers[i], _ = newTracer(config)
+			cfg.Debug = true
+			cfg.Tracer = tracers[i]
+		}
+		return cfg
+	})
+	if err == vm.ErrExecOverTime || err == context.DeadlineExceeded {
+		return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*TxTraceResult, 0, len(tracers))
+	for i, tx := range block.Transactions() {
+		if tracer, ok := tracers[i]; ok {
+			results = append(results, newTxTraceResult(tx, receipts[i], tracer))
+		}
+	}
+	return results, nil
+}
+
+func newTxTraceResult(tx *types.Transaction, receipt *types.Receipt, tracer vm.Tracer) *TxTraceResult {
+	result := &TxTraceResult{
+		TxHash:  tx.Hash(),
+		GasUsed: receipt.TotalGasUsed,
+		Actions: make([]*ActionTrace, 0, len(receipt.ActionResults)),
+		Result:  tracerResult(tracer),
+	}
+	detailTx := receipt.GetInternalTxsLog()
+	logger, _ := tracer.(*actionLogger)
+	var frames []*actionFrame
+	if logger != nil {
+		frames = logger.frames
+	}
+	for i, action := range tx.GetActions() {
+		if i >= len(receipt.ActionResults) {
+			break
+		}
+		actionResult := receipt.ActionResults[i]
+		trace := &ActionTrace{
+			RPCActionResult: actionResult.NewRPCActionResult(action.Type()),
+			From:            action.Sender(),
+			To:              action.Recipient(),
+			Calls:           make([]*vm.CallFrame, 0),
+			StructLogs:      make([]StructLogRes, 0),
+		}
+		if actionResult.Status == types.ReceiptStatusFailed {
+			result.Failed = true
+		}
+		if detailTx != nil && i < len(detailTx.Actions) {
+			trace.Calls = vm.BuildCallTree(detailTx.Actions[i].InternalActions)
+		}
+		// only contract actions enter the evm at the top level
+		if logger != nil && (action.Type() == types.CreateContract || action.Type() == types.Create2Contract || action.Type() == types.CallContract) {
+			if len(frames) > 0 {
+				frame := frames[0]
+				frames = frames[1:]
+				trace.ReturnValue = frame.output
+				trace.StructLogs = formatLogs(logger.StructLogs()[frame.start:frame.end])
+				if frame.err != nil {
+					trace.RevertReason = unpackRevertReason(frame.output)
+				}
+			}
+		}
+		result.Actions = append(result.Actions, trace)
+	}
+	return result
+}
+
+// unpackRevertReason returns the reason string of a solidity revert, if the
+// output carries one.
+func unpackRevertReason(output []byte) string {
+	if len(output) < len(revertSelector) || !bytes.Equal(output[:len(revertSelector)], revertSelector) {
+		return ""
+	}
+	typ, _ := abi.NewType("string")
+	var reason string
+	if err := (abi.Arguments{{Type: typ}}).Unpack(&reason, output[len(revertSelector):]); err != nil {
+		return ""
+	}
+	return reason
+}
+
+// formatLogs formats EVM returned structured logs for json output.
+func formatLogs(logs []vm.StructLog) []StructLogRes {
+	formatted := make([]StructLogRes, len(logs))
+	for index, trace := range logs {
+		formatted[index] = StructLogRes{
+			Pc:      trace.Pc,
+			Op:      trace.Op.String(),
+			Gas:     trace.Gas,
+			GasCost: trace.GasCost,
+			Depth:   trace.Depth,
+			Error:   trace.ErrorString(),
+		}
+		if trace.Stack != nil {
+			stack := make([]string, len(trace.Stack))
+			for i, stackValue := range trace.Stack {
+				stack[i] = fmt.Sprintf("%x", common.LeftPadBytes(stackValue.Bytes(), 32))
+			}
+			formatted[index].Stack = &stack
+		}
+		if trace.Memory != nil {
+			memory := make([]string, 0, (len(trace.Memory)+31)/32)
+			for i := 0; i+32 <= len(trace.Memory); i += 32 {
+				memory = append(memory, fmt.Sprintf("%x", trace.Memory[i:i+32]))
+			}
+			formatted[index].Memory = &memory
+		}
+		if trace.Storage != nil {
+			storage := make(map[string]string)
+			for i, storageValue := range trace.Storage {
+				storage[fmt.Sprintf("%x", i)] = fmt.Sprintf("%x", storageValue)
+			}
+			formatted[index].Storage = &storage
+		}
+	}
+	return formatted
+}
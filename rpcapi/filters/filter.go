@@ -85,6 +85,11 @@ Logs:
 	return ret
 }
 
+// FilterLogs returns the logs matching the accounts and topics of the query.
+func FilterLogs(logs []*types.Log, query FilterQuery) []*types.Log {
+	return filterLogs(logs, query.Accounts, query.Topics)
+}
+
 func bloomFilter(bloom types.Bloom, accounts []common.Name, topics [][]common.Hash) bool {
 	if len(accounts) > 0 {
 		var included bool
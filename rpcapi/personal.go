@@ -0,0 +1,186 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/keystore"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+// defaultUnlockDuration is the number of seconds a key is unlocked for when
+// no duration is given.
+const defaultUnlockDuration = 300
+
+// PrivatePersonalAPI provides an API to manage the keys of the node keystore
+// and to sign transactions with them.
+type PrivatePersonalAPI struct {
+	b Backend
+}
+
+// NewPrivatePersonalAPI creates a new personal API.
+func NewPrivatePersonalAPI(b Backend) *PrivatePersonalAPI {
+	return &PrivatePersonalAPI{b}
+}
+
+// NewKey generates a key encrypted with passphrase and returns its public key.
+func (s *PrivatePersonalAPI) NewKey(passphrase string) (common.PubKey, error) {
+	key, err := s.b.KeyManager().NewKey(passphrase)
+	if err != nil {
+		return common.PubKey{}, err
+	}
+	return key.PubKey, nil
+}
+
+// ImportRawKey stores the hex encoded private key encrypted with passphrase.
+func (s *PrivatePersonalAPI) ImportRawKey(privKey string, passphrase string) (common.PubKey, error) {
+	priv, err := crypto.HexToECDSA(privKey)
+	if err != nil {
+		return common.PubKey{}, err
+	}
+	key, err := s.b.KeyManager().ImportECDSA(priv, passphrase)
+	if err != nil {
+		return common.PubKey{}, err
+	}
+	return key.PubKey, nil
+}
+
+// ListKeys returns the public keys of the keystore.
+func (s *PrivatePersonalAPI) ListKeys() ([]common.PubKey, error) {
+	return s.b.KeyManager().Keys()
+}
+
+// UnlockKey unlocks the key of pubKey for duration seconds, 300 if nil. A
+// duration of 0 unlocks the key until LockKey is called.
+func (s *PrivatePersonalAPI) UnlockKey(pubKey common.PubKey, passphrase string, duration *uint64) (bool, error) {
+	d := uint64(defaultUnlockDuration)
+	if duration != nil {
+		d = *duration
+	}
+	if err := s.b.KeyManager().TimedUnlock(pubKey, passphrase, time.Duration(d)*time.Second); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LockKey removes the unlocked key of pubKey from memory.
+func (s *PrivatePersonalAPI) LockKey(pubKey common.PubKey) bool {
+	s.b.KeyManager().Lock(pubKey)
+	return true
+}
+
+// SendTxArgs represents the arguments to sign a single action transaction.
+type SendTxArgs struct {
+	ActionType  types.ActionType `json:"actionType"`
+	From        common.Name      `json:"from"`
+	To          common.Name      `json:"to"`
+	Nonce       *uint64          `json:"nonce"`
+	AssetID     uint64           `json:"assetId"`
+	Gas         uint64           `json:"gas"`
+	GasAssetID  *uint64          `json:"gasAssetId"`
+	GasPrice    *big.Int         `json:"gasPrice"`
+	Value       *big.Int         `json:"value"`
+	Data        hexutil.Bytes    `json:"data"`
+	Remark      hexutil.Bytes    `json:"remark"`
+	AuthorIndex uint64           `json:"authorIndex"`
+}
+
+// SignTransactionResult is the rlp encoded signed transaction and its hash.
+type SignTransactionResult struct {
+	Raw  hexutil.Bytes `json:"raw"`
+	Hash common.Hash   `json:"hash"`
+}
+
+// SignTransaction signs the transaction of args with the key of pubKey. The
+// unlocked key is used if passphrase is empty. The nonce defaults to the
+// pending nonce of the sender and the gas price to the suggested price.
+func (s *PrivatePersonalAPI) SignTransaction(ctx context.Context, args SendTxArgs, pubKey common.PubKey, passphrase string) (*SignTransactionResult, error) {
+	tx, err := s.signTransaction(ctx, args, pubKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &SignTransactionResult{Raw: raw, Hash: tx.Hash()}, nil
+}
+
+// SendTransaction signs the transaction of args like SignTransaction and
+// submits it to the transaction pool.
+func (s *PrivatePersonalAPI) SendTransaction(ctx context.Context, args SendTxArgs, pubKey common.PubKey, passphrase string) (common.Hash, error) {
+	tx, err := s.signTransaction(ctx, args, pubKey, passphrase)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return submitTransaction(ctx, s.b, tx)
+}
+
+func (s *PrivatePersonalAPI) signTransaction(ctx context.Context, args SendTxArgs, pubKey common.PubKey, passphrase string) (*types.Transaction, error) {
+	var (
+		priv *ecdsa.PrivateKey
+		err  error
+	)
+	if passphrase == "" {
+		priv, err = s.b.KeyManager().UnlockedKey(pubKey)
+	} else {
+		var key *keystore.Key
+		if key, err = s.b.KeyManager().GetKey(pubKey, passphrase); err == nil {
+			priv = key.PrivateKey
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Nonce == nil {
+		nonce, err := s.b.TxPool().State().GetNonce(args.From)
+		if err != nil {
+			return nil, err
+		}
+		args.Nonce = &nonce
+	}
+	if args.GasPrice == nil {
+		price, err := s.b.SuggestPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		args.GasPrice = price
+	}
+	if args.GasAssetID == nil {
+		args.GasAssetID = &s.b.ChainConfig().SysTokenID
+	}
+	if args.Value == nil {
+		args.Value = new(big.Int)
+	}
+
+	action := types.NewAction(args.ActionType, args.From, args.To, *args.Nonce, args.AssetID, args.Gas, args.Value, args.Data, args.Remark)
+	tx := types.NewTransaction(*args.GasAssetID, args.GasPrice, action)
+	keyPair := types.MakeKeyPair(priv, []uint64{args.AuthorIndex})
+	if err := types.SignActionWithMultiKey(action, tx, types.NewSigner(s.b.ChainConfig().ChainID), 0, []*types.KeyPair{keyPair}); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/unichainplatform/unichain/common"
 	"github.com/unichainplatform/unichain/consensus/dpos"
 	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/keystore"
 	"github.com/unichainplatform/unichain/types"
 	"github.com/unichainplatform/unichain/utils/abi"
 	"github.com/unichainplatform/unichain/utils/rlp"
@@ -65,11 +66,25 @@ func NewAccount(api *API, name common.Name, priv *ecdsa.PrivateKey, feeid uint64
 	}
 }
 
+// NewAccountFromKeystore new account whose key of pubKey is decrypted from ks
+func NewAccountFromKeystore(api *API, name common.Name, ks *keystore.KeyStore, pubKey common.PubKey, passphrase string, feeid uint64, nonce uint64, checked bool, chainID *big.Int) (*Account, error) {
+	key, err := ks.GetKey(pubKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewAccount(api, name, key.PrivateKey, feeid, nonce, checked, chainID), nil
+}
+
 // Pubkey account pub key
 func (acc *Account) Pubkey() common.PubKey {
 	return common.BytesToPubKey(crypto.FromECDSAPub(&acc.priv.PublicKey))
 }
 
+// Name account name
+func (acc *Account) Name() common.Name {
+	return acc.name
+}
+
 //=====================================================================================
 //                       Transactions
 //=====================================================================================
@@ -1032,3 +1047,54 @@ func formDestroyAsset(abifile string, assetID, value *big.Int) ([]byte, error) {
 	}
 	return common.Hex2Bytes(destroyAssetInput), nil
 }
+
+// DeployContract deploys the code and constructor arguments of input to the
+// account itself
+func (acc *Account) DeployContract(value *big.Int, id uint64, gas uint64, input []byte) (hash common.Hash, err error) {
+	return acc.sendContract(types.CreateContract, acc.name, value, id, gas, input)
+}
+
+// InvokeContract calls the contract to with input, transferring value of
+// asset id to it
+func (acc *Account) InvokeContract(to common.Name, value *big.Int, id uint64, gas uint64, input []byte) (hash common.Hash, err error) {
+	return acc.sendContract(types.CallContract, to, value, id, gas, input)
+}
+
+func (acc *Account) sendContract(t types.ActionType, to common.Name, value *big.Int, id uint64, gas uint64, input []byte) (hash common.Hash, err error) {
+	nonce := acc.nonce
+	if nonce == math.MaxUint64 {
+		nonce, err = acc.api.AccountNonce(acc.name.String())
+		if err != nil {
+			return
+		}
+	}
+
+	action := types.NewAction(t, acc.name, to, nonce, id, gas, value, input, nil)
+	tx := types.NewTransaction(acc.feeid, acc.gasprice, []*types.Action{action}...)
+	key := types.MakeKeyPair(acc.priv, []uint64{0})
+	err = types.SignActionWithMultiKey(action, tx, types.NewSigner(acc.chainID), 0, []*types.KeyPair{key})
+	if err != nil {
+		return
+	}
+	rawtx, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return
+	}
+
+	hash, err = acc.api.SendRawTransaction(rawtx)
+	if err != nil {
+		return
+	}
+	if acc.checked {
+		//after
+		err = acc.utilReceipt(hash, timeout)
+		if err != nil {
+			return
+		}
+	}
+
+	if acc.nonce != math.MaxUint64 {
+		acc.nonce++
+	}
+	return
+}
@@ -17,6 +17,7 @@
 package sdk
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/unichainplatform/unichain/rpc"
@@ -25,6 +26,7 @@ import (
 // API rpc api
 type API struct {
 	rpchost string
+	wshost  string // websocket endpoint of subscriptions, empty if not supported
 	client  *rpc.Client
 }
 
@@ -39,3 +41,17 @@ func NewAPI(rpchost string) *API {
 	api.client = client
 	return api
 }
+
+// NewWSAPI create api interface on the websocket endpoint, which also serves
+// the subscriptions
+func NewWSAPI(wshost string) *API {
+	client, err := rpc.DialWebsocket(context.Background(), wshost, "")
+	if err != nil {
+		panic(fmt.Sprintf("dial websocket %v err %v", wshost, err))
+	}
+	api := &API{}
+	api.rpchost = wshost
+	api.wshost = wshost
+	api.client = client
+	return api
+}
@@ -20,6 +20,7 @@ import (
 	"math/big"
 
 	"github.com/unichainplatform/unichain/asset"
+	"github.com/unichainplatform/unichain/common"
 	"github.com/unichainplatform/unichain/rpcapi"
 )
 
@@ -72,6 +73,13 @@ func (api *API) AssetInfoByID(id uint64) (*asset.AssetObject, error) {
 	return assetInfo, err
 }
 
+// AccountProof get merkle proofs of account and its contract storage keys at block number
+func (api *API) AccountProof(name string, keys []common.Hash, number int64) (*rpcapi.AccountProof, error) {
+	proof := &rpcapi.AccountProof{}
+	err := api.client.Call(proof, "account_getProof", name, keys, number)
+	return proof, err
+}
+
 // BalanceByAssetID get asset balance
 func (api *API) BalanceByAssetID(name string, id uint64, typeID uint64) (*big.Int, error) {
 	balance := big.NewInt(0)
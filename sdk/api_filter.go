@@ -0,0 +1,44 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package sdk
+
+import (
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/rpcapi/filters"
+	"github.com/unichainplatform/unichain/types"
+)
+
+// NewFilter creates a filter of the logs matching crit on the node
+func (api *API) NewFilter(crit filters.FilterCriteria) (rpc.ID, error) {
+	var id rpc.ID
+	err := api.client.Call(&id, "uni_newFilter", crit)
+	return id, err
+}
+
+// GetFilterLogs get the logs of the filter since the last poll
+func (api *API) GetFilterLogs(id rpc.ID) ([]*types.RPCLog, error) {
+	logs := []*types.RPCLog{}
+	err := api.client.Call(&logs, "uni_getFilterChanges", id)
+	return logs, err
+}
+
+// UninstallFilter removes the filter from the node
+func (api *API) UninstallFilter(id rpc.ID) (bool, error) {
+	removed := false
+	err := api.client.Call(&removed, "uni_uninstallFilter", id)
+	return removed, err
+}
@@ -17,6 +17,7 @@
 package sdk
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/unichainplatform/unichain/params"
@@ -24,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/unichainplatform/unichain/common"
 	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/rpcapi"
 	"github.com/unichainplatform/unichain/types"
 )
 
@@ -55,6 +57,31 @@ func (api *API) GetBlockByNumber(number int64, fullTx bool) (map[string]interfac
 	return block, err
 }
 
+// GetHeaderByNumber get block header, latest block if number is negative
+func (api *API) GetHeaderByNumber(number int64) (*types.Header, error) {
+	var header *types.Header
+	err := api.client.Call(&header, "uni_getHeaderByNumber", rpc.BlockNumber(number))
+	if err == nil && header == nil {
+		err = fmt.Errorf("block %v not found", number)
+	}
+	return header, err
+}
+
+// GetBlockReceipts get the receipts of the transactions of block number
+func (api *API) GetBlockReceipts(number uint64) ([]*types.Receipt, error) {
+	var result *struct {
+		Receipts []*types.Receipt `json:"receipts"`
+	}
+	err := api.client.Call(&result, "uni_getBlockAndResultByNumber", rpc.BlockNumber(number))
+	if err == nil && result == nil {
+		err = fmt.Errorf("block %v not found", number)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Receipts, nil
+}
+
 // GetTransactionByHash get tx info by hash
 func (api *API) GetTransactionByHash(hash common.Hash) (*types.RPCTransaction, error) {
 	tx := &types.RPCTransaction{}
@@ -76,6 +103,21 @@ func (api *API) GasPrice() (*big.Int, error) {
 	return gasprice, err
 }
 
+// Call executes the call of args on the state of block number without
+// creating a transaction, latest block if number is negative
+func (api *API) Call(args *rpcapi.CallArgs, number int64) ([]byte, error) {
+	var result hexutil.Bytes
+	err := api.client.Call(&result, "uni_call", args, rpc.BlockNumber(number))
+	return result, err
+}
+
+// EstimateGas estimate the gas limit of the call of args
+func (api *API) EstimateGas(args *rpcapi.CallArgs) (uint64, error) {
+	gas := uint64(0)
+	err := api.client.Call(&gas, "uni_estimateGas", args)
+	return gas, err
+}
+
 // GetChainConfig get chain config
 func (api *API) GetChainConfig() (*params.ChainConfig, error) {
 	cfg := &params.ChainConfig{}
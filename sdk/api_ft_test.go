@@ -56,6 +56,18 @@ func TestGetBlockByNumber(t *testing.T) {
 		So(block, ShouldNotBeNil)
 	})
 }
+func TestGetHeaderByNumber(t *testing.T) {
+	Convey("uni_getHeaderByNumber", t, func() {
+		block, err := api.GetCurrentBlock(false)
+		So(err, ShouldBeNil)
+		header, err := api.GetHeaderByNumber(int64(block["number"].(float64)))
+		So(err, ShouldBeNil)
+		So(header.Hash().Hex(), ShouldEqual, block["hash"])
+		receipts, err := api.GetBlockReceipts(header.Number.Uint64())
+		So(err, ShouldBeNil)
+		So(len(receipts), ShouldEqual, len(block["transactions"].([]interface{})))
+	})
+}
 func TestGetTransactionByHash(t *testing.T) {
 	Convey("ft_getTransactionByHash", t, func() {
 		block, err := api.GetCurrentBlock(false)
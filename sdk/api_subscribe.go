@@ -0,0 +1,250 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/event"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/rpcapi/filters"
+	"github.com/unichainplatform/unichain/types"
+)
+
+// resubscribeInterval is the wait before dialing the websocket endpoint again
+// after a subscription is lost.
+var resubscribeInterval = 3 * time.Second
+
+// SubscribeNewHeads subscribes to the headers of the blocks appended to the
+// chain. After reconnecting, the headers since the last one sent are fetched
+// before the new ones.
+//
+// Headers are sent in increasing number only, a header at or below the number
+// of the last one sent is dropped. After a reorg the headers of the new chain
+// are not sent up to the old head, the reorg shows as a header whose
+// ParentHash is not the hash of the header sent before.
+func (api *API) SubscribeNewHeads(ch chan<- *types.Header) (event.Subscription, error) {
+	head, err := api.GetHeaderByNumber(int64(rpc.LatestBlockNumber))
+	if err != nil {
+		return nil, err
+	}
+	last := head.Number.Uint64()
+	send := func(header *types.Header, quit <-chan struct{}) {
+		if header.Number.Uint64() <= last {
+			return
+		}
+		last = header.Number.Uint64()
+		select {
+		case ch <- header:
+		case <-quit:
+		}
+	}
+	resume := func(quit <-chan struct{}) error {
+		head, err := api.GetHeaderByNumber(int64(rpc.LatestBlockNumber))
+		if err != nil {
+			return err
+		}
+		for number := last + 1; number < head.Number.Uint64(); number++ {
+			header, err := api.GetHeaderByNumber(int64(number))
+			if err != nil {
+				return err
+			}
+			send(header, quit)
+		}
+		send(head, quit)
+		return nil
+	}
+	return api.subscribe(func(msg json.RawMessage, quit <-chan struct{}) error {
+		header := new(types.Header)
+		if err := json.Unmarshal(msg, header); err != nil {
+			return err
+		}
+		send(header, quit)
+		return nil
+	}, resume, "newHeads")
+}
+
+// SubscribeLogs subscribes to the logs matching crit. After reconnecting, the
+// logs of the blocks since the last one sent are fetched before the new ones.
+//
+// Logs are sent in increasing block number only, a log of a block before the
+// one of the last log sent is dropped. The node does not notify removed logs,
+// so logs of blocks reverted by a reorg are not retracted and the logs of the
+// new chain are not sent for blocks before the last one with logs sent.
+func (api *API) SubscribeLogs(crit filters.FilterCriteria, ch chan<- types.Log) (event.Subscription, error) {
+	head, err := api.GetHeaderByNumber(int64(rpc.LatestBlockNumber))
+	if err != nil {
+		return nil, err
+	}
+	// the logs of block next are sent up to skip, the blocks before are done
+	next, skip := head.Number.Uint64()+1, 0
+	send := func(log *types.Log, quit <-chan struct{}) {
+		switch {
+		case log.BlockNumber < next:
+			return
+		case log.BlockNumber == next:
+			skip++
+		default:
+			next, skip = log.BlockNumber, 1
+		}
+		select {
+		case ch <- *log:
+		case <-quit:
+		}
+	}
+	resume := func(quit <-chan struct{}) error {
+		head, err := api.GetHeaderByNumber(int64(rpc.LatestBlockNumber))
+		if err != nil {
+			return err
+		}
+		for number := next; number <= head.Number.Uint64(); number++ {
+			receipts, err := api.GetBlockReceipts(number)
+			if err != nil {
+				return err
+			}
+			var logs []*types.Log
+			for _, receipt := range receipts {
+				logs = append(logs, receipt.Logs...)
+			}
+			logs = filters.FilterLogs(logs, filters.FilterQuery(crit))
+			if number == next {
+				if skip > len(logs) {
+					skip = len(logs)
+				}
+				logs = logs[skip:]
+			}
+			for _, log := range logs {
+				send(log, quit)
+			}
+		}
+		next, skip = head.Number.Uint64()+1, 0
+		return nil
+	}
+	return api.subscribe(func(msg json.RawMessage, quit <-chan struct{}) error {
+		log := new(types.Log)
+		if err := json.Unmarshal(msg, log); err != nil {
+			return err
+		}
+		send(log, quit)
+		return nil
+	}, resume, "logs", crit)
+}
+
+// SubscribeNewPendingTransactions subscribes to the hashes of the transactions
+// entering the pool of the node. Transactions entering the pool while the
+// subscription is reconnecting are missed.
+func (api *API) SubscribeNewPendingTransactions(ch chan<- common.Hash) (event.Subscription, error) {
+	return api.subscribe(func(msg json.RawMessage, quit <-chan struct{}) error {
+		var hash common.Hash
+		if err := json.Unmarshal(msg, &hash); err != nil {
+			return err
+		}
+		select {
+		case ch <- hash:
+		case <-quit:
+		}
+		return nil
+	}, nil, "newPendingTransactions")
+}
+
+// subscribe keeps the subscription of args on the websocket endpoint, passing
+// its notifications to handle. The subscription is made again on a new
+// connection when it is lost, resume is called before the notifications of
+// the new subscription are handled.
+func (api *API) subscribe(handle func(json.RawMessage, <-chan struct{}) error, resume func(<-chan struct{}) error, args ...interface{}) (event.Subscription, error) {
+	if api.wshost == "" {
+		return nil, fmt.Errorf("subscription requires a websocket endpoint")
+	}
+	client, sub, notifications, err := api.dialSubscription(args)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for {
+			err := forward(sub, notifications, handle, quit)
+			sub.Unsubscribe()
+			client.Close()
+			if err != nil {
+				return err
+			}
+			if client, sub, notifications, err = api.resubscribe(resume, args, quit); err != nil || client == nil {
+				return err
+			}
+		}
+	}), nil
+}
+
+// forward handles the notifications of sub until it fails or quits, the
+// error of handle is returned.
+func forward(sub *rpc.ClientSubscription, notifications <-chan json.RawMessage, handle func(json.RawMessage, <-chan struct{}) error, quit <-chan struct{}) error {
+	for {
+		select {
+		case msg := <-notifications:
+			if err := handle(msg, quit); err != nil {
+				return err
+			}
+		case <-sub.Err():
+			return nil
+		case <-quit:
+			return nil
+		}
+	}
+}
+
+// resubscribe dials the subscription until it succeeds and is resumed, nil
+// client if it quits first.
+func (api *API) resubscribe(resume func(<-chan struct{}) error, args []interface{}, quit <-chan struct{}) (*rpc.Client, *rpc.ClientSubscription, chan json.RawMessage, error) {
+	for {
+		select {
+		case <-quit:
+			return nil, nil, nil, nil
+		case <-time.After(resubscribeInterval):
+		}
+		client, sub, notifications, err := api.dialSubscription(args)
+		if err != nil {
+			continue
+		}
+		if resume != nil {
+			if err := resume(quit); err != nil {
+				sub.Unsubscribe()
+				client.Close()
+				continue
+			}
+		}
+		return client, sub, notifications, nil
+	}
+}
+
+func (api *API) dialSubscription(args []interface{}) (*rpc.Client, *rpc.ClientSubscription, chan json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := rpc.DialWebsocket(ctx, api.wshost, "")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	notifications := make(chan json.RawMessage, 128)
+	sub, err := client.UniSubscribe(ctx, notifications, args...)
+	if err != nil {
+		client.Close()
+		return nil, nil, nil, err
+	}
+	return client, sub, notifications, nil
+}
@@ -0,0 +1,628 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/event"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/rpcapi"
+	"github.com/unichainplatform/unichain/rpcapi/filters"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/abi"
+)
+
+var (
+	errNoSigner = errors.New("no account to sign the transaction")
+
+	nameT    = reflect.TypeOf(common.Name(""))
+	addressT = reflect.TypeOf(common.Address{})
+)
+
+// CallOpts is the collection of options to fine tune a contract call.
+type CallOpts struct {
+	From        common.Name // account the call is made from, the contract itself if empty
+	BlockNumber *big.Int    // block the call is executed on, latest if nil
+}
+
+// TransactOpts is the collection of options to send a contract transaction.
+type TransactOpts struct {
+	From     *Account // account signing the action
+	AssetID  uint64   // asset of the value
+	Value    *big.Int // value transferred to the contract
+	GasLimit uint64   // gas limit of the action, estimated by the node if zero
+}
+
+// WatchOpts is the collection of options to watch the events of a contract.
+type WatchOpts struct {
+	Interval time.Duration // interval polling the filter of the node without websocket, one second if zero
+}
+
+// BoundContract is the base wrapper of a contract on the chain, used by the
+// bindings generated by uniabigen. Contract arguments of the address type are
+// account ids, account names are used in place of them.
+type BoundContract struct {
+	name common.Name
+	abi  abi.ABI
+	api  *API
+
+	mu    sync.Mutex
+	ids   map[common.Name]uint64
+	names map[uint64]common.Name
+}
+
+// NewBoundContract binds the contract of the account name.
+func NewBoundContract(name common.Name, abi abi.ABI, api *API) *BoundContract {
+	return &BoundContract{
+		name:  name,
+		abi:   abi,
+		api:   api,
+		ids:   make(map[common.Name]uint64),
+		names: make(map[uint64]common.Name),
+	}
+}
+
+// DeployContract deploys the bytecode with the constructor params to the
+// account of opts.From and binds it.
+func DeployContract(opts *TransactOpts, contractABI abi.ABI, bytecode []byte, api *API, params ...interface{}) (common.Hash, *BoundContract, error) {
+	if opts == nil || opts.From == nil {
+		return common.Hash{}, nil, errNoSigner
+	}
+	c := NewBoundContract(opts.From.Name(), contractABI, api)
+	input, err := c.pack("", params...)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	hash, err := c.transact(opts, types.CreateContract, append(common.CopyBytes(bytecode), input...))
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	return hash, c, nil
+}
+
+// Name returns the account name of the contract.
+func (c *BoundContract) Name() common.Name {
+	return c.name
+}
+
+// Call executes the constant method with args without a transaction and
+// stores its outputs in results, pointers to the values of each output.
+func (c *BoundContract) Call(opts *CallOpts, results []interface{}, method string, args ...interface{}) error {
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+	input, err := c.pack(method, args...)
+	if err != nil {
+		return err
+	}
+	msg := &rpcapi.CallArgs{
+		ActionType: types.CallContract,
+		From:       opts.From,
+		To:         c.name,
+		Gas:        params.BlockGasLimit,
+		GasPrice:   new(big.Int),
+		Value:      new(big.Int),
+		Data:       input,
+	}
+	if msg.From == "" {
+		msg.From = c.name
+	}
+	number := rpc.LatestBlockNumber.Int64()
+	if opts.BlockNumber != nil {
+		number = opts.BlockNumber.Int64()
+	}
+	output, err := c.api.Call(msg, number)
+	if err != nil {
+		return err
+	}
+	outputs := c.abi.Methods[method].Outputs
+	if len(output) == 0 && len(outputs) > 0 {
+		return fmt.Errorf("no output of %v from contract %v", method, c.name)
+	}
+	values, err := outputs.UnpackValues(output)
+	if err != nil {
+		return err
+	}
+	if len(values) != len(results) {
+		return fmt.Errorf("abi: %d outputs of %v, %d results", len(values), method, len(results))
+	}
+	for i, value := range values {
+		if err := c.assign(reflect.ValueOf(results[i]).Elem(), reflect.ValueOf(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Transact sends a CallContract action of the method with params signed by
+// opts.From.
+func (c *BoundContract) Transact(opts *TransactOpts, method string, params ...interface{}) (common.Hash, error) {
+	input, err := c.pack(method, params...)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return c.transact(opts, types.CallContract, input)
+}
+
+func (c *BoundContract) transact(opts *TransactOpts, t types.ActionType, input []byte) (common.Hash, error) {
+	if opts == nil || opts.From == nil {
+		return common.Hash{}, errNoSigner
+	}
+	value := opts.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	gas := opts.GasLimit
+	if gas == 0 {
+		var err error
+		gas, err = c.api.EstimateGas(&rpcapi.CallArgs{
+			ActionType: t,
+			From:       opts.From.Name(),
+			To:         c.name,
+			AssetID:    opts.AssetID,
+			GasPrice:   new(big.Int),
+			Value:      value,
+			Data:       input,
+		})
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to estimate gas: %v", err)
+		}
+	}
+	if t == types.CreateContract {
+		return opts.From.DeployContract(value, opts.AssetID, gas, input)
+	}
+	return opts.From.InvokeContract(c.name, value, opts.AssetID, gas, input)
+}
+
+// WatchLogs watches the logs of the event, whose indexed arguments match
+// query, the alternatives of each of them. An empty alternative list matches
+// any value. The logs are subscribed to on the websocket endpoint of the api,
+// a filter of the node is polled without it.
+func (c *BoundContract) WatchLogs(opts *WatchOpts, name string, query ...[]interface{}) (<-chan types.Log, event.Subscription, error) {
+	topics, err := c.topics(name, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	crit := filters.FilterCriteria{Accounts: []common.Name{c.name}, Topics: topics}
+	if c.api.wshost != "" {
+		logs := make(chan types.Log, 128)
+		sub, err := c.api.SubscribeLogs(crit, logs)
+		if err != nil {
+			return nil, nil, err
+		}
+		return logs, sub, nil
+	}
+	id, err := c.api.NewFilter(crit)
+	if err != nil {
+		return nil, nil, err
+	}
+	interval := time.Second
+	if opts != nil && opts.Interval > 0 {
+		interval = opts.Interval
+	}
+
+	logs := make(chan types.Log, 128)
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		defer c.api.UninstallFilter(id)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				rpcLogs, err := c.api.GetFilterLogs(id)
+				if err != nil {
+					return err
+				}
+				for _, log := range rpcLogs {
+					select {
+					case logs <- types.Log{
+						Name:        log.Name,
+						Topics:      log.Topics,
+						Data:        log.Data,
+						BlockNumber: log.BlockNumber,
+						BlockHash:   log.BlockHash,
+						TxHash:      log.TxHash,
+						Index:       log.Index,
+						ActionIndex: log.ActionIndex,
+						TxIndex:     log.TxIndex,
+					}:
+					case <-quit:
+						return nil
+					}
+				}
+			case <-quit:
+				return nil
+			}
+		}
+	})
+	return logs, sub, nil
+}
+
+// UnpackLog stores the arguments of the event of log in results, pointers to
+// the values of each argument. Indexed arguments of dynamic types are left as
+// the hash of their topic.
+func (c *BoundContract) UnpackLog(results []interface{}, name string, log types.Log) error {
+	ev, ok := c.abi.Events[name]
+	if !ok {
+		return fmt.Errorf("abi: event %v not found", name)
+	}
+	if len(results) != len(ev.Inputs) {
+		return fmt.Errorf("abi: %d arguments of %v, %d results", len(ev.Inputs), name, len(results))
+	}
+	topics := log.Topics
+	if !ev.Anonymous {
+		if len(topics) == 0 || topics[0] != ev.Id() {
+			return fmt.Errorf("abi: log is not event %v", name)
+		}
+		topics = topics[1:]
+	}
+	data, err := ev.Inputs.UnpackValues(log.Data)
+	if err != nil {
+		return err
+	}
+
+	for i, input := range ev.Inputs {
+		var value interface{}
+		switch {
+		case !input.Indexed:
+			value, data = data[0], data[1:]
+		case len(topics) == 0:
+			return fmt.Errorf("abi: missing topic of %v of event %v", input.Name, name)
+		case isHashedTopic(input.Type):
+			value, topics = topics[0], topics[1:]
+		default:
+			values, err := abi.Arguments{{Type: input.Type}}.UnpackValues(topics[0].Bytes())
+			if err != nil {
+				return err
+			}
+			value, topics = values[0], topics[1:]
+		}
+		if err := c.assign(reflect.ValueOf(results[i]).Elem(), reflect.ValueOf(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WatchEvents watches the events of the logs of WatchLogs, sending them to
+// sink, a channel of pointers to the structs of the events as decoded by
+// UnpackLogIntoStruct.
+func (c *BoundContract) WatchEvents(opts *WatchOpts, name string, sink interface{}, query ...[]interface{}) (event.Subscription, error) {
+	sinkValue := reflect.ValueOf(sink)
+	sinkType := sinkValue.Type()
+	if sinkType.Kind() != reflect.Chan || sinkType.ChanDir()&reflect.SendDir == 0 ||
+		sinkType.Elem().Kind() != reflect.Ptr || sinkType.Elem().Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sink %v is not a channel of struct pointers", sinkType)
+	}
+	logs, sub, err := c.WatchLogs(opts, name, query...)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev := reflect.New(sinkType.Elem().Elem())
+				if err := c.UnpackLogIntoStruct(ev.Interface(), name, log); err != nil {
+					return err
+				}
+				cases := []reflect.SelectCase{
+					{Dir: reflect.SelectSend, Chan: sinkValue, Send: ev},
+					{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sub.Err())},
+					{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(quit)},
+				}
+				switch chosen, recv, ok := reflect.Select(cases); {
+				case chosen == 1 && ok && !recv.IsNil():
+					return recv.Interface().(error)
+				case chosen != 0:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// UnpackLogIntoStruct stores the arguments of the event of log in the fields
+// of the struct out, the field tagged abi:"<name>" or named after the
+// argument in camel case. A Raw field of types.Log is set to the log.
+func (c *BoundContract) UnpackLogIntoStruct(out interface{}, name string, log types.Log) error {
+	value := reflect.ValueOf(out)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("abi: UnpackLogIntoStruct(non-struct pointer %T)", out)
+	}
+	value = value.Elem()
+	ev, ok := c.abi.Events[name]
+	if !ok {
+		return fmt.Errorf("abi: event %v not found", name)
+	}
+	tagged := make(map[string]reflect.Value)
+	for i := 0; i < value.NumField(); i++ {
+		if tag, ok := value.Type().Field(i).Tag.Lookup("abi"); ok {
+			tagged[tag] = value.Field(i)
+		}
+	}
+	results := make([]interface{}, len(ev.Inputs))
+	for i, input := range ev.Inputs {
+		field, ok := tagged[input.Name]
+		if !ok {
+			field = value.FieldByName(abi.ToCamelCase(input.Name))
+		}
+		if field.IsValid() && field.CanSet() {
+			results[i] = field.Addr().Interface()
+		} else {
+			results[i] = new(interface{})
+		}
+	}
+	if err := c.UnpackLog(results, name, log); err != nil {
+		return err
+	}
+	if raw := value.FieldByName("Raw"); raw.IsValid() && raw.Type() == reflect.TypeOf(log) {
+		raw.Set(reflect.ValueOf(log))
+	}
+	return nil
+}
+
+// isHashedTopic returns whether indexed arguments of typ are stored as the
+// hash of their encoding in topics.
+func isHashedTopic(typ abi.Type) bool {
+	switch typ.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+func (c *BoundContract) topics(name string, query [][]interface{}) ([][]common.Hash, error) {
+	ev, ok := c.abi.Events[name]
+	if !ok {
+		return nil, fmt.Errorf("abi: event %v not found", name)
+	}
+	var topics [][]common.Hash
+	if !ev.Anonymous {
+		topics = append(topics, []common.Hash{ev.Id()})
+	}
+	var indexed abi.Arguments
+	for _, input := range ev.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(query) > len(indexed) {
+		return nil, fmt.Errorf("abi: %d indexed arguments of %v, %d rules", len(indexed), name, len(query))
+	}
+	for i, rule := range query {
+		var hashes []common.Hash
+		for _, item := range rule {
+			topic, err := c.topic(indexed[i].Type, item)
+			if err != nil {
+				return nil, err
+			}
+			hashes = append(hashes, topic)
+		}
+		topics = append(topics, hashes)
+	}
+	return topics, nil
+}
+
+func (c *BoundContract) topic(typ abi.Type, item interface{}) (common.Hash, error) {
+	switch v := item.(type) {
+	case common.Hash:
+		return v, nil
+	case common.Name:
+		addr, err := c.address(v)
+		return common.BytesToHash(addr.Bytes()), err
+	}
+	if isHashedTopic(typ) {
+		return common.Hash{}, fmt.Errorf("abi: topic of %v must be a hash", typ)
+	}
+	packed, err := abi.Arguments{{Type: typ}}.Pack(item)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(packed), nil
+}
+
+// pack packs the params of the method, the constructor if empty, replacing
+// the account names by their addresses.
+func (c *BoundContract) pack(method string, params ...interface{}) ([]byte, error) {
+	args := make([]interface{}, len(params))
+	for i, param := range params {
+		args[i] = param
+		if v := reflect.ValueOf(param); v.IsValid() {
+			arg, err := c.toABI(v)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = arg.Interface()
+		}
+	}
+	return c.abi.Pack(method, args...)
+}
+
+// abiType returns typ with the account names replaced by addresses.
+func abiType(typ reflect.Type) reflect.Type {
+	switch {
+	case typ == nameT:
+		return addressT
+	case typ.Kind() == reflect.Slice:
+		return reflect.SliceOf(abiType(typ.Elem()))
+	case typ.Kind() == reflect.Array:
+		return reflect.ArrayOf(typ.Len(), abiType(typ.Elem()))
+	case typ.Kind() == reflect.Struct:
+		// tuples are packed from the exported fields
+		var fields []reflect.StructField
+		changed := false
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath != "" {
+				changed = true
+				continue
+			}
+			if fieldType := abiType(field.Type); fieldType != field.Type {
+				field.Type, changed = fieldType, true
+			}
+			fields = append(fields, field)
+		}
+		if changed {
+			return reflect.StructOf(fields)
+		}
+	}
+	return typ
+}
+
+func (c *BoundContract) toABI(v reflect.Value) (reflect.Value, error) {
+	typ := abiType(v.Type())
+	switch {
+	case typ == v.Type():
+		return v, nil
+	case v.Type() == nameT:
+		addr, err := c.address(common.Name(v.String()))
+		return reflect.ValueOf(addr), err
+	}
+	var out reflect.Value
+	switch v.Kind() {
+	case reflect.Slice:
+		out = reflect.MakeSlice(typ, v.Len(), v.Len())
+	case reflect.Struct:
+		out = reflect.New(typ).Elem()
+		for i := 0; i < typ.NumField(); i++ {
+			field, err := c.toABI(v.FieldByName(typ.Field(i).Name))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Field(i).Set(field)
+		}
+		return out, nil
+	default:
+		out = reflect.New(typ).Elem()
+	}
+	for i := 0; i < v.Len(); i++ {
+		elem, err := c.toABI(v.Index(i))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out.Index(i).Set(elem)
+	}
+	return out, nil
+}
+
+// assign sets dst to the unpacked src, replacing addresses by the account
+// names of dst.
+func (c *BoundContract) assign(dst, src reflect.Value) error {
+	switch {
+	case src.Type().AssignableTo(dst.Type()):
+		dst.Set(src)
+	case dst.Type() == nameT && src.Type() == addressT:
+		name, err := c.accountName(src.Interface().(common.Address))
+		if err != nil {
+			return err
+		}
+		dst.SetString(name.String())
+	case dst.Kind() == reflect.Slice && (src.Kind() == reflect.Slice || src.Kind() == reflect.Array):
+		dst.Set(reflect.MakeSlice(dst.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			if err := c.assign(dst.Index(i), src.Index(i)); err != nil {
+				return err
+			}
+		}
+	case dst.Kind() == reflect.Array && src.Kind() == reflect.Array && dst.Len() == src.Len():
+		for i := 0; i < src.Len(); i++ {
+			if err := c.assign(dst.Index(i), src.Index(i)); err != nil {
+				return err
+			}
+		}
+	case dst.Kind() == reflect.Struct && src.Kind() == reflect.Struct:
+		// the fields of unpacked tuples are named after the ABI components
+		for i := 0; i < src.NumField(); i++ {
+			field := dst.FieldByName(src.Type().Field(i).Name)
+			if !field.IsValid() || !field.CanSet() {
+				return fmt.Errorf("abi: field %v not found in %v", src.Type().Field(i).Name, dst.Type())
+			}
+			if err := c.assign(field, src.Field(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("abi: cannot assign %v to %v", src.Type(), dst.Type())
+	}
+	return nil
+}
+
+// address returns the address of the account id of name.
+func (c *BoundContract) address(name common.Name) (common.Address, error) {
+	if name == "" {
+		return common.Address{}, nil
+	}
+	c.mu.Lock()
+	id, ok := c.ids[name]
+	c.mu.Unlock()
+	if !ok {
+		account, err := c.api.AccountInfo(name.String())
+		if err != nil {
+			return common.Address{}, err
+		}
+		if account.AcctName == "" {
+			return common.Address{}, fmt.Errorf("account %v not exist", name)
+		}
+		id = account.AccountID
+		c.cache(name, id)
+	}
+	return common.BigToAddress(new(big.Int).SetUint64(id)), nil
+}
+
+// accountName returns the name of the account id of addr.
+func (c *BoundContract) accountName(addr common.Address) (common.Name, error) {
+	id := new(big.Int).SetBytes(addr.Bytes()).Uint64()
+	if id == 0 {
+		return "", nil
+	}
+	c.mu.Lock()
+	name, ok := c.names[id]
+	c.mu.Unlock()
+	if !ok {
+		account, err := c.api.AccountInfoByID(id)
+		if err != nil {
+			return "", err
+		}
+		if account.AcctName == "" {
+			return "", fmt.Errorf("account id %v not exist", id)
+		}
+		name = account.AcctName
+		c.cache(name, id)
+	}
+	return name, nil
+}
+
+func (c *BoundContract) cache(name common.Name, id uint64) {
+	c.mu.Lock()
+	c.ids[name] = id
+	c.names[id] = name
+	c.mu.Unlock()
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package mocktest
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/unichainplatform/unichain/accountmanager"
+	"github.com/unichainplatform/unichain/blockchain"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/params"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/rpcapi"
+	"github.com/unichainplatform/unichain/sdk"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/types"
+)
+
+// proofBackend serves the state of the genesis block with a storage slot set
+// in the system account.
+type proofBackend struct {
+	config *params.ChainConfig
+	state  *state.StateDB
+	header *types.Header
+}
+
+var (
+	proofSlot  = common.BytesToHash([]byte("slot"))
+	proofValue = common.BytesToHash([]byte("slot value"))
+)
+
+func newProofBackend(t *testing.T) *proofBackend {
+	db := rawdb.NewMemoryDatabase()
+	config, _, hash, err := blockchain.SetupGenesisBlock(db, blockchain.DefaultGenesis(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := rawdb.ReadHeader(db, hash, 0)
+	cachedb := state.NewDatabase(db)
+	statedb, err := state.New(header.Root, cachedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb.SetState(config.SysName, proofSlot, proofValue)
+	batch := db.NewBatch()
+	if header.Root, err = statedb.Commit(batch, hash, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := cachedb.TrieDB().Commit(header.Root, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if statedb, err = state.New(header.Root, cachedb); err != nil {
+		t.Fatal(err)
+	}
+	return &proofBackend{config: config, state: statedb, header: header}
+}
+
+func (b *proofBackend) ChainConfig() *params.ChainConfig { return b.config }
+
+func (b *proofBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	return b.state, b.header, nil
+}
+
+func (b *proofBackend) GetAccountManager() (*accountmanager.AccountManager, error) {
+	return accountmanager.NewAccountManager(b.state)
+}
+
+func (b *proofBackend) proof(t *testing.T, name common.Name) *rpcapi.AccountProof {
+	proof, err := rpcapi.NewAccountAPI(b).GetProof(context.Background(), name, []common.Hash{proofSlot}, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proof
+}
+
+func TestVerifyAccountProof(t *testing.T) {
+	b := newProofBackend(t)
+	root, acctManager, sys := b.header.Root, common.Name(b.config.AccountName), common.Name(b.config.SysName)
+
+	proof := b.proof(t, sys)
+	if len(proof.Balances) == 0 || len(proof.StorageProof) != 1 || proof.StorageProof[0].Value != proofValue {
+		t.Fatalf("proof of %v: have balances %v storage %v", sys, proof.Balances, proof.StorageProof)
+	}
+	acct, err := sdk.VerifyAccountProof(root, acctManager, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct == nil || acct.GetName() != sys {
+		t.Fatalf("proven account: have %v, want %v", acct, sys)
+	}
+	if _, err := sdk.VerifyAccountProof(common.BytesToHash([]byte("bad root")), acctManager, proof); err == nil {
+		t.Error("proof verified against another root")
+	}
+
+	proof = b.proof(t, sys)
+	proof.Balances[0].Balance = new(big.Int).Add(proof.Balances[0].Balance, big.NewInt(1))
+	if _, err := sdk.VerifyAccountProof(root, acctManager, proof); err == nil {
+		t.Error("tampered balance verified")
+	}
+
+	proof = b.proof(t, sys)
+	proof.StorageProof[0].Value = common.BytesToHash([]byte("other value"))
+	if _, err := sdk.VerifyAccountProof(root, acctManager, proof); err == nil {
+		t.Error("tampered storage value verified")
+	}
+
+	missing := common.Name("a123456789none")
+	proof = b.proof(t, missing)
+	if acct, err := sdk.VerifyAccountProof(root, acctManager, proof); err != nil || acct != nil {
+		t.Fatalf("proof of %v: have account %v err %v, want not exist", missing, acct, err)
+	}
+	proof.AccountID = 1
+	if _, err := sdk.VerifyAccountProof(root, acctManager, proof); err == nil {
+		t.Error("account proven not exist verified with an id")
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package mocktest tests the sdk against mocks of a node, its tests run
+// without a node.
+package mocktest
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/rpcapi/filters"
+	"github.com/unichainplatform/unichain/sdk"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/abi"
+)
+
+// MockChain serves headers, receipts and subscriptions to them over a
+// websocket, a subscription made is passed on subs.
+type MockChain struct {
+	mu       sync.Mutex
+	headers  []*types.Header
+	receipts [][]*types.Receipt
+	subs     chan *testSub
+}
+
+type testSub struct {
+	notifier *rpc.Notifier
+	id       rpc.ID
+}
+
+func (s *testSub) notify(t *testing.T, data interface{}) {
+	if err := s.notifier.Notify(s.id, data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// extend appends a block with the logs to the chain.
+func (c *MockChain) extend(logs ...*types.Log) *types.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	number := uint64(len(c.headers))
+	header := &types.Header{Number: new(big.Int).SetUint64(number), Time: big.NewInt(0), Difficulty: big.NewInt(0)}
+	if number > 0 {
+		header.ParentHash = c.headers[number-1].Hash()
+	}
+	for i, log := range logs {
+		log.BlockNumber, log.Index = number, uint(i)
+	}
+	c.headers = append(c.headers, header)
+	c.receipts = append(c.receipts, []*types.Receipt{{Logs: logs}})
+	return header
+}
+
+func (c *MockChain) GetHeaderByNumber(number rpc.BlockNumber) *types.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if number == rpc.LatestBlockNumber {
+		return c.headers[len(c.headers)-1]
+	}
+	if int(number) >= len(c.headers) {
+		return nil
+	}
+	return c.headers[number]
+}
+
+func (c *MockChain) GetBlockAndResultByNumber(number rpc.BlockNumber) map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if int(number) >= len(c.receipts) {
+		return nil
+	}
+	return map[string]interface{}{"receipts": c.receipts[number]}
+}
+
+func (c *MockChain) subscribe(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	// the subscription is notified after it is returned to the client
+	go func() { c.subs <- &testSub{notifier, sub.ID} }()
+	return sub, nil
+}
+
+func (c *MockChain) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return c.subscribe(ctx)
+}
+
+func (c *MockChain) Logs(ctx context.Context, crit filters.FilterCriteria) (*rpc.Subscription, error) {
+	return c.subscribe(ctx)
+}
+
+// dropListener records its connections so they can be dropped.
+type dropListener struct {
+	net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func (l *dropListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.mu.Lock()
+		l.conns = append(l.conns, conn)
+		l.mu.Unlock()
+	}
+	return conn, err
+}
+
+func (l *dropListener) drop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, conn := range l.conns {
+		conn.Close()
+	}
+	l.conns = nil
+}
+
+// newMockChain serves a chain of the genesis block over a websocket.
+func newMockChain(t *testing.T) (*MockChain, *sdk.API, *dropListener, func()) {
+	chain := &MockChain{subs: make(chan *testSub, 4)}
+	chain.extend()
+	server := rpc.NewServer()
+	if err := server.RegisterName("uni", chain); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewUnstartedServer(server.WebsocketHandler([]string{"*"}))
+	listener := &dropListener{Listener: httpServer.Listener}
+	httpServer.Listener = listener
+	httpServer.Start()
+
+	api := sdk.NewWSAPI("ws://" + strings.TrimPrefix(httpServer.URL, "http://"))
+	return chain, api, listener, func() {
+		listener.drop()
+		httpServer.Close()
+		server.Stop()
+	}
+}
+
+// waitSub returns the next subscription made, a subscription lost is made
+// again after the resubscribe interval of the sdk.
+func (c *MockChain) waitSub(t *testing.T) *testSub {
+	select {
+	case sub := <-c.subs:
+		return sub
+	case <-time.After(10 * time.Second):
+		t.Fatal("no subscription made")
+	}
+	return nil
+}
+
+func TestSubscribeNewHeadsResume(t *testing.T) {
+	chain, api, listener, stop := newMockChain(t)
+	defer stop()
+	chain.extend()
+
+	headers := make(chan *types.Header, 16)
+	sub, err := api.SubscribeNewHeads(headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	first := chain.waitSub(t)
+	// the head when subscribing is not sent
+	first.notify(t, chain.GetHeaderByNumber(1))
+	first.notify(t, chain.extend())
+
+	// headers 3 to 5 are missed, the connection drops before they are sent
+	chain.extend()
+	chain.extend()
+	head := chain.extend()
+	listener.drop()
+	// headers of the new subscription sent while resuming are sent once
+	second := chain.waitSub(t)
+	second.notify(t, head)
+	second.notify(t, chain.extend())
+
+	for number := uint64(2); number <= 6; number++ {
+		select {
+		case header := <-headers:
+			if header.Number.Uint64() != number || header.Hash() != chain.GetHeaderByNumber(rpc.BlockNumber(number)).Hash() {
+				t.Fatalf("header: have %v, want %v", header.Number, number)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("header %v not sent", number)
+		}
+	}
+	select {
+	case header := <-headers:
+		t.Fatalf("header %v sent twice", header.Number)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSubscribeLogsResume(t *testing.T) {
+	chain, api, listener, stop := newMockChain(t)
+	defer stop()
+
+	logs := make(chan types.Log, 16)
+	sub, err := api.SubscribeLogs(filters.FilterCriteria{}, logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	first := chain.waitSub(t)
+
+	newLog := func(data string) *types.Log { return &types.Log{Name: "a123456789cntr", Data: []byte(data)} }
+	// only the first log of block 1 is notified before the connection drops
+	block1 := []*types.Log{newLog("1a"), newLog("1b")}
+	chain.extend(block1...)
+	first.notify(t, block1[0])
+	waitLog := func(want string) {
+		select {
+		case log := <-logs:
+			if string(log.Data) != want {
+				t.Fatalf("log: have %s, want %s", log.Data, want)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("log %s not sent", want)
+		}
+	}
+	waitLog("1a")
+
+	block2 := []*types.Log{newLog("2a")}
+	chain.extend(block2...)
+	listener.drop()
+	second := chain.waitSub(t)
+	// logs of the new subscription sent while resuming are sent once
+	second.notify(t, block2[0])
+	block3 := []*types.Log{newLog("3a")}
+	chain.extend(block3...)
+	second.notify(t, block3[0])
+
+	for _, want := range []string{"1b", "2a", "3a"} {
+		waitLog(want)
+	}
+	select {
+	case log := <-logs:
+		t.Fatalf("log %s sent twice", log.Data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchEvents(t *testing.T) {
+	chain, api, _, stop := newMockChain(t)
+	defer stop()
+
+	contractABI, err := abi.JSON(strings.NewReader(`[{"type":"event","name":"Deposit","inputs":[
+		{"name":"from_id","type":"uint256","indexed":true},
+		{"name":"amount","type":"uint256","indexed":false},
+		{"name":"memo","type":"string","indexed":false}]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contract := sdk.NewBoundContract("a123456789cntr", contractABI, api)
+	type Deposit struct {
+		FromId *big.Int
+		Amount *big.Int
+		Memo   string
+		Raw    types.Log
+	}
+	events := make(chan *Deposit, 1)
+	sub, err := contract.WatchEvents(nil, "Deposit", events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	ev := contractABI.Events["Deposit"]
+	data, err := ev.Inputs.NonIndexed().Pack(big.NewInt(100), "memo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := &types.Log{
+		Name:   contract.Name(),
+		Topics: []common.Hash{ev.Id(), common.BigToHash(big.NewInt(7))},
+		Data:   data,
+	}
+	chain.extend(log)
+	chain.waitSub(t).notify(t, log)
+
+	select {
+	case deposit := <-events:
+		if deposit.FromId.Int64() != 7 || deposit.Amount.Int64() != 100 || deposit.Memo != "memo" || deposit.Raw.BlockNumber != 1 {
+			t.Fatalf("event: have %+v", deposit)
+		}
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not sent")
+	}
+}
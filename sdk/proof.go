@@ -0,0 +1,92 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/unichainplatform/unichain/accountmanager"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/rpcapi"
+	"github.com/unichainplatform/unichain/state"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+func fromProofBytes(proof []hexutil.Bytes) [][]byte {
+	nodes := make([][]byte, len(proof))
+	for i, node := range proof {
+		nodes[i] = node
+	}
+	return nodes
+}
+
+// VerifyAccountProof checks proof, as returned by account_getProof, against the
+// state root of a trusted header and returns the proven account. The account
+// is nil if the proof shows it does not exist. acctManager is the AccountName
+// of the chain config.
+func VerifyAccountProof(root common.Hash, acctManager common.Name, proof *rpcapi.AccountProof) (*accountmanager.Account, error) {
+	idValue, err := state.VerifyProof(root, accountmanager.AccountNameIDKey(acctManager, proof.AccountName), fromProofBytes(proof.IDProof))
+	if err != nil {
+		return nil, err
+	}
+	if len(idValue) == 0 {
+		if proof.AccountID != 0 {
+			return nil, fmt.Errorf("account %v proven not exist", proof.AccountName)
+		}
+		return nil, nil
+	}
+	var accountID uint64
+	if err := rlp.DecodeBytes(idValue, &accountID); err != nil {
+		return nil, err
+	}
+	if accountID != proof.AccountID {
+		return nil, fmt.Errorf("account id mismatch, proven %v, reported %v", accountID, proof.AccountID)
+	}
+
+	acctValue, err := state.VerifyProof(root, accountmanager.AccountInfoKey(acctManager, accountID), fromProofBytes(proof.AccountProof))
+	if err != nil {
+		return nil, err
+	}
+	if len(acctValue) == 0 {
+		return nil, fmt.Errorf("account %v object proven not exist", proof.AccountName)
+	}
+	var acct accountmanager.Account
+	if err := rlp.DecodeBytes(acctValue, &acct); err != nil {
+		return nil, err
+	}
+	if acct.GetName() != proof.AccountName {
+		return nil, fmt.Errorf("account name mismatch, proven %v, reported %v", acct.GetName(), proof.AccountName)
+	}
+	for _, balance := range proof.Balances {
+		proven, err := acct.GetBalanceByID(balance.AssetID)
+		if err != nil || proven.Cmp(balance.Balance) != 0 {
+			return nil, fmt.Errorf("asset %v balance mismatch, proven %v, reported %v", balance.AssetID, proven, balance.Balance)
+		}
+	}
+
+	for _, storage := range proof.StorageProof {
+		value, err := state.VerifyProof(root, state.StorageKey(proof.AccountName.String(), storage.Key), fromProofBytes(storage.Proof))
+		if err != nil {
+			return nil, err
+		}
+		if common.BytesToHash(value) != storage.Value {
+			return nil, fmt.Errorf("storage %v mismatch, proven %x, reported %v", storage.Key.String(), value, storage.Value.String())
+		}
+	}
+	return &acct, nil
+}
@@ -22,14 +22,17 @@ import (
 
 	"github.com/unichainplatform/unichain/common"
 	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/keystore"
 
 	"github.com/unichainplatform/unichain/params"
 	"github.com/unichainplatform/unichain/sdk"
 )
 
 var (
-	api      *sdk.API
-	chainCfg *params.ChainConfig
+	api        *sdk.API
+	chainCfg   *params.ChainConfig
+	ks         *keystore.KeyStore
+	passphrase string
 )
 
 // TTX
@@ -45,6 +48,7 @@ type TTX struct {
 	Succeed bool        `json:"succeed,omitempty"`
 	Contain string      `json:"contain,omitempty"`
 	Priv    string      `json:"priv,omitempty"`
+	PubKey  string      `json:"pubkey,omitempty"`
 	Childs  []*TTX      `json:"childs,omitempty"`
 }
 
@@ -69,13 +73,25 @@ func Init() {
 	log.Root().SetHandler(glogger)
 }
 
-func runTx(api *sdk.API, tx *TTX, file string) error {
+// txAccount returns the sender of tx, its key is taken from the keystore if
+// tx names a public key instead of a hex private key.
+func txAccount(api *sdk.API, tx *TTX) (*sdk.Account, error) {
+	if tx.PubKey != "" {
+		return sdk.NewAccountFromKeystore(api, common.StrToName(tx.From), ks, common.HexToPubKey(tx.PubKey), passphrase, chainCfg.SysTokenID, math.MaxUint64, true, chainCfg.ChainID)
+	}
 	priv, err := crypto.HexToECDSA(tx.Priv)
 	if err != nil {
-		log.Error(file, "hex priv err", err)
+		return nil, err
+	}
+	return sdk.NewAccount(api, common.StrToName(tx.From), priv, chainCfg.SysTokenID, math.MaxUint64, true, chainCfg.ChainID), nil
+}
+
+func runTx(api *sdk.API, tx *TTX, file string) error {
+	act, err := txAccount(api, tx)
+	if err != nil {
+		log.Error(file, "account key err", err)
 		return err
 	}
-	act := sdk.NewAccount(api, common.StrToName(tx.From), priv, chainCfg.SysTokenID, math.MaxUint64, true, chainCfg.ChainID)
 
 	var hash common.Hash
 	switch strings.ToLower(tx.Type) {
@@ -237,8 +253,11 @@ func runTx(api *sdk.API, tx *TTX, file string) error {
 func main() {
 	_rpchost := flag.String("u", "http://127.0.0.1:8545", "RPC地址")
 	_dirfile := flag.String("d", "./testcase", "目录名/文件名")
+	_keystore := flag.String("k", "./keystore", "keystore目录")
+	flag.StringVar(&passphrase, "p", "", "keystore密码")
 	flag.Parse()
 	api = sdk.NewAPI(*_rpchost)
+	ks = keystore.NewKeyStore(*_keystore, keystore.StandardScryptN, keystore.StandardScryptP)
 	Init()
 	f, _ := os.Stat(*_dirfile)
 	if f.IsDir() {
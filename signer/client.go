@@ -0,0 +1,85 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package signer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+// signTimeout bounds a signing request, a block has to be sealed within its
+// time slot.
+const signTimeout = 2 * time.Second
+
+var errBadSignature = errors.New("remote signer returned an invalid signature")
+
+// Client is the node side of the remote signer protocol.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects to the signer at rawurl, an IPC path or an HTTP url.
+func Dial(rawurl string) (*Client, error) {
+	c, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{c}, nil
+}
+
+// Close closes the connection to the signer.
+func (c *Client) Close() {
+	c.c.Close()
+}
+
+// PubKeys returns the public keys of the signer.
+func (c *Client) PubKeys() ([]common.PubKey, error) {
+	var pubKeys []common.PubKey
+	err := c.c.Call(&pubKeys, "signer_pubKeys")
+	return pubKeys, err
+}
+
+// SignHeader requests the signature of the seal hash of header on the chain of
+// chainID by pubKey, the signature is checked to be made by pubKey.
+func (c *Client) SignHeader(header *types.Header, chainID *big.Int, pubKey common.PubKey) ([]byte, error) {
+	encoded, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), signTimeout)
+	defer cancel()
+	var sig hexutil.Bytes
+	if err := c.c.CallContext(ctx, &sig, "signer_signHeader", hexutil.Bytes(encoded), chainID, pubKey); err != nil {
+		return nil, err
+	}
+	pub, err := crypto.Ecrecover(dpos.SealHash(header, chainID).Bytes(), sig)
+	if err != nil || !bytes.Equal(pub, pubKey.Bytes()) {
+		return nil, errBadSignature
+	}
+	return sig, nil
+}
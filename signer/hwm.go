@@ -0,0 +1,108 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package signer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/unichainplatform/unichain/common"
+)
+
+// mark is the last header signed with a key.
+type mark struct {
+	Number uint64      `json:"number"`
+	Time   uint64      `json:"time"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// highWaterMark keeps the last signed header of every key in a json file, it
+// is written before a signature is returned so that a restarted signer never
+// signs at or below its slot.
+type highWaterMark struct {
+	file  string
+	marks map[common.PubKey]mark
+}
+
+func loadHighWaterMark(file string) (*highWaterMark, error) {
+	hwm := &highWaterMark{file: file, marks: make(map[common.PubKey]mark)}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return hwm, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &hwm.marks); err != nil {
+		return nil, err
+	}
+	return hwm, nil
+}
+
+// check returns ErrDoubleSign unless the slot of m is above the mark of pubKey
+// or m is the very header signed last. The number is not checked, a producer
+// may sign a lower header at a later slot after switching to another fork.
+func (hwm *highWaterMark) check(pubKey common.PubKey, m mark) error {
+	last, ok := hwm.marks[pubKey]
+	if !ok || last == m {
+		return nil
+	}
+	if m.Time <= last.Time {
+		return ErrDoubleSign
+	}
+	return nil
+}
+
+func (hwm *highWaterMark) update(pubKey common.PubKey, m mark) error {
+	marks := make(map[common.PubKey]mark, len(hwm.marks)+1)
+	for k, v := range hwm.marks {
+		marks[k] = v
+	}
+	marks[pubKey] = m
+	if err := writeMarks(hwm.file, marks); err != nil {
+		return err
+	}
+	hwm.marks = marks
+	return nil
+}
+
+func writeMarks(file string, marks map[common.PubKey]mark) error {
+	data, err := json.MarshalIndent(marks, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	f.Close()
+	return os.Rename(f.Name(), file)
+}
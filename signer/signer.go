@@ -0,0 +1,154 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package signer implements a remote signer for block production keys, so that
+// the keys of a producer can be kept off the networked node.
+//
+// The node talks to the signer over JSON-RPC on an IPC socket or HTTP with
+// the methods of the "signer" namespace:
+//
+//	signer_pubKeys() -> ["0x04..."]
+//		returns the public keys the signer holds.
+//	signer_signHeader(header, chainID, pubKey) -> "0x..."
+//		header is the hex of the rlp encoded block header to seal, the
+//		result is the 65 byte signature of its dpos seal hash on the
+//		chain of chainID by pubKey.
+//
+// The signer refuses to sign a header unless its time slot is above the one
+// of the last header signed with the same key, re-signing the very same header
+// is allowed. A lower number at a later slot is signed, as a producer switching
+// forks does. The last signed header of every key is written to disk before
+// the signature is returned.
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/rlp"
+)
+
+var (
+	// ErrUnknownKey is returned when the signer does not hold the key.
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrDoubleSign is returned when a header is at or below the high-water
+	// mark of the key.
+	ErrDoubleSign = errors.New("refusing to sign header at or below high-water mark")
+)
+
+// Signer signs block headers with the keys it holds.
+type Signer struct {
+	mu   sync.Mutex
+	keys map[common.PubKey]*ecdsa.PrivateKey
+	hwm  *highWaterMark
+}
+
+// New creates a signer of keys whose high-water marks are kept in hwmFile.
+func New(keys []*ecdsa.PrivateKey, hwmFile string) (*Signer, error) {
+	hwm, err := loadHighWaterMark(hwmFile)
+	if err != nil {
+		return nil, err
+	}
+	s := &Signer{
+		keys: make(map[common.PubKey]*ecdsa.PrivateKey, len(keys)),
+		hwm:  hwm,
+	}
+	for _, key := range keys {
+		s.keys[common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))] = key
+	}
+	return s, nil
+}
+
+// PubKeys returns the public keys of the signer.
+func (s *Signer) PubKeys() []common.PubKey {
+	pubKeys := make([]common.PubKey, 0, len(s.keys))
+	for pubKey := range s.keys {
+		pubKeys = append(pubKeys, pubKey)
+	}
+	sort.Slice(pubKeys, func(i, j int) bool { return pubKeys[i].Compare(pubKeys[j]) < 0 })
+	return pubKeys
+}
+
+// SignHeader signs the seal hash of header on the chain of chainID with the
+// key of pubKey.
+func (s *Signer) SignHeader(header *types.Header, chainID *big.Int, pubKey common.PubKey) ([]byte, error) {
+	key, ok := s.keys[pubKey]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if header.Number == nil || header.Time == nil {
+		return nil, errors.New("header without number or time")
+	}
+	if chainID == nil {
+		return nil, errors.New("missing chain id")
+	}
+	if len(header.Extra) < dpos.ExtraSeal {
+		return nil, errors.New("header extra-data without seal")
+	}
+
+	hash := dpos.SealHash(header, chainID)
+	m := mark{Number: header.Number.Uint64(), Time: header.Time.Uint64(), Hash: hash}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.hwm.check(pubKey, m); err != nil {
+		return nil, err
+	}
+	if err := s.hwm.update(pubKey, m); err != nil {
+		return nil, err
+	}
+	return crypto.Sign(hash.Bytes(), key)
+}
+
+// APIs returns the signer RPC API.
+func (s *Signer) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "signer",
+			Version:   "1.0",
+			Service:   &API{s},
+		},
+	}
+}
+
+// API exposes the signer over RPC.
+type API struct {
+	s *Signer
+}
+
+// PubKeys returns the public keys of the signer.
+func (api *API) PubKeys() []common.PubKey {
+	return api.s.PubKeys()
+}
+
+// SignHeader signs the rlp encoded header for the chain of chainID with the
+// key of pubKey.
+func (api *API) SignHeader(encodedHeader hexutil.Bytes, chainID *big.Int, pubKey common.PubKey) (hexutil.Bytes, error) {
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(encodedHeader, header); err != nil {
+		return nil, err
+	}
+	return api.s.SignHeader(header, chainID, pubKey)
+}
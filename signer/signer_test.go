@@ -0,0 +1,156 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package signer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	"github.com/unichainplatform/unichain/crypto"
+	"github.com/unichainplatform/unichain/rpc"
+	"github.com/unichainplatform/unichain/types"
+)
+
+var testChainID = big.NewInt(1)
+
+func testHeader(number, time uint64, root byte) *types.Header {
+	return &types.Header{
+		Coinbase:   common.StrToName("unichain.founder"),
+		Root:       common.Hash{root},
+		Difficulty: big.NewInt(1),
+		Number:     new(big.Int).SetUint64(number),
+		Time:       new(big.Int).SetUint64(time),
+		Extra:      make([]byte, 65),
+	}
+}
+
+func TestSignHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signer-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "hwm.json")
+
+	key, _ := crypto.GenerateKey()
+	pubKey := common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))
+	s, err := New([]*ecdsa.PrivateKey{key}, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := crypto.GenerateKey()
+	if _, err := s.SignHeader(testHeader(1, 3, 0), testChainID, common.BytesToPubKey(crypto.FromECDSAPub(&other.PublicKey))); err != ErrUnknownKey {
+		t.Fatalf("sign with unknown key: have %v, want %v", err, ErrUnknownKey)
+	}
+
+	header := testHeader(10, 30, 1)
+	short := types.CopyHeader(header)
+	short.Extra = short.Extra[:dpos.ExtraSeal-1]
+	if _, err := s.SignHeader(short, testChainID, pubKey); err == nil {
+		t.Fatal("sign header without seal succeeded")
+	}
+	sig, err := s.SignHeader(header, testChainID, pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub, err := crypto.Ecrecover(dpos.SealHash(header, testChainID).Bytes(), sig); err != nil || !bytes.Equal(pub, pubKey.Bytes()) {
+		t.Fatalf("signature of wrong key: %v", err)
+	}
+	if _, err := s.SignHeader(header, testChainID, pubKey); err != nil {
+		t.Fatalf("re-sign same header: %v", err)
+	}
+
+	tests := []struct {
+		header *types.Header
+		err    error
+	}{
+		{testHeader(10, 30, 2), ErrDoubleSign}, // same height and slot
+		{testHeader(11, 30, 1), ErrDoubleSign}, // same slot
+		{testHeader(11, 27, 1), ErrDoubleSign}, // earlier slot
+		{testHeader(10, 33, 1), nil},           // same height at a later slot
+		{testHeader(9, 36, 1), nil},            // lower height at a later slot
+		{testHeader(12, 36, 1), ErrDoubleSign}, // same slot as the lower height
+	}
+	for i, test := range tests {
+		if _, err := s.SignHeader(test.header, testChainID, pubKey); err != test.err {
+			t.Fatalf("test %d: have %v, want %v", i, err, test.err)
+		}
+	}
+
+	// the mark survives a restart
+	s, err = New([]*ecdsa.PrivateKey{key}, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SignHeader(testHeader(9, 36, 2), testChainID, pubKey); err != ErrDoubleSign {
+		t.Fatalf("sign after restart: have %v, want %v", err, ErrDoubleSign)
+	}
+	if _, err := s.SignHeader(testHeader(10, 39, 1), testChainID, pubKey); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signer-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, _ := crypto.GenerateKey()
+	pubKey := common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))
+	s, err := New([]*ecdsa.PrivateKey{key}, filepath.Join(dir, "hwm.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := filepath.Join(dir, "signer.ipc")
+	listener, handler, err := rpc.StartIPCEndpoint(endpoint, s.APIs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Stop()
+	defer listener.Close()
+
+	client, err := Dial(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	pubKeys, err := client.PubKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pubKeys) != 1 || pubKeys[0] != pubKey {
+		t.Fatalf("pubkeys mismatch: have %v, want %v", pubKeys, pubKey)
+	}
+	header := testHeader(1, 3, 1)
+	if _, err := client.SignHeader(header, testChainID, pubKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SignHeader(testHeader(1, 3, 2), testChainID, pubKey); err == nil || err.Error() != ErrDoubleSign.Error() {
+		t.Fatalf("double sign: have %v, want %v", err, ErrDoubleSign)
+	}
+}
@@ -122,6 +122,21 @@ func (sn *SnapshotManager) GetPrevSnapshotTime(time uint64) (uint64, error) {
 	return blockInfo.Timestamp, nil
 }
 
+// GetSnapshotBlock get the block whose state is the snapshot of time
+func (sn *SnapshotManager) GetSnapshotBlock(time uint64) (BlockInfo, error) {
+	key := snapshotTime + strconv.FormatUint(time, 10)
+	blockInfoEnc, err := sn.stateDB.Get(snapshotManagerName, key)
+	if err != nil {
+		return BlockInfo{}, fmt.Errorf("Not snapshot info, error = %v", err)
+	}
+
+	var blockInfo BlockInfo
+	if err = rlp.DecodeBytes(blockInfoEnc, &blockInfo); err != nil {
+		return BlockInfo{}, fmt.Errorf("Not snapshot info, error = %v", err)
+	}
+	return blockInfo, nil
+}
+
 func (sn *SnapshotManager) GetSnapshotMsg(account string, key string, time uint64) ([]byte, error) {
 	if time == 0 {
 		return nil, fmt.Errorf("Not snapshot info, time = %v", time)
@@ -17,6 +17,7 @@
 package state
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/unichainplatform/unichain/common"
@@ -30,6 +31,7 @@ var MaxTrieCacheGen = uint16(120)
 type Database interface {
 	GetDB() fdb.Database
 	OpenTrie(root common.Hash) (Trie, error)
+	CopyTrie(Trie) Trie
 	TrieDB() *trie.Database
 	Lock()
 	UnLock()
@@ -78,6 +80,16 @@ func (db *cachingDB) OpenTrie(root common.Hash) (Trie, error) {
 	return cachedTrie{tr, db}, nil
 }
 
+// CopyTrie returns an independent copy of the given trie.
+func (db *cachingDB) CopyTrie(t Trie) Trie {
+	switch t := t.(type) {
+	case cachedTrie:
+		return cachedTrie{t.SecureTrie.Copy(), db}
+	default:
+		panic(fmt.Errorf("unknown trie type %T", t))
+	}
+}
+
 // TrieDB retrieves any intermediate trie-node caching layer.
 func (db *cachingDB) TrieDB() *trie.Database {
 	return db.triedb
@@ -0,0 +1,74 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+	trie "github.com/unichainplatform/unichain/state/mtp"
+)
+
+// AccountDataKey returns the trie key of data stored by Put under account.
+func AccountDataKey(account string, key string) []byte {
+	return []byte(acctDataPrefix + linkSymbol + account + linkSymbol + key)
+}
+
+// StorageKey returns the trie key of a contract storage slot.
+func StorageKey(account string, key common.Hash) []byte {
+	return []byte(statePrefix + linkSymbol + account + linkSymbol + key.String())
+}
+
+// proofList collects the encoded nodes of a proof, from the root down.
+type proofList [][]byte
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// proofSet indexes the nodes of a proof by hash for verification.
+type proofSet map[string][]byte
+
+func (s proofSet) Get(key []byte) ([]byte, error) {
+	return s[string(key)], nil
+}
+
+func (s proofSet) Has(key []byte) (bool, error) {
+	_, ok := s[string(key)]
+	return ok, nil
+}
+
+// GetProof returns the merkle proof of key against the state root the StateDB
+// was opened at. Changes not committed yet are not covered.
+func (s *StateDB) GetProof(key []byte) ([][]byte, error) {
+	var proof proofList
+	if err := s.trie.Prove(crypto.Keccak256(key), 0, &proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// VerifyProof checks proof against root and returns the value stored at key,
+// nil if the proof shows the key is absent.
+func VerifyProof(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	set := make(proofSet, len(proof))
+	for _, node := range proof {
+		set[string(crypto.Keccak256(node))] = node
+	}
+	value, _, err := trie.VerifyProof(root, crypto.Keccak256(key), set)
+	return value, err
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/rawdb"
+)
+
+func TestGetProof(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	batch := db.NewBatch()
+	cachedb := NewDatabase(db)
+
+	state, err := New(common.Hash{}, cachedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		state.Put("proof", fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", i)))
+	}
+	slot, slotValue := common.BytesToHash([]byte("slot")), common.BytesToHash([]byte("slot value"))
+	state.SetState("proof", slot, slotValue)
+	root, err := state.Commit(batch, common.Hash{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := state.db.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	batch.Write()
+
+	state, err = New(root, cachedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := AccountDataKey("proof", "key7")
+	proof, err := state.GetProof(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := VerifyProof(root, key, proof); err != nil || !bytes.Equal(value, []byte("value7")) {
+		t.Fatalf("verify proof: have %s %v, want value7", value, err)
+	}
+	if _, err := VerifyProof(common.BytesToHash([]byte("bad root")), key, proof); err == nil {
+		t.Fatalf("proof verified against wrong root")
+	}
+
+	proof, err = state.GetProof(StorageKey("proof", slot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := VerifyProof(root, StorageKey("proof", slot), proof); err != nil || !bytes.Equal(value, slotValue[:]) {
+		t.Fatalf("verify storage proof: have %x %v, want %x", value, err, slotValue)
+	}
+
+	missing := AccountDataKey("proof", "missing")
+	proof, err = state.GetProof(missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := VerifyProof(root, missing, proof); err != nil || value != nil {
+		t.Fatalf("verify absence: have %x %v", value, err)
+	}
+}
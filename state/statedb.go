@@ -65,9 +65,17 @@ type StateDB struct {
 
 	stateTrace bool // replay transaction, true is replayed , false is not replayed
 
+	accessTracer AccessTracer // notified of every key read or written, may be nil
+
 	lock sync.Mutex
 }
 
+// AccessTracer is notified of every key read or written through a StateDB.
+type AccessTracer interface {
+	CaptureGet(key string, value []byte)
+	CapturePut(key string, prevalue, value []byte)
+}
+
 //New func generate a statedb object
 //parentHash: block's parent hash, db: cachedb
 func New(root common.Hash, db Database) (*StateDB, error) {
@@ -204,10 +212,21 @@ func (s *StateDB) put(key string, value []byte) {
 	s.journal.append(stateChange{key: &key,
 		prevalue: oldValue})
 	s.set(key, value)
+	if s.accessTracer != nil {
+		s.accessTracer.CapturePut(key, oldValue, value)
+	}
 }
 
 //get return nil when key not exsit
 func (s *StateDB) get(key string) ([]byte, error) {
+	value, err := s.load(key)
+	if s.accessTracer != nil {
+		s.accessTracer.CaptureGet(key, value)
+	}
+	return value, err
+}
+
+func (s *StateDB) load(key string) ([]byte, error) {
 	if value, exsit := s.writeSet[key]; exsit {
 		return common.CopyBytes(value), nil
 	}
@@ -232,6 +251,12 @@ func (s *StateDB) get(key string) ([]byte, error) {
 	return common.CopyBytes(value), nil
 }
 
+// SetAccessTracer sets the tracer notified of every key read or written,
+// nil removes it.
+func (s *StateDB) SetAccessTracer(tracer AccessTracer) {
+	s.accessTracer = tracer
+}
+
 func (s *StateDB) Database() Database {
 	return s.db
 }
@@ -242,7 +267,7 @@ func (s *StateDB) Copy() *StateDB {
 
 	state := &StateDB{
 		db:        s.db,
-		trie:      s.trie,
+		trie:      s.db.CopyTrie(s.trie),
 		readSet:   make(map[string][]byte, len(s.writeSet)),
 		writeSet:  make(map[string][]byte, len(s.writeSet)),
 		dirtySet:  make(map[string]struct{}, len(s.dirtySet)),
@@ -75,7 +75,7 @@ func IntrinsicGas(accountDB *accountmanager.AccountManager, action *types.Action
 
 	var gas uint64
 
-	if action.Type() == types.CreateContract || action.Type() == types.CreateAccount {
+	if action.Type() == types.CreateContract || action.Type() == types.Create2Contract || action.Type() == types.CreateAccount {
 		gas += gasTable.ActionGasCreation
 	} else if action.Type() == types.IssueAsset {
 		gas += gasTable.ActionGasIssueAsset
@@ -40,6 +40,8 @@ const (
 	CallContract ActionType = iota
 	// CreateContract repesents the create contract action.
 	CreateContract
+	// Create2Contract repesents the create contract action to the sub account derived from the salt and the code hash.
+	Create2Contract
 )
 
 const (
@@ -51,6 +53,18 @@ const (
 	DeleteAccount
 	// UpdateAccountAuthor represents the update account author.
 	UpdateAccountAuthor
+	// LockedTransfer represents transfer asset released on a vesting schedule.
+	LockedTransfer
+	// ClaimLocked represents claim the vested locked asset.
+	ClaimLocked
+	// SetAccountRecovery represents set the guardians of account.
+	SetAccountRecovery
+	// ApproveAccountRecovery represents guardian approve account author reset.
+	ApproveAccountRecovery
+	// ExecuteAccountRecovery represents guardian execute approved account author reset.
+	ExecuteAccountRecovery
+	// CancelAccountRecovery represents cancel the pending account author reset.
+	CancelAccountRecovery
 )
 
 const (
@@ -67,6 +81,18 @@ const (
 	// Transfer repesents transfer asset action.
 	Transfer
 	UpdateAssetContract
+	// FreezeAccountAsset repesents asset owner freeze the asset of an account.
+	FreezeAccountAsset
+	// UnfreezeAccountAsset repesents asset owner unfreeze the asset of an account.
+	UnfreezeAccountAsset
+	// PauseAsset repesents asset owner pause all transfers of asset.
+	PauseAsset
+	// UnpauseAsset repesents asset owner unpause all transfers of asset.
+	UnpauseAsset
+	// ApproveAsset repesents allow an account to transfer asset of the sender.
+	ApproveAsset
+	// TransferFromAsset repesents transfer asset of another account out of its allowance.
+	TransferFromAsset
 )
 
 const (
@@ -83,6 +109,16 @@ const (
 
 	// UpdateCandidatePubKey repesents update candidate action.
 	UpdateCandidatePubKey
+	// ReportDoubleSign repesents report the evidence of a candidate signing two headers for the same slot.
+	ReportDoubleSign
+	// ClaimReward repesents claim the rewards shared by candidates action.
+	ClaimReward
+	// CreateProposal repesents propose to change chain parameters action.
+	CreateProposal
+	// VoteProposal repesents vote for or against a proposal action.
+	VoteProposal
+	// ExecuteProposal repesents tally a proposal whose voting has ended action.
+	ExecuteProposal
 )
 
 const (
@@ -220,8 +256,30 @@ func (a *Action) Check(fid uint64, conf *params.ChainConfig) error {
 		if a.data.From != a.data.To {
 			return fmt.Errorf("Receipt should is %v", a.data.From)
 		}
+	case Create2Contract:
+		if fid < params.ForkID5 {
+			return fmt.Errorf("Receipt undefined")
+		}
+		if !a.data.From.IsChildren(a.data.To) {
+			return fmt.Errorf("Receipt should is sub account of %v", a.data.From)
+		}
 	case CallContract:
 	//account
+	case LockedTransfer:
+		fallthrough
+	case ClaimLocked:
+		fallthrough
+	case SetAccountRecovery:
+		fallthrough
+	case ApproveAccountRecovery:
+		fallthrough
+	case ExecuteAccountRecovery:
+		fallthrough
+	case CancelAccountRecovery:
+		if fid < params.ForkID5 {
+			return fmt.Errorf("Receipt undefined")
+		}
+		fallthrough
 	case CreateAccount:
 		fallthrough
 	case UpdateAccount:
@@ -233,6 +291,21 @@ func (a *Action) Check(fid uint64, conf *params.ChainConfig) error {
 			return fmt.Errorf("Receipt should is %v", conf.AccountName)
 		}
 	//asset
+	case ApproveAsset:
+		fallthrough
+	case TransferFromAsset:
+		fallthrough
+	case FreezeAccountAsset:
+		fallthrough
+	case UnfreezeAccountAsset:
+		fallthrough
+	case PauseAsset:
+		fallthrough
+	case UnpauseAsset:
+		if fid < params.ForkID5 {
+			return fmt.Errorf("Receipt undefined")
+		}
+		fallthrough
 	case IncreaseAsset:
 		fallthrough
 	case IssueAsset:
@@ -249,6 +322,19 @@ func (a *Action) Check(fid uint64, conf *params.ChainConfig) error {
 		}
 	case Transfer:
 		//dpos
+	case ReportDoubleSign:
+		fallthrough
+	case ClaimReward:
+		fallthrough
+	case CreateProposal:
+		fallthrough
+	case VoteProposal:
+		fallthrough
+	case ExecuteProposal:
+		if fid < params.ForkID5 {
+			return fmt.Errorf("Receipt undefined")
+		}
+		fallthrough
 	case UpdateCandidatePubKey:
 		if fid < params.ForkID4 {
 			return fmt.Errorf("Receipt undefined")
@@ -283,12 +369,16 @@ func (a *Action) Check(fid uint64, conf *params.ChainConfig) error {
 	switch a.Type() {
 	case CreateContract:
 		fallthrough
+	case Create2Contract:
+		fallthrough
 	case CallContract:
 		fallthrough
 	case Transfer:
 		fallthrough
 	case CreateAccount:
 		fallthrough
+	case LockedTransfer:
+		fallthrough
 	case DestroyAsset:
 		fallthrough
 	case RegCandidate:
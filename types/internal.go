@@ -52,3 +52,38 @@ type AccountTxs struct {
 	IrreversibleBlockHeight uint64              `json:"irreversibleBlockHeight"`
 	EndHeight               uint64              `json:"endHeight"`
 }
+
+type AccountTx struct {
+	Seq         uint64      `json:"seq"`
+	Hash        common.Hash `json:"hash"`
+	Height      uint64      `json:"height"`
+	TxIndex     uint64      `json:"txIndex"`
+	ActionIndex uint64      `json:"actionIndex"`
+}
+
+type AccountTxsPage struct {
+	Txs                     []*AccountTx `json:"txs"`
+	Total                   uint64       `json:"total"`
+	NextCursor              *uint64      `json:"nextCursor"`
+	IndexedHeight           uint64       `json:"indexedHeight"`
+	IrreversibleBlockHeight uint64       `json:"irreversibleBlockHeight"`
+}
+
+type AccountInternalAction struct {
+	Seq            uint64          `json:"seq"`
+	TxHash         common.Hash     `json:"txHash"`
+	BlockHash      common.Hash     `json:"blockHash"`
+	Height         uint64          `json:"height"`
+	TxIndex        uint64          `json:"txIndex"`
+	ActionIndex    uint64          `json:"actionIndex"`
+	InternalIndex  uint64          `json:"internalIndex"`
+	InternalAction *InternalAction `json:"internalAction"`
+}
+
+type InternalActionsPage struct {
+	Actions                 []*AccountInternalAction `json:"actions"`
+	Total                   uint64                   `json:"total"`
+	NextCursor              *uint64                  `json:"nextCursor"`
+	IndexedHeight           uint64                   `json:"indexedHeight"`
+	IrreversibleBlockHeight uint64                   `json:"irreversibleBlockHeight"`
+}
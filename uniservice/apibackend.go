@@ -18,6 +18,7 @@ package uniservice
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/math"
@@ -27,6 +28,7 @@ import (
 	"github.com/unichainplatform/unichain/consensus"
 	"github.com/unichainplatform/unichain/feemanager"
 	"github.com/unichainplatform/unichain/uniservice/gasprice"
+	"github.com/unichainplatform/unichain/keystore"
 	"github.com/unichainplatform/unichain/p2p/enode"
 	"github.com/unichainplatform/unichain/params"
 	"github.com/unichainplatform/unichain/processor"
@@ -82,6 +84,10 @@ func (b *APIBackend) ChainDb() fdb.Database {
 	return b.uniService.chainDb
 }
 
+func (b *APIBackend) KeyManager() *keystore.Manager {
+	return b.uniService.keyManager
+}
+
 func (b *APIBackend) CurrentBlock() *types.Block {
 	return b.uniService.blockchain.CurrentBlock()
 }
@@ -159,6 +165,115 @@ func (b *APIBackend) GetTxsByFilter(ctx context.Context, filterFn func(common.Na
 	return accountTxs
 }
 
+// GetTxsByAccountIndex returns a page of the account transaction index. In
+// ascending order the cursor is the first sequence number returned, in
+// descending order it is the exclusive upper bound, 0 meaning the newest.
+func (b *APIBackend) GetTxsByAccountIndex(ctx context.Context, name common.Name, cursor, limit uint64, desc bool) (*types.AccountTxsPage, error) {
+	head, ok := rawdb.ReadAccountTxIndexHead(b.uniService.chainDb)
+	if !ok {
+		return nil, fmt.Errorf("account tx index not available, enable it with --accountindex and run 'uni chain reindex'")
+	}
+	// the index head and the head block are written in the same batch
+	if current := rawdb.ReadHeaderNumber(b.uniService.chainDb, rawdb.ReadHeadBlockHash(b.uniService.chainDb)); current == nil || *current != head {
+		return nil, fmt.Errorf("account tx index at #%d is not in step with the chain, run 'uni chain reindex' to rebuild it", head)
+	}
+
+	total := rawdb.ReadAccountTxCount(b.uniService.chainDb, name)
+	page := &types.AccountTxsPage{
+		Txs:                     make([]*types.AccountTx, 0),
+		Total:                   total,
+		IndexedHeight:           head,
+		IrreversibleBlockHeight: b.uniService.engine.CalcBFTIrreversible(),
+	}
+
+	var seqs []uint64
+	if desc {
+		if cursor == 0 || cursor > total {
+			cursor = total
+		}
+		for seq := cursor; seq > 0 && uint64(len(seqs)) < limit; seq-- {
+			seqs = append(seqs, seq-1)
+		}
+		if len(seqs) > 0 && seqs[len(seqs)-1] > 0 {
+			next := seqs[len(seqs)-1]
+			page.NextCursor = &next
+		}
+	} else {
+		for seq := cursor; seq < total && uint64(len(seqs)) < limit; seq++ {
+			seqs = append(seqs, seq)
+		}
+		if len(seqs) > 0 && seqs[len(seqs)-1]+1 < total {
+			next := seqs[len(seqs)-1] + 1
+			page.NextCursor = &next
+		}
+	}
+
+	for _, seq := range seqs {
+		entry := rawdb.ReadAccountTxIndexEntry(b.uniService.chainDb, name, seq)
+		if entry == nil {
+			continue
+		}
+		page.Txs = append(page.Txs, &types.AccountTx{
+			Seq:         seq,
+			Hash:        entry.TxHash,
+			Height:      entry.BlockNumber,
+			TxIndex:     entry.TxIndex,
+			ActionIndex: entry.ActionIndex,
+		})
+	}
+	return page, nil
+}
+
+// GetInternalActionsByAccountIndex returns a page of the internal action index
+// of an account in one asset, starting at the cursor sequence number.
+func (b *APIBackend) GetInternalActionsByAccountIndex(ctx context.Context, name common.Name, assetID, cursor, limit uint64) (*types.InternalActionsPage, error) {
+	head, ok := rawdb.ReadInternalActionIndexHead(b.uniService.chainDb)
+	if !ok {
+		return nil, fmt.Errorf("internal action index not available, enable it with --internalindex and run 'uni chain reindex'")
+	}
+
+	total := rawdb.ReadInternalActionCount(b.uniService.chainDb, name, assetID)
+	page := &types.InternalActionsPage{
+		Actions:                 make([]*types.AccountInternalAction, 0),
+		Total:                   total,
+		IndexedHeight:           head,
+		IrreversibleBlockHeight: b.uniService.engine.CalcBFTIrreversible(),
+	}
+
+	detailTxs := make(map[common.Hash][]*types.DetailTx)
+	seq := cursor
+	for ; seq < total && uint64(len(page.Actions)) < limit; seq++ {
+		entry := rawdb.ReadInternalActionIndexEntry(b.uniService.chainDb, name, assetID, seq)
+		if entry == nil {
+			continue
+		}
+		dtxs, ok := detailTxs[entry.BlockHash]
+		if !ok {
+			dtxs = rawdb.ReadDetailTxs(b.uniService.chainDb, entry.BlockHash, entry.BlockNumber)
+			detailTxs[entry.BlockHash] = dtxs
+		}
+		if uint64(len(dtxs)) <= entry.TxIndex || dtxs[entry.TxIndex] == nil ||
+			uint64(len(dtxs[entry.TxIndex].Actions)) <= entry.ActionIndex ||
+			uint64(len(dtxs[entry.TxIndex].Actions[entry.ActionIndex].InternalActions)) <= entry.InternalIndex {
+			continue
+		}
+		page.Actions = append(page.Actions, &types.AccountInternalAction{
+			Seq:            seq,
+			TxHash:         entry.TxHash,
+			BlockHash:      entry.BlockHash,
+			Height:         entry.BlockNumber,
+			TxIndex:        entry.TxIndex,
+			ActionIndex:    entry.ActionIndex,
+			InternalIndex:  entry.InternalIndex,
+			InternalAction: dtxs[entry.TxIndex].Actions[entry.ActionIndex].InternalActions[entry.InternalIndex],
+		})
+	}
+	if seq < total {
+		page.NextCursor = &seq
+	}
+	return page, nil
+}
+
 func (b *APIBackend) GetDetailTxByFilter(ctx context.Context, filterFn func(common.Name) bool, blockNr, lookbackNum uint64) []*types.DetailTx {
 	var lastnum int64
 	if lookbackNum > blockNr {
@@ -246,6 +361,76 @@ func (b *APIBackend) GetEVM(ctx context.Context, account *accountmanager.Account
 	return vm.NewEVM(context, account, state, b.ChainConfig(), vmCfg), vmError, nil
 }
 
+// ReplayBlock re-executes the txs of a block on the state of its parent the
+// same way block processing does, stopping after the tx at index end. The vm
+// config of every tx is returned by vmCfg.
+func (b *APIBackend) ReplayBlock(ctx context.Context, block *types.Block, end int, vmCfg func(index int) vm.Config) ([]*types.Receipt, error) {
+	bc := b.uniService.blockchain
+	if block.NumberU64() == 0 {
+		return nil, fmt.Errorf("genesis block is not traceable")
+	}
+	parent := bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent block %x not found", block.ParentHash())
+	}
+	statedb, err := bc.StateAt(parent.Root())
+	if err != nil {
+		// parent state was pruned, replay on the state read by the block
+		if statedb, err = state.TraceNew(block.Hash(), state.NewDatabase(b.uniService.chainDb)); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		receipts []*types.Receipt
+		header   = block.Header()
+		usedGas  = new(uint64)
+		gp       = new(common.GasPool).AddGas(block.GasLimit())
+	)
+	if err := b.uniService.engine.Prepare(bc, header, block.Transactions(), nil, statedb); err != nil {
+		return nil, err
+	}
+	for i, tx := range block.Transactions() {
+		if i > end {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		cfg := vmCfg(i)
+		// also catch the reads made before the evm is created, and never
+		// trace the transactions replayed without a tracer
+		tracer, _ := cfg.Tracer.(state.AccessTracer)
+		if !cfg.Debug {
+			tracer = nil
+		}
+		statedb.SetAccessTracer(tracer)
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		receipt, _, err := bc.Processor().ApplyTransaction(nil, gp, statedb, header, tx, usedGas, cfg)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+// ApplyTransaction executes a single tx on statedb in the context of header,
+// the same way block processing does.
+func (b *APIBackend) ApplyTransaction(ctx context.Context, tx *types.Transaction, statedb *state.StateDB, header *types.Header, vmCfg vm.Config) (*types.Receipt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var (
+		bc      = b.uniService.blockchain
+		usedGas = new(uint64)
+		gp      = new(common.GasPool).AddGas(header.GasLimit)
+	)
+	statedb.Prepare(tx.Hash(), header.Hash(), 0)
+	receipt, _, err := bc.Processor().ApplyTransaction(nil, gp, statedb, header, tx, usedGas, vmCfg)
+	return receipt, err
+}
+
 func (b *APIBackend) SetGasPrice(gasPrice *big.Int) bool {
 	return b.uniService.SetGasPrice(gasPrice)
 }
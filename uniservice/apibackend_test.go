@@ -0,0 +1,142 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package uniservice
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/consensus/dpos"
+	"github.com/unichainplatform/unichain/rawdb"
+	"github.com/unichainplatform/unichain/rpcapi"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/fdb"
+)
+
+const testIndexHead = 3
+
+var testIndexAccount = common.Name("a123456789test")
+
+// newIndexBackend serves an account tx index of five entries, the entry seq
+// is of block seq, over a chain whose head block is testIndexHead.
+func newIndexBackend(t *testing.T) (*APIBackend, fdb.Database) {
+	db := rawdb.NewMemoryDatabase()
+	head := &types.Header{Number: big.NewInt(testIndexHead), Time: big.NewInt(0), Difficulty: big.NewInt(0)}
+	rawdb.WriteHeader(db, head)
+	rawdb.WriteHeadBlockHash(db, head.Hash())
+	for seq := uint64(0); seq < 5; seq++ {
+		rawdb.WriteAccountTxIndexEntry(db, testIndexAccount, seq, &rawdb.AccountTxIndexEntry{BlockNumber: seq})
+	}
+	rawdb.WriteAccountTxCount(db, testIndexAccount, 5)
+	rawdb.WriteAccountTxIndexHead(db, testIndexHead)
+	return &APIBackend{uniService: &UniService{chainDb: db, engine: dpos.New(dpos.DefaultConfig, nil)}}, db
+}
+
+func pageSeqs(page *types.AccountTxsPage) []uint64 {
+	seqs := make([]uint64, 0, len(page.Txs))
+	for _, tx := range page.Txs {
+		if tx.Height != tx.Seq {
+			return nil
+		}
+		seqs = append(seqs, tx.Seq)
+	}
+	return seqs
+}
+
+func TestGetTxsByAccountIndex(t *testing.T) {
+	backend, _ := newIndexBackend(t)
+	for _, test := range []struct {
+		cursor, limit uint64
+		desc          bool
+		seqs          []uint64
+		next          *uint64
+	}{
+		{cursor: 0, limit: 2, seqs: []uint64{0, 1}, next: newUint64(2)},
+		{cursor: 2, limit: 2, seqs: []uint64{2, 3}, next: newUint64(4)},
+		{cursor: 4, limit: 2, seqs: []uint64{4}},
+		{cursor: 5, limit: 2, seqs: []uint64{}},
+		{cursor: 1, limit: 10, seqs: []uint64{1, 2, 3, 4}},
+		// in descending order cursor 0 is the newest and the cursor is exclusive
+		{cursor: 0, limit: 2, desc: true, seqs: []uint64{4, 3}, next: newUint64(3)},
+		{cursor: 3, limit: 2, desc: true, seqs: []uint64{2, 1}, next: newUint64(1)},
+		{cursor: 1, limit: 2, desc: true, seqs: []uint64{0}},
+		{cursor: 9, limit: 1, desc: true, seqs: []uint64{4}, next: newUint64(4)},
+	} {
+		page, err := backend.GetTxsByAccountIndex(context.Background(), testIndexAccount, test.cursor, test.limit, test.desc)
+		if err != nil {
+			t.Fatalf("cursor %v limit %v desc %v: %v", test.cursor, test.limit, test.desc, err)
+		}
+		if seqs := pageSeqs(page); !reflect.DeepEqual(seqs, test.seqs) {
+			t.Errorf("cursor %v limit %v desc %v: have seqs %v, want %v", test.cursor, test.limit, test.desc, seqs, test.seqs)
+		}
+		if !reflect.DeepEqual(page.NextCursor, test.next) {
+			t.Errorf("cursor %v limit %v desc %v: have next cursor %v, want %v", test.cursor, test.limit, test.desc, page.NextCursor, test.next)
+		}
+		if page.Total != 5 || page.IndexedHeight != testIndexHead {
+			t.Errorf("cursor %v limit %v desc %v: have total %v indexed height %v", test.cursor, test.limit, test.desc, page.Total, page.IndexedHeight)
+		}
+	}
+}
+
+func TestGetTxsByAccountPaged(t *testing.T) {
+	backend, _ := newIndexBackend(t)
+	api := rpcapi.NewPublicBlockChainAPI(backend)
+
+	// a limit of 0 is the largest page
+	for direction, want := range map[string][]uint64{"": {0, 1, 2, 3, 4}, "asc": {0, 1, 2, 3, 4}, "desc": {4, 3, 2, 1, 0}} {
+		page, err := api.GetTxsByAccountPaged(context.Background(), testIndexAccount, 0, 0, direction)
+		if err != nil {
+			t.Fatalf("direction %q: %v", direction, err)
+		}
+		if seqs := pageSeqs(page); !reflect.DeepEqual(seqs, want) || page.NextCursor != nil {
+			t.Errorf("direction %q: have seqs %v next cursor %v, want %v", direction, seqs, page.NextCursor, want)
+		}
+	}
+	if _, err := api.GetTxsByAccountPaged(context.Background(), testIndexAccount, 0, 0, "up"); err == nil {
+		t.Error("unknown direction accepted")
+	}
+
+	// walking the pages of the next cursors returns every entry once
+	var seqs []uint64
+	for cursor := newUint64(0); cursor != nil; {
+		page, err := api.GetTxsByAccountPaged(context.Background(), testIndexAccount, *cursor, 2, "desc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		seqs, cursor = append(seqs, pageSeqs(page)...), page.NextCursor
+	}
+	if want := []uint64{4, 3, 2, 1, 0}; !reflect.DeepEqual(seqs, want) {
+		t.Errorf("desc walk: have seqs %v, want %v", seqs, want)
+	}
+}
+
+func TestGetTxsByAccountIndexLag(t *testing.T) {
+	backend, db := newIndexBackend(t)
+	rawdb.WriteAccountTxIndexHead(db, testIndexHead-1)
+	if _, err := backend.GetTxsByAccountIndex(context.Background(), testIndexAccount, 0, 2, false); err == nil {
+		t.Error("index behind the head block served")
+	}
+	rawdb.DeleteAccountTxIndexHead(db)
+	if _, err := backend.GetTxsByAccountIndex(context.Background(), testIndexAccount, 0, 2, false); err == nil {
+		t.Error("missing index served")
+	}
+}
+
+func newUint64(n uint64) *uint64 { return &n }
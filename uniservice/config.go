@@ -44,11 +44,18 @@ type Config struct {
 
 	MetricsConf *metrics.Config `mapstructure:"metrics"`
 
-	StatePruning    bool `mapstructure:"statepruning"`
-	ContractLogFlag bool `mapstructure:"contractlog"`
+	StatePruning      bool `mapstructure:"statepruning"`
+	ContractLogFlag   bool `mapstructure:"contractlog"`
+	AccountIndexFlag  bool `mapstructure:"accountindex"`
+	InternalIndexFlag bool `mapstructure:"internalindex"`
 
 	BadHashes   []string `mapstructure:"badhashes"`
 	StartNumber uint64   `mapstructure:"startnumber"`
+	FastSync    bool     `mapstructure:"fastsync"`
+	Light       bool     `mapstructure:"light"`
+
+	// Directory of the encrypted key files, relative to the data directory
+	KeyStore string `mapstructure:"keystore"`
 }
 
 // MinerConfig miner config
@@ -58,4 +65,12 @@ type MinerConfig struct {
 	Name        string   `mapstructure:"name"`
 	PrivateKeys []string `mapstructure:"private"`
 	ExtraData   string   `mapstructure:"extra"`
+
+	// Public keys of keystore keys used instead of PrivateKeys, they are
+	// unlocked with the first line of PasswordFile.
+	KeyStoreKeys []string `mapstructure:"keystorekeys"`
+	PasswordFile string   `mapstructure:"password"`
+
+	// IPC path or HTTP url of a remote signer holding the block signing keys
+	Signer string `mapstructure:"signer"`
 }
@@ -17,14 +17,20 @@
 package uniservice
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/unichainplatform/unichain/blockchain"
+	"github.com/unichainplatform/unichain/common"
 	"github.com/unichainplatform/unichain/consensus"
 	"github.com/unichainplatform/unichain/consensus/dpos"
 	"github.com/unichainplatform/unichain/consensus/miner"
 	"github.com/unichainplatform/unichain/uniservice/gasprice"
+	"github.com/unichainplatform/unichain/keystore"
 	"github.com/unichainplatform/unichain/node"
 	"github.com/unichainplatform/unichain/p2p"
 	adaptor "github.com/unichainplatform/unichain/p2p/protoadaptor"
@@ -39,16 +45,18 @@ import (
 
 // UniService implements the unichain service.
 type UniService struct {
-	config       *Config
-	chainConfig  *params.ChainConfig
-	shutdownChan chan bool // Channel for shutting down the service
-	blockchain   *blockchain.BlockChain
-	txPool       *txpool.TxPool
-	chainDb      fdb.Database // Block chain database
-	engine       consensus.IEngine
-	miner        *miner.Miner
-	p2pServer    *adaptor.ProtoAdaptor
-	APIBackend   *APIBackend
+	config          *Config
+	chainConfig     *params.ChainConfig
+	shutdownChan    chan bool // Channel for shutting down the service
+	blockchain      *blockchain.BlockChain
+	txPool          *txpool.TxPool
+	chainDb         fdb.Database // Block chain database
+	engine          consensus.IEngine
+	evidenceStation *dpos.EvidenceStation
+	miner           *miner.Miner
+	keyManager      *keystore.Manager
+	p2pServer       *adaptor.ProtoAdaptor
+	APIBackend      *APIBackend
 }
 
 // New creates a new uniservice object (including the initialisation of the common uniservice object)
@@ -58,7 +66,7 @@ func New(ctx *node.ServiceContext, config *Config) (*UniService, error) {
 		return nil, err
 	}
 
-	chainCfg, dposCfg, _, err := blockchain.SetupGenesisBlock(chainDb, config.Genesis)
+	chainCfg, dposCfg, _, err := blockchain.SetupGenesisBlock(chainDb, config.Genesis, config.ContractLogFlag || config.InternalIndexFlag)
 	if err != nil {
 		return nil, err
 	}
@@ -75,13 +83,16 @@ func New(ctx *node.ServiceContext, config *Config) (*UniService, error) {
 
 	//blockchain
 	vmconfig := vm.Config{
-		ContractLogFlag: config.ContractLogFlag,
+		ContractLogFlag:   config.ContractLogFlag,
+		AccountIndexFlag:  config.AccountIndexFlag,
+		InternalIndexFlag: config.InternalIndexFlag,
 	}
 
 	uniService.blockchain, err = blockchain.NewBlockChain(chainDb, config.StatePruning, vmconfig, uniService.chainConfig, config.BadHashes, config.StartNumber, txpool.SenderCacher)
 	if err != nil {
 		return nil, err
 	}
+	uniService.blockchain.SetFastSync(config.FastSync)
 
 	// txpool
 	if config.TxPool.Journal != "" {
@@ -92,6 +103,7 @@ func New(ctx *node.ServiceContext, config *Config) (*UniService, error) {
 
 	engine := dpos.New(dposCfg, uniService.blockchain)
 	uniService.engine = engine
+	uniService.evidenceStation = dpos.NewEvidenceStation(engine, uniService.chainConfig.ChainID)
 
 	type bc struct {
 		*blockchain.BlockChain
@@ -116,7 +128,23 @@ func New(ctx *node.ServiceContext, config *Config) (*UniService, error) {
 	bcc.Processor = txProcessor
 	uniService.miner = miner.NewMiner(bcc)
 	uniService.miner.SetDelayDuration(config.Miner.Delay)
-	uniService.miner.SetCoinbase(config.Miner.Name, config.Miner.PrivateKeys)
+	keystoreDir := config.KeyStore
+	if keystoreDir == "" {
+		keystoreDir = "keystore"
+	}
+	uniService.keyManager = keystore.NewManager(keystore.NewKeyStore(ctx.ResolvePath(keystoreDir), keystore.StandardScryptN, keystore.StandardScryptP))
+	uniService.miner.SetKeyManager(uniService.keyManager)
+	if config.Miner.Signer != "" {
+		if err := uniService.miner.SetRemoteSigner(config.Miner.Name, config.Miner.Signer); err != nil {
+			return nil, fmt.Errorf("failed to connect to remote signer: %v", err)
+		}
+	} else if len(config.Miner.KeyStoreKeys) > 0 {
+		if err := uniService.setKeystoreCoinbase(config.Miner); err != nil {
+			return nil, err
+		}
+	} else {
+		uniService.miner.SetCoinbase(config.Miner.Name, config.Miner.PrivateKeys)
+	}
 	uniService.miner.SetExtra([]byte(config.Miner.ExtraData))
 	if config.Miner.Start {
 		uniService.miner.Start(false)
@@ -144,6 +172,7 @@ func (fs *UniService) Stop() error {
 	fs.miner.Stop()
 	fs.blockchain.Stop()
 	fs.txPool.Stop()
+	fs.evidenceStation.Stop()
 	fs.chainDb.Close()
 	close(fs.shutdownChan)
 	log.Info("uniservice stopped")
@@ -161,6 +190,29 @@ func (fs *UniService) SetGasPrice(gasPrice *big.Int) bool {
 	return true
 }
 
+// setKeystoreCoinbase unlocks the configured keystore keys of the miner with
+// the passphrase of the password file and sets them as coinbase keys.
+func (fs *UniService) setKeystoreCoinbase(config *MinerConfig) error {
+	if config.PasswordFile == "" {
+		return errors.New("miner password file is required to unlock keystore keys")
+	}
+	text, err := ioutil.ReadFile(config.PasswordFile)
+	if err != nil {
+		return fmt.Errorf("failed to read miner password file: %v", err)
+	}
+	passphrase := strings.TrimRight(strings.Split(string(text), "\n")[0], "\r")
+
+	pubKeys := make([]common.PubKey, 0, len(config.KeyStoreKeys))
+	for _, key := range config.KeyStoreKeys {
+		pubKey := common.HexToPubKey(key)
+		if err := fs.keyManager.Unlock(pubKey, passphrase); err != nil {
+			return fmt.Errorf("failed to unlock miner key %v: %v", key, err)
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return fs.miner.SetCoinbaseFromKeystore(config.Name, pubKeys)
+}
+
 // CreateDB creates the chain database.
 func CreateDB(ctx *node.ServiceContext, config *Config, name string) (fdb.Database, error) {
 	db, err := ctx.OpenDatabase(name, config.DatabaseCache, config.DatabaseHandles)
@@ -174,4 +226,5 @@ func (s *UniService) BlockChain() *blockchain.BlockChain { return s.blockchain }
 func (s *UniService) TxPool() *txpool.TxPool             { return s.txPool }
 func (s *UniService) Engine() consensus.IEngine          { return s.engine }
 func (s *UniService) ChainDb() fdb.Database              { return s.chainDb }
+func (s *UniService) KeyManager() *keystore.Manager      { return s.keyManager }
 func (s *UniService) Protocols() []p2p.Protocol          { return nil }
@@ -0,0 +1,208 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const specABI = `[
+	{"type":"function","name":"f","inputs":[{"name":"a","type":"uint256"},{"name":"b","type":"uint32[]"},{"name":"c","type":"bytes10"},{"name":"d","type":"bytes"}]},
+	{"type":"function","name":"g","inputs":[{"name":"a","type":"uint256[][]"},{"name":"b","type":"string[]"}]},
+	{"type":"function","name":"h","inputs":[{"name":"order","type":"tuple","components":[{"name":"amount","type":"uint256"},{"name":"memo_text","type":"string"}]}]}
+]`
+
+func mustHex(t *testing.T, words ...string) []byte {
+	b, err := hex.DecodeString(strings.Join(words, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// the examples of the solidity abi specification
+func TestPackSpecExamples(t *testing.T) {
+	abi, err := JSON(strings.NewReader(specABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var c [10]byte
+	copy(c[:], "1234567890")
+	fargs := []interface{}{big.NewInt(0x123), []uint32{0x456, 0x789}, c, []byte("Hello, world!")}
+	packed, err := abi.Pack("f", fargs...)
+	if err != nil {
+		t.Fatalf("pack f: %v", err)
+	}
+	want := mustHex(t,
+		"8be65246",
+		"0000000000000000000000000000000000000000000000000000000000000123",
+		"0000000000000000000000000000000000000000000000000000000000000080",
+		"3132333435363738393000000000000000000000000000000000000000000000",
+		"00000000000000000000000000000000000000000000000000000000000000e0",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"0000000000000000000000000000000000000000000000000000000000000456",
+		"0000000000000000000000000000000000000000000000000000000000000789",
+		"000000000000000000000000000000000000000000000000000000000000000d",
+		"48656c6c6f2c20776f726c642100000000000000000000000000000000000000",
+	)
+	if !bytes.Equal(packed, want) {
+		t.Fatalf("pack f mismatch\nhave %x\nwant %x", packed, want)
+	}
+	values, err := abi.Methods["f"].Inputs.UnpackValues(packed[4:])
+	if err != nil {
+		t.Fatalf("unpack f: %v", err)
+	}
+	if !reflect.DeepEqual(values, fargs) {
+		t.Fatalf("unpack f mismatch\nhave %v\nwant %v", values, fargs)
+	}
+
+	gargs := []interface{}{[][]*big.Int{{big.NewInt(1), big.NewInt(2)}, {big.NewInt(3)}}, []string{"one", "two", "three"}}
+	packed, err = abi.Pack("g", gargs...)
+	if err != nil {
+		t.Fatalf("pack g: %v", err)
+	}
+	want = mustHex(t,
+		"2289b18c",
+		"0000000000000000000000000000000000000000000000000000000000000040",
+		"0000000000000000000000000000000000000000000000000000000000000140",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"0000000000000000000000000000000000000000000000000000000000000040",
+		"00000000000000000000000000000000000000000000000000000000000000a0",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"0000000000000000000000000000000000000000000000000000000000000060",
+		"00000000000000000000000000000000000000000000000000000000000000a0",
+		"00000000000000000000000000000000000000000000000000000000000000e0",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"6f6e650000000000000000000000000000000000000000000000000000000000",
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"74776f0000000000000000000000000000000000000000000000000000000000",
+		"0000000000000000000000000000000000000000000000000000000000000005",
+		"7468726565000000000000000000000000000000000000000000000000000000",
+	)
+	if !bytes.Equal(packed, want) {
+		t.Fatalf("pack g mismatch\nhave %x\nwant %x", packed, want)
+	}
+	values, err = abi.Methods["g"].Inputs.UnpackValues(packed[4:])
+	if err != nil {
+		t.Fatalf("unpack g: %v", err)
+	}
+	if !reflect.DeepEqual(values, gargs) {
+		t.Fatalf("unpack g mismatch\nhave %v\nwant %v", values, gargs)
+	}
+}
+
+func TestPackTuple(t *testing.T) {
+	abi, err := JSON(strings.NewReader(specABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := abi.Methods["h"]
+	if sig := method.Sig(); sig != "h((uint256,string))" {
+		t.Fatalf("signature mismatch %v", sig)
+	}
+
+	order := struct {
+		Amount   *big.Int
+		MemoText string
+	}{big.NewInt(7), "memo"}
+	packed, err := abi.Pack("h", order)
+	if err != nil {
+		t.Fatalf("pack h: %v", err)
+	}
+	want := mustHex(t,
+		"0000000000000000000000000000000000000000000000000000000000000020",
+		"0000000000000000000000000000000000000000000000000000000000000007",
+		"0000000000000000000000000000000000000000000000000000000000000040",
+		"0000000000000000000000000000000000000000000000000000000000000004",
+		"6d656d6f00000000000000000000000000000000000000000000000000000000",
+	)
+	if !bytes.Equal(packed[4:], want) {
+		t.Fatalf("pack h mismatch\nhave %x\nwant %x", packed[4:], want)
+	}
+
+	values, err := method.Inputs.UnpackValues(packed[4:])
+	if err != nil {
+		t.Fatalf("unpack h: %v", err)
+	}
+	decoded := reflect.ValueOf(values[0])
+	if amount := decoded.FieldByName("Amount").Interface().(*big.Int); amount.Cmp(order.Amount) != 0 {
+		t.Fatalf("amount mismatch %v", amount)
+	}
+	if memo := decoded.FieldByName("MemoText").String(); memo != order.MemoText {
+		t.Fatalf("memo mismatch %v", memo)
+	}
+}
+
+func TestToCamelCase(t *testing.T) {
+	for input, want := range map[string]string{
+		"foo":      "Foo",
+		"foo_bar":  "FooBar",
+		"_foo":     "Foo",
+		"__foo":    "Foo",
+		"foo__bar": "FooBar",
+		"FooBar":   "FooBar",
+		"":         "",
+	} {
+		if have := ToCamelCase(input); have != want {
+			t.Errorf("ToCamelCase(%q) = %q, want %q", input, have, want)
+		}
+	}
+}
+
+func TestUnpackUnderscoreNames(t *testing.T) {
+	abi, err := JSON(strings.NewReader(`[{"type":"function","name":"f","outputs":[{"name":"foo_bar","type":"uint256"},{"name":"_baz","type":"uint256"}]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := abi.Methods["f"].Outputs.Pack(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var capitalised struct {
+		Foo_bar *big.Int
+		Baz     *big.Int
+	}
+	if err := abi.Unpack(&capitalised, "f", output); err != nil {
+		t.Fatal(err)
+	}
+	if capitalised.Foo_bar.Int64() != 1 || capitalised.Baz.Int64() != 2 {
+		t.Fatalf("capitalised fields mismatch %v %v", capitalised.Foo_bar, capitalised.Baz)
+	}
+
+	var camel struct {
+		FooBar *big.Int
+		Baz    *big.Int
+	}
+	if err := abi.Unpack(&camel, "f", output); err != nil {
+		t.Fatal(err)
+	}
+	if camel.FooBar.Int64() != 1 || camel.Baz.Int64() != 2 {
+		t.Fatalf("camel-cased fields mismatch %v %v", camel.FooBar, camel.Baz)
+	}
+}
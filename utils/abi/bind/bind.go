@@ -0,0 +1,224 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bind generates Go bindings of contracts from their ABI, which call
+// the contracts through the sdk with account names in place of addresses.
+package bind
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/unichainplatform/unichain/utils/abi"
+)
+
+// reserved are the identifiers of the generated code clashing with the
+// parameters of the methods and events.
+var reserved = map[string]bool{
+	"opts": true, "api": true, "sink": true, "logs": true, "sub": true, "err": true, "ev": true,
+}
+
+// Bind generates the Go bindings in package pkg of the contracts of types,
+// with the ABI JSON and the hex bytecode, empty for no deployment, of each.
+func Bind(types []string, abis []string, bytecodes []string, pkg string) (string, error) {
+	if len(abis) != len(types) || len(bytecodes) != len(types) {
+		return "", fmt.Errorf("%d types, %d abis and %d bytecodes", len(types), len(abis), len(bytecodes))
+	}
+	data := &tmplData{Package: pkg, structs: make(map[string]*tmplStruct)}
+	for i, typ := range types {
+		contract, err := data.newTmplContract(typ, abis[i], bytecodes[i])
+		if err != nil {
+			return "", err
+		}
+		data.Contracts = append(data.Contracts, contract)
+	}
+
+	buffer := new(bytes.Buffer)
+	tmpl := template.Must(template.New("").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(tmplSource))
+	if err := tmpl.Execute(buffer, data); err != nil {
+		return "", err
+	}
+	code, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("%v\n%s", err, buffer)
+	}
+	return string(code), nil
+}
+
+func (data *tmplData) newTmplContract(typ string, input string, bytecode string) (*tmplContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+	compacted := new(bytes.Buffer)
+	if err := json.Compact(compacted, []byte(input)); err != nil {
+		return nil, err
+	}
+	contract := &tmplContract{
+		Type:     abi.ToCamelCase(typ),
+		InputABI: compacted.String(),
+		InputBin: strings.TrimSpace(bytecode),
+	}
+	if contract.Constructor, err = data.bindArgs(parsed.Constructor.Inputs); err != nil {
+		return nil, err
+	}
+
+	for _, name := range sortedMethods(parsed.Methods) {
+		original := parsed.Methods[name]
+		method := &tmplMethod{Original: original, Normalized: abi.ToCamelCase(original.Name)}
+		if method.Inputs, err = data.bindArgs(original.Inputs); err != nil {
+			return nil, err
+		}
+		if original.Const {
+			for _, output := range original.Outputs {
+				method.Outputs = append(method.Outputs, data.bindType(output.Type))
+			}
+			contract.Calls = append(contract.Calls, method)
+		} else {
+			contract.Transacts = append(contract.Transacts, method)
+		}
+	}
+
+	for _, name := range sortedEvents(parsed.Events) {
+		original := parsed.Events[name]
+		ev := &tmplEvent{Original: original, Normalized: abi.ToCamelCase(original.Name)}
+		for i, input := range original.Inputs {
+			field := &tmplArg{Name: abi.ToCamelCase(input.Name), Type: "common.Hash"}
+			if field.Name == "" {
+				field.Name = fmt.Sprintf("Arg%d", i)
+			}
+			if !input.Indexed || !isHashedTopic(input.Type) {
+				field.Type = data.bindType(input.Type)
+			}
+			if input.Indexed {
+				ev.Indexed = append(ev.Indexed, &tmplArg{Name: paramName(input.Name, i), Type: field.Type})
+			}
+			ev.Fields = append(ev.Fields, field)
+		}
+		contract.Events = append(contract.Events, ev)
+	}
+	return contract, nil
+}
+
+func sortedMethods(methods map[string]abi.Method) []string {
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedEvents(events map[string]abi.Event) []string {
+	names := make([]string, 0, len(events))
+	for name := range events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (data *tmplData) bindArgs(args abi.Arguments) ([]*tmplArg, error) {
+	var bound []*tmplArg
+	names := make(map[string]bool)
+	for i, arg := range args {
+		name := paramName(arg.Name, i)
+		if names[name] {
+			return nil, fmt.Errorf("duplicated argument %v", arg.Name)
+		}
+		names[name] = true
+		bound = append(bound, &tmplArg{Name: name, Type: data.bindType(arg.Type)})
+	}
+	return bound, nil
+}
+
+// bindType returns the Go type of the ABI type, account names are used in
+// place of addresses and tuples are bound to structs.
+func (data *tmplData) bindType(typ abi.Type) string {
+	switch typ.T {
+	case abi.AddressTy:
+		return "common.Name"
+	case abi.BoolTy:
+		return "bool"
+	case abi.StringTy:
+		return "string"
+	case abi.BytesTy:
+		return "[]byte"
+	case abi.FixedBytesTy:
+		return fmt.Sprintf("[%d]byte", typ.Size)
+	case abi.FunctionTy:
+		return "[24]byte"
+	case abi.SliceTy:
+		return "[]" + data.bindType(*typ.Elem)
+	case abi.ArrayTy:
+		return fmt.Sprintf("[%d]%s", typ.Size, data.bindType(*typ.Elem))
+	case abi.TupleTy:
+		return data.bindStruct(typ)
+	}
+	// integers are native types up to 64 bits, big ints otherwise
+	return typ.Type.String()
+}
+
+// bindStruct returns the name of the struct of the tuple typ, declaring it
+// on first use. Structs are named after the solidity source if known.
+func (data *tmplData) bindStruct(typ abi.Type) string {
+	id := typ.TupleRawName + typ.String() + strings.Join(typ.TupleRawNames, ",")
+	if s, ok := data.structs[id]; ok {
+		return s.Name
+	}
+	s := &tmplStruct{Name: typ.TupleRawName}
+	if s.Name == "" {
+		s.Name = fmt.Sprintf("Struct%d", data.anonymous)
+		data.anonymous++
+	}
+	for i, elem := range typ.TupleElems {
+		s.Fields = append(s.Fields, &tmplArg{Name: abi.ToCamelCase(typ.TupleRawNames[i]), Type: data.bindType(*elem)})
+	}
+	data.structs[id] = s
+	data.Structs = append(data.Structs, s)
+	return s.Name
+}
+
+// isHashedTopic returns whether indexed arguments of typ are stored as the
+// hash of their encoding in topics.
+func isHashedTopic(typ abi.Type) bool {
+	switch typ.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+// paramName returns the Go parameter name of the argument at index.
+func paramName(name string, index int) string {
+	name = abi.ToCamelCase(name)
+	if name == "" {
+		return fmt.Sprintf("arg%d", index)
+	}
+	name = string(unicode.ToLower(rune(name[0]))) + name[1:]
+	if token.Lookup(name).IsKeyword() || reserved[name] {
+		return "arg" + abi.ToCamelCase(name)
+	}
+	return name
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const tokenABI = `[
+	{"type":"constructor","inputs":[{"name":"supply","type":"uint256"},{"name":"symbol","type":"string"}]},
+	{"type":"function","name":"balance_of","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"owner","constant":true,"inputs":[],"outputs":[{"name":"","type":"address"},{"name":"","type":"uint8"}]},
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"type","type":"bytes32[]"}],"outputs":[]},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"memo","type":"string","indexed":true},{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+func TestBind(t *testing.T) {
+	code, err := Bind([]string{"token"}, []string{tokenABI}, []string{"0x6060"}, "tokens")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "token.go", code, 0); err != nil {
+		t.Fatalf("invalid binding: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		"package tokens",
+		"const TokenBin = \"0x6060\"",
+		"func DeployToken(opts *sdk.TransactOpts, api *sdk.API, supply *big.Int, symbol string) (common.Hash, *Token, error)",
+		"func NewToken(name common.Name, api *sdk.API) (*Token, error)",
+		"func (_Token *TokenCaller) BalanceOf(opts *sdk.CallOpts, owner common.Name) (*big.Int, error)",
+		"func (_Token *TokenCaller) Owner(opts *sdk.CallOpts) (common.Name, uint8, error)",
+		"func (_Token *TokenTransactor) Transfer(opts *sdk.TransactOpts, to common.Name, value *big.Int, argType [][32]byte) (common.Hash, error)",
+		"func (_Token *TokenFilterer) WatchTransfer(opts *sdk.WatchOpts, sink chan<- *TokenTransfer, from []common.Name, memo []common.Hash) (event.Subscription, error)",
+		"func (_Token *TokenFilterer) ParseTransfer(log types.Log) (*TokenTransfer, error)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("binding misses %q\n%s", want, code)
+		}
+	}
+}
+
+func TestBindNoBytecode(t *testing.T) {
+	code, err := Bind([]string{"Token"}, []string{tokenABI}, []string{""}, "tokens")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(code, "DeployToken") {
+		t.Errorf("deploy method bound without bytecode")
+	}
+	if _, err := Bind([]string{"Token"}, []string{"{"}, []string{""}, "tokens"); err == nil {
+		t.Errorf("invalid abi bound")
+	}
+}
+
+const ordersABI = `[
+	{"type":"function","name":"orders","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"tuple[]","internalType":"struct Book.Order[]","components":[{"name":"maker","type":"address"},{"name":"prices","type":"uint256[][]"},{"name":"fee","type":"tuple","components":[{"name":"asset_id","type":"uint64"},{"name":"memo","type":"string"}]}]}]},
+	{"type":"function","name":"place","inputs":[{"name":"order","type":"tuple","internalType":"struct Book.Order","components":[{"name":"maker","type":"address"},{"name":"prices","type":"uint256[][]"},{"name":"fee","type":"tuple","components":[{"name":"asset_id","type":"uint64"},{"name":"memo","type":"string"}]}]}],"outputs":[]},
+	{"type":"event","name":"Placed","inputs":[{"name":"order","type":"tuple","indexed":true,"components":[{"name":"maker","type":"address"}]}]}
+]`
+
+func TestBindTuple(t *testing.T) {
+	code, err := Bind([]string{"Book"}, []string{ordersABI}, []string{""}, "book")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "book.go", code, 0); err != nil {
+		t.Fatalf("invalid binding: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		"type BookOrder struct",
+		"Prices [][]*big.Int",
+		"Fee    Struct0",
+		"type Struct0 struct",
+		"AssetId uint64",
+		"func (_Book *BookCaller) Orders(opts *sdk.CallOpts, owner common.Name) ([]BookOrder, error)",
+		"func (_Book *BookTransactor) Place(opts *sdk.TransactOpts, order BookOrder) (common.Hash, error)",
+		"func (_Book *BookFilterer) WatchPlaced(opts *sdk.WatchOpts, sink chan<- *BookPlaced, order []common.Hash) (event.Subscription, error)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("binding misses %q\n%s", want, code)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import "github.com/unichainplatform/unichain/utils/abi"
+
+// tmplData is the data of the template generating the bindings.
+type tmplData struct {
+	Package   string          // package name of the generated file
+	Contracts []*tmplContract // contracts bound in the file
+	Structs   []*tmplStruct   // structs of the tuples used by the contracts
+
+	structs   map[string]*tmplStruct // structs by tuple signature
+	anonymous int                    // number of structs without source names
+}
+
+// tmplContract is the data of a contract bound by the template.
+type tmplContract struct {
+	Type        string        // type name of the main contract binding
+	InputABI    string        // compacted JSON ABI of the contract
+	InputBin    string        // hex bytecode deploying the contract, empty if not deployable
+	Constructor []*tmplArg    // arguments of the constructor
+	Calls       []*tmplMethod // constant methods, called without transactions
+	Transacts   []*tmplMethod // methods invoked by transactions
+	Events      []*tmplEvent  // events watched and parsed from logs
+}
+
+// tmplMethod is a method of a contract.
+type tmplMethod struct {
+	Original   abi.Method // method as parsed from the ABI
+	Normalized string     // Go name of the method
+	Inputs     []*tmplArg // bound arguments of the method
+	Outputs    []string   // Go types of the returned values
+}
+
+// tmplEvent is an event of a contract.
+type tmplEvent struct {
+	Original   abi.Event  // event as parsed from the ABI
+	Normalized string     // Go name of the event
+	Fields     []*tmplArg // fields of the event struct
+	Indexed    []*tmplArg // indexed arguments filtering the logs
+}
+
+// tmplStruct is the struct of a tuple.
+type tmplStruct struct {
+	Name   string
+	Fields []*tmplArg
+}
+
+// tmplArg is a named argument or field of a Go type.
+type tmplArg struct {
+	Name string
+	Type string
+}
+
+// tmplSource is the Go source template of the bindings.
+const tmplSource = `// Code generated by uniabigen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/event"
+	"github.com/unichainplatform/unichain/sdk"
+	"github.com/unichainplatform/unichain/types"
+	"github.com/unichainplatform/unichain/utils/abi"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = common.Name("")
+	_ = event.NewSubscription
+	_ = types.Log{}
+	_ = abi.JSON
+	_ = sdk.NewBoundContract
+)
+{{range .Structs}}
+// {{.Name}} is a Go binding of a tuple of the contracts.
+type {{.Name}} struct {
+	{{range .Fields}}{{.Name}} {{.Type}}
+	{{end}}
+}
+{{end}}{{range $contract := .Contracts}}
+// {{.Type}}ABI is the input ABI used to generate the binding from.
+const {{.Type}}ABI = {{quote .InputABI}}
+{{if .InputBin}}
+// {{.Type}}Bin is the compiled bytecode used for deploying new contracts.
+const {{.Type}}Bin = {{quote .InputBin}}
+
+// Deploy{{.Type}} deploys a new {{.Type}} contract to the account of opts.From,
+// binding an instance of {{.Type}} to it.
+func Deploy{{.Type}}(opts *sdk.TransactOpts, api *sdk.API{{range .Constructor}}, {{.Name}} {{.Type}}{{end}}) (common.Hash, *{{.Type}}, error) {
+	parsed, err := abi.JSON(strings.NewReader({{.Type}}ABI))
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	hash, contract, err := sdk.DeployContract(opts, parsed, common.FromHex({{.Type}}Bin), api{{range .Constructor}}, {{.Name}}{{end}})
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	return hash, &{{.Type}}{ {{.Type}}Caller: {{.Type}}Caller{contract: contract}, {{.Type}}Transactor: {{.Type}}Transactor{contract: contract}, {{.Type}}Filterer: {{.Type}}Filterer{contract: contract} }, nil
+}
+{{end}}
+// {{.Type}} is a Go binding around a contract of the chain.
+type {{.Type}} struct {
+	{{.Type}}Caller     // read-only binding to the contract
+	{{.Type}}Transactor // write-only binding to the contract
+	{{.Type}}Filterer   // log filterer for contract events
+}
+
+// {{.Type}}Caller is a read-only Go binding around a contract of the chain.
+type {{.Type}}Caller struct {
+	contract *sdk.BoundContract
+}
+
+// {{.Type}}Transactor is a write-only Go binding around a contract of the chain.
+type {{.Type}}Transactor struct {
+	contract *sdk.BoundContract
+}
+
+// {{.Type}}Filterer is a log filtering Go binding around a contract of the chain.
+type {{.Type}}Filterer struct {
+	contract *sdk.BoundContract
+}
+
+// New{{.Type}} creates a new instance of {{.Type}}, bound to the contract of
+// the account name.
+func New{{.Type}}(name common.Name, api *sdk.API) (*{{.Type}}, error) {
+	parsed, err := abi.JSON(strings.NewReader({{.Type}}ABI))
+	if err != nil {
+		return nil, err
+	}
+	contract := sdk.NewBoundContract(name, parsed, api)
+	return &{{.Type}}{ {{.Type}}Caller: {{.Type}}Caller{contract: contract}, {{.Type}}Transactor: {{.Type}}Transactor{contract: contract}, {{.Type}}Filterer: {{.Type}}Filterer{contract: contract} }, nil
+}
+{{range .Calls}}
+// {{.Normalized}} is a free data retrieval call binding the contract method {{printf "0x%x" .Original.Id}}.
+//
+// Solidity: {{.Original.String}}
+func (_{{$contract.Type}} *{{$contract.Type}}Caller) {{.Normalized}}(opts *sdk.CallOpts{{range .Inputs}}, {{.Name}} {{.Type}}{{end}}) ({{range $i, $t := .Outputs}}{{$t}}, {{end}}error) {
+	var (
+		{{range $i, $t := .Outputs}}ret{{$i}} = new({{$t}})
+		{{end}}
+	)
+	err := _{{$contract.Type}}.contract.Call(opts, []interface{}{ {{range $i, $t := .Outputs}}ret{{$i}}, {{end}} }, {{quote .Original.Name}}{{range .Inputs}}, {{.Name}}{{end}})
+	return {{range $i, $t := .Outputs}}*ret{{$i}}, {{end}}err
+}
+{{end}}
+{{range .Transacts}}
+// {{.Normalized}} is a paid mutator transaction binding the contract method {{printf "0x%x" .Original.Id}}.
+//
+// Solidity: {{.Original.String}}
+func (_{{$contract.Type}} *{{$contract.Type}}Transactor) {{.Normalized}}(opts *sdk.TransactOpts{{range .Inputs}}, {{.Name}} {{.Type}}{{end}}) (common.Hash, error) {
+	return _{{$contract.Type}}.contract.Transact(opts, {{quote .Original.Name}}{{range .Inputs}}, {{.Name}}{{end}})
+}
+{{end}}
+{{range .Events}}
+// {{$contract.Type}}{{.Normalized}} represents a {{.Normalized}} event raised by the {{$contract.Type}} contract.
+type {{$contract.Type}}{{.Normalized}} struct {
+	{{range .Fields}}{{.Name}} {{.Type}}
+	{{end}}Raw types.Log // log of the event
+}
+
+// Watch{{.Normalized}} watches the {{.Original.Name}} event, sending the events
+// whose indexed arguments match one of the given values, any if none, to sink.
+//
+// Solidity: {{.Original.String}}
+func (_{{$contract.Type}} *{{$contract.Type}}Filterer) Watch{{.Normalized}}(opts *sdk.WatchOpts, sink chan<- *{{$contract.Type}}{{.Normalized}}{{range .Indexed}}, {{.Name}} []{{.Type}}{{end}}) (event.Subscription, error) {
+	{{range .Indexed}}var {{.Name}}Rule []interface{}
+	for _, item := range {{.Name}} {
+		{{.Name}}Rule = append({{.Name}}Rule, item)
+	}
+	{{end}}
+	logs, sub, err := _{{$contract.Type}}.contract.WatchLogs(opts, {{quote .Original.Name}}{{range .Indexed}}, {{.Name}}Rule{{end}})
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev, err := _{{$contract.Type}}.Parse{{.Normalized}}(log)
+				if err != nil {
+					return err
+				}
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// Parse{{.Normalized}} parses the {{.Original.Name}} event of log.
+//
+// Solidity: {{.Original.String}}
+func (_{{$contract.Type}} *{{$contract.Type}}Filterer) Parse{{.Normalized}}(log types.Log) (*{{$contract.Type}}{{.Normalized}}, error) {
+	ev := &{{$contract.Type}}{{.Normalized}}{Raw: log}
+	if err := _{{$contract.Type}}.contract.UnpackLog([]interface{}{ {{range .Fields}}&ev.{{.Name}}, {{end}} }, {{quote .Original.Name}}, log); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+{{end}}
+{{end}}
+`
@@ -71,22 +71,60 @@ func mustArrayToByteSlice(value reflect.Value) reflect.Value {
 //
 // set is a bit more lenient when it comes to assignment and doesn't force an as
 // strict ruleset as bare `reflect` does.
-func set(dst, src reflect.Value, output Argument) error {
+func set(dst, src reflect.Value) error {
 	dstType := dst.Type()
 	srcType := src.Type()
 	switch {
-	case dstType.AssignableTo(srcType):
+	case srcType.AssignableTo(dstType):
 		dst.Set(src)
 	case dstType.Kind() == reflect.Interface:
 		dst.Set(src)
-	case dstType.Kind() == reflect.Ptr:
-		return set(dst.Elem(), src, output)
+	case dstType.Kind() == reflect.Ptr && (!dst.IsNil() || dst.CanSet()):
+		if dst.IsNil() {
+			dst.Set(reflect.New(dstType.Elem()))
+		}
+		return set(dst.Elem(), src)
+	case dstType.Kind() == reflect.Slice && (srcType.Kind() == reflect.Slice || srcType.Kind() == reflect.Array):
+		slice := reflect.MakeSlice(dstType, src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := set(slice.Index(i), src.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+	case dstType.Kind() == reflect.Array && (srcType.Kind() == reflect.Slice || srcType.Kind() == reflect.Array):
+		if src.Len() != dst.Len() {
+			return fmt.Errorf("abi: cannot unmarshal %v of length %d in to %v", srcType, src.Len(), dstType)
+		}
+		for i := 0; i < src.Len(); i++ {
+			if err := set(dst.Index(i), src.Index(i)); err != nil {
+				return err
+			}
+		}
+	case dstType.Kind() == reflect.Struct && srcType.Kind() == reflect.Struct:
+		return setStruct(dst, src)
 	default:
 		return fmt.Errorf("abi: cannot unmarshal %v in to %v", src.Type(), dst.Type())
 	}
 	return nil
 }
 
+// setStruct assigns the fields of the unpacked tuple src to the fields of dst
+// with the same names.
+func setStruct(dst, src reflect.Value) error {
+	srcType := src.Type()
+	for i := 0; i < srcType.NumField(); i++ {
+		field := dst.FieldByName(srcType.Field(i).Name)
+		if !field.IsValid() || !field.CanSet() {
+			return fmt.Errorf("abi: field %v of tuple not found in %v", srcType.Field(i).Name, dst.Type())
+		}
+		if err := set(field, src.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // requireAssignable assures that `dest` is a pointer and it's not an interface.
 func requireAssignable(dst, src reflect.Value) error {
 	if dst.Kind() != reflect.Ptr && dst.Kind() != reflect.Interface {
@@ -112,13 +150,13 @@ func requireUnpackKind(v reflect.Value, t reflect.Type, k reflect.Kind,
 	return nil
 }
 
-// mapAbiToStringField maps abi to struct fields.
+// mapArgNamesToStructFields maps the argument names to struct fields.
 // first round: for each Exportable field that contains a `abi:""` tag
 //   and this field name exists in the arguments, pair them together.
 // second round: for each argument field that has not been already linked,
 //   find what variable is expected to be mapped into, if it exists and has not been
 //   used, pair them.
-func mapAbiToStructFields(args Arguments, value reflect.Value) (map[string]string, error) {
+func mapArgNamesToStructFields(argNames []string, value reflect.Value) (map[string]string, error) {
 
 	typ := value.Type()
 
@@ -148,14 +186,14 @@ func mapAbiToStructFields(args Arguments, value reflect.Value) (map[string]strin
 
 		// check which argument field matches with the abi tag.
 		found := false
-		for _, abiField := range args.NonIndexed() {
-			if abiField.Name == tagName {
-				if abi2struct[abiField.Name] != "" {
+		for _, argName := range argNames {
+			if argName == tagName {
+				if abi2struct[argName] != "" {
 					return nil, fmt.Errorf("struct: abi tag in '%s' already mapped", structFieldName)
 				}
 				// pair them
-				abi2struct[abiField.Name] = structFieldName
-				struct2abi[structFieldName] = abiField.Name
+				abi2struct[argName] = structFieldName
+				struct2abi[structFieldName] = argName
 				found = true
 			}
 		}
@@ -168,10 +206,13 @@ func mapAbiToStructFields(args Arguments, value reflect.Value) (map[string]strin
 	}
 
 	// second round ~~~
-	for _, arg := range args {
+	for _, abiFieldName := range argNames {
 
-		abiFieldName := arg.Name
 		structFieldName := capitalise(abiFieldName)
+		// fields of generated bindings and tuples are camel-cased
+		if camel := ToCamelCase(abiFieldName); !value.FieldByName(structFieldName).IsValid() && value.FieldByName(camel).IsValid() {
+			structFieldName = camel
+		}
 
 		if structFieldName == "" {
 			return nil, fmt.Errorf("abi: purely underscored output cannot unpack to struct")
@@ -0,0 +1,69 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/unichainplatform/unichain/common"
+)
+
+// ParseTopics decodes the topics of the indexed fields of an event into the
+// struct out, the topic of each of the fields in order. The topics of strings,
+// bytes, arrays and tuples are the hashes of their encoding, they are stored
+// as common.Hash.
+func ParseTopics(out interface{}, fields Arguments, topics []common.Hash) error {
+	value := reflect.ValueOf(out)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("abi: ParseTopics(non-struct pointer %T)", out)
+	}
+	value = value.Elem()
+	if len(fields) != len(topics) {
+		return fmt.Errorf("abi: %d indexed fields, %d topics", len(fields), len(topics))
+	}
+	abi2struct, err := mapArgNamesToStructFields(fields.names(), value)
+	if err != nil {
+		return err
+	}
+	for i, arg := range fields {
+		if !arg.Indexed {
+			return fmt.Errorf("abi: field %v is not indexed", arg.Name)
+		}
+		structField, ok := abi2struct[arg.Name]
+		if !ok {
+			continue
+		}
+		decoded, err := parseTopic(arg.Type, topics[i])
+		if err != nil {
+			return err
+		}
+		if err := set(value.FieldByName(structField), reflect.ValueOf(decoded)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTopic decodes the topic of an indexed argument of type t.
+func parseTopic(t Type, topic common.Hash) (interface{}, error) {
+	switch t.T {
+	case StringTy, BytesTy, SliceTy, ArrayTy, TupleTy:
+		return topic, nil
+	}
+	return toGoType(0, t, topic.Bytes())
+}
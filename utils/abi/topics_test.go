@@ -0,0 +1,75 @@
+// Copyright 2018 The UniChain Team Authors
+// This file is part of the unichain project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/unichainplatform/unichain/common"
+	"github.com/unichainplatform/unichain/crypto"
+)
+
+const eventABI = `[
+	{"type":"event","name":"Deposit","inputs":[{"name":"amount","type":"uint256","indexed":true},{"name":"is_new","type":"bool","indexed":true},{"name":"memo","type":"string","indexed":true},{"name":"data","type":"bytes","indexed":false}]}
+]`
+
+func TestParseTopics(t *testing.T) {
+	abi, err := JSON(strings.NewReader(eventABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := abi.Events["Deposit"]
+	if id := event.Id(); id != crypto.Keccak256Hash([]byte("Deposit(uint256,bool,string,bytes)")) {
+		t.Fatalf("event id mismatch %x", id)
+	}
+
+	var indexed Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	memo := crypto.Keccak256Hash([]byte("memo"))
+	topics := []common.Hash{
+		common.BigToHash(big.NewInt(1000)),
+		common.BigToHash(big.NewInt(1)),
+		memo,
+	}
+	var out struct {
+		Amount *big.Int
+		IsNew  bool
+		Memo   common.Hash
+	}
+	if err := ParseTopics(&out, indexed, topics); err != nil {
+		t.Fatalf("ParseTopics: %v", err)
+	}
+	if out.Amount.Cmp(big.NewInt(1000)) != 0 || !out.IsNew || out.Memo != memo {
+		t.Fatalf("topics mismatch %v %v %x", out.Amount, out.IsNew, out.Memo)
+	}
+
+	if err := ParseTopics(&out, indexed, topics[:2]); err == nil {
+		t.Fatal("topics count mismatch accepted")
+	}
+	if err := ParseTopics(out, indexed, topics); err == nil {
+		t.Fatal("non pointer accepted")
+	}
+	if err := ParseTopics(&out, event.Inputs[3:], topics[:1]); err == nil || !strings.Contains(err.Error(), "not indexed") {
+		t.Fatal("non indexed field accepted")
+	}
+}